@@ -0,0 +1,127 @@
+package arp
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+const (
+	CFG_AFTER = "after"
+
+	AfterHookPath = "test.after"
+
+	// environment variables exposing the test's response to its 'after' command
+	ENV_AFTER_RESPONSE    = "ARP_RESPONSE"
+	ENV_AFTER_STATUS_CODE = "ARP_STATUS_CODE"
+	ENV_AFTER_TEST_NAME   = "ARP_TEST_NAME"
+
+	DEFAULT_AFTER_TIMEOUT = 30 * time.Second
+
+	// largest response passed in the ARP_RESPONSE environment variable, well below the limits of common platforms
+	MaxAfterResponseEnvSize = 32 * 1024
+)
+
+// TestCaseAfterCfg defines a command that is executed once a test's response has been validated, e.g. to clean up
+// resources it created or verify its side effects out of band.
+type TestCaseAfterCfg struct {
+	BinPath     string   `yaml:"bin"`
+	Args        []string `yaml:"args"`
+	Timeout     string   `yaml:"timeout"`
+	FailOnError bool     `yaml:"failOnError"`
+}
+
+// loadAfterHook validates the test's 'after' command and parses its timeout, falling back to the default
+func (t *TestCase) loadAfterHook() error {
+	after := t.Config.After
+	if after == nil {
+		return nil
+	}
+	if t.Config.Websocket {
+		return fmt.Errorf("'%v' is not supported for websocket tests: %v", CFG_AFTER, t.Config.Name)
+	}
+	if after.BinPath == "" {
+		return fmt.Errorf("'%v' requires a '%v' to execute: %v", CFG_AFTER, TEST_EXEC_KEY_BIN_PATH, t.Config.Name)
+	}
+
+	t.AfterTimeout = DEFAULT_AFTER_TIMEOUT
+	if after.Timeout != "" {
+		var err error
+		if t.AfterTimeout, err = time.ParseDuration(after.Timeout); err != nil {
+			return fmt.Errorf("invalid '%v' timeout for test '%v': %v", CFG_AFTER, t.Config.Name, err)
+		}
+	}
+	return nil
+}
+
+// runAfterHook executes the test's 'after' command with the response as JSON on its stdin and in the environment.
+// Its exit status and output are reported with the test's results and only fail it when 'failOnError' is set, in
+// which case commands that can't be executed or time out fail it as well.
+func (t *TestCase) runAfterHook(result *TestResult) {
+	after := t.Config.After
+	if after == nil {
+		return
+	}
+
+	status, output, err := t.executeAfterHook(result)
+	passed := (err == nil && status == 0) || !after.FailOnError
+	msg := fmt.Sprintf("[status %v]", status)
+	if err != nil {
+		msg = fmt.Sprintf("[%v]", err)
+	}
+	if output != "" {
+		msg = fmt.Sprintf("%v\n %v", msg, output)
+	}
+	result.Fields = append(result.Fields, &FieldMatcherResult{
+		ObjectKeyPath:   AfterHookPath,
+		Error:           msg,
+		Status:          passed,
+		ShowExtendedMsg: !passed,
+	})
+	result.Passed = result.Passed && passed
+}
+
+// executeAfterHook runs the 'after' command, returning its exit status and combined output. Errors are only returned
+// when the command couldn't be run to completion.
+func (t *TestCase) executeAfterHook(result *TestResult) (int, string, error) {
+	after := t.Config.After
+
+	resolvedBinPath, args, err := resolveCommand(t.GlobalDataStore, after.BinPath, after.Args)
+	if err != nil {
+		return 0, "", err
+	}
+
+	response, err := json.Marshal(result.Response)
+	if err != nil {
+		return 0, "", fmt.Errorf("failed to serialize the response for '%v': %v", CFG_AFTER, err)
+	}
+
+	ctx, cancel := context.WithTimeout(t.ctx(), t.AfterTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, resolvedBinPath, args...)
+	cmd.Stdin = bytes.NewReader(response)
+	cmd.Env = append(os.Environ(),
+		fmt.Sprintf("%v=%v", ENV_AFTER_STATUS_CODE, result.StatusCode),
+		fmt.Sprintf("%v=%v", ENV_AFTER_TEST_NAME, t.Config.Name),
+	)
+	// large responses would exceed the size limit of the environment, so they are only passed on stdin
+	if len(response) <= MaxAfterResponseEnvSize {
+		cmd.Env = append(cmd.Env, fmt.Sprintf("%v=%s", ENV_AFTER_RESPONSE, response))
+	}
+
+	out, err := cmd.CombinedOutput()
+	output := strings.TrimSpace(string(out))
+	if ctx.Err() == context.DeadlineExceeded {
+		return 0, output, fmt.Errorf("'%v' command %v timed out after %v", CFG_AFTER, resolvedBinPath, t.AfterTimeout)
+	}
+	if err != nil && (cmd.ProcessState == nil || !cmd.ProcessState.Exited()) {
+		return 0, output, fmt.Errorf("failed to execute '%v' command %v: %v", CFG_AFTER, resolvedBinPath, err)
+	}
+	return cmd.ProcessState.ExitCode(), output, nil
+}
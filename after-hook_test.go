@@ -0,0 +1,68 @@
+package arp
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolveCommand(t *testing.T) {
+	ds := NewDataStore()
+	ds.Put("bin", "/bin/echo")
+	ds.Put("id", "42")
+	ds.Put("user", map[string]interface{}{"name": "charles"})
+
+	bin, args, err := resolveCommand(&ds, "@{bin}", []string{"--id=@{id}", "@{user}", "plain"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if bin != "/bin/echo" {
+		t.Errorf("unexpected bin '%v'", bin)
+	}
+	expected := []string{"--id=42", `{"name":"charles"}`, "plain"}
+	if !reflect.DeepEqual(args, expected) {
+		t.Errorf("expected args %v but got %v", expected, args)
+	}
+
+	if _, _, err := resolveCommand(&ds, "@{missing}", nil); err == nil {
+		t.Error("expected an error for an unresolved bin")
+	}
+	if _, _, err := resolveCommand(&ds, "@{bin}", []string{"@{missing}"}); err == nil {
+		t.Error("expected an error for an unresolved argument")
+	}
+}
+
+func TestAfterHookResponse(t *testing.T) {
+	tests := []struct {
+		name     string
+		response map[string]interface{}
+		inEnv    bool
+	}{
+		{"small response", map[string]interface{}{"id": 1}, true},
+		{"large response", map[string]interface{}{"data": strings.Repeat("x", MaxAfterResponseEnvSize)}, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := NewDataStore()
+			test := &TestCase{GlobalDataStore: &ds, AfterTimeout: 10 * time.Second}
+			test.Config.After = &TestCaseAfterCfg{
+				BinPath: "/bin/sh",
+				Args:    []string{"-c", `test "$(cat)" = "$ARP_RESPONSE" && echo env || echo stdin`},
+			}
+
+			status, output, err := test.executeAfterHook(&TestResult{Response: tt.response})
+			if err != nil || status != 0 {
+				t.Fatalf("unexpected status %v: %v", status, err)
+			}
+			expected := "stdin"
+			if tt.inEnv {
+				expected = "env"
+			}
+			if output != expected {
+				t.Errorf("expected the response to be passed on %v but got '%v'", expected, output)
+			}
+		})
+	}
+}
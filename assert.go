@@ -0,0 +1,133 @@
+package arp
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+const (
+	CFG_ASSERT_EQUALS = "assertEquals"
+	ASSERT_METHOD     = "ASSERT"
+
+	AssertDiffersFmt = "Values differ at '%v': %v (%v) != %v (%v)"
+	AssertMissingFmt = "Values differ at '%v': key is missing from the %v value"
+	AssertLengthFmt  = "Values differ at '%v': array length %v != %v"
+	AssertResolveFmt = "failed to resolve '%v' of '%v': %v"
+)
+
+// TestCaseAssertCfg compares two values, typically variables stored by previous tests, without making a request
+type TestCaseAssertCfg struct {
+	Left  interface{} `yaml:"left"`
+	Right interface{} `yaml:"right"`
+}
+
+// resolveAssertValue resolves any variables within an assertion value and normalizes it to its JSON representation
+// so that values from YAML and JSON responses compare equally (e.g. 1 and 1.0).
+func (t *TestCase) resolveAssertValue(name string, value interface{}) (interface{}, error) {
+	var resolved interface{}
+	var err error
+	if s, ok := value.(string); ok {
+		// expanded directly so that a missing variable is reported rather than compared as text
+		resolved, err = t.GlobalDataStore.ExpandVariable(s)
+	} else {
+		resolved, err = t.GlobalDataStore.RecursiveResolveVariables(value)
+	}
+	if err != nil {
+		return nil, fmt.Errorf(AssertResolveFmt, name, CFG_ASSERT_EQUALS, err)
+	}
+
+	normalized, err := normalizeJson(YamlToJson(resolved))
+	if err != nil {
+		return nil, fmt.Errorf(AssertResolveFmt, name, CFG_ASSERT_EQUALS, err)
+	}
+	return normalized, nil
+}
+
+// executeAssertion performs a typed deep comparison of the left and right values of an 'assertEquals' test. Values
+// that can't be resolved fail the assertion rather than the rest of the suite.
+func (t *TestCase) executeAssertion(result *TestResult) {
+	var msg string
+	var equal bool
+	var right interface{}
+	left, err := t.resolveAssertValue("left", t.Config.AssertEquals.Left)
+	if err == nil {
+		right, err = t.resolveAssertValue("right", t.Config.AssertEquals.Right)
+	}
+	if err != nil {
+		msg = err.Error()
+	} else if msg, equal = assertDiff(left, right, ""); equal {
+		msg = varToString(left)
+	}
+	result.Fields = []*FieldMatcherResult{
+		{
+			ObjectKeyPath:   fmt.Sprintf("test.%v", CFG_ASSERT_EQUALS),
+			Error:           msg,
+			Status:          equal,
+			ShowExtendedMsg: !equal && len(msg) >= 64,
+		},
+	}
+	result.Passed = equal
+}
+
+// assertDiff returns a description of the first difference found between two JSON values
+func assertDiff(left interface{}, right interface{}, path string) (string, bool) {
+	switch l := left.(type) {
+	case map[string]interface{}:
+		r, ok := right.(map[string]interface{})
+		if !ok {
+			break
+		}
+
+		keys := map[string]bool{}
+		for k := range l {
+			keys[k] = true
+		}
+		for k := range r {
+			keys[k] = true
+		}
+		var sorted []string
+		for k := range keys {
+			sorted = append(sorted, k)
+		}
+		sort.Strings(sorted)
+
+		for _, k := range sorted {
+			lv, lOk := l[k]
+			rv, rOk := r[k]
+			if !lOk {
+				return fmt.Sprintf(AssertMissingFmt, path+"."+k, "left"), false
+			} else if !rOk {
+				return fmt.Sprintf(AssertMissingFmt, path+"."+k, "right"), false
+			}
+			if msg, equal := assertDiff(lv, rv, path+"."+k); !equal {
+				return msg, false
+			}
+		}
+		return "", true
+	case []interface{}:
+		r, ok := right.([]interface{})
+		if !ok {
+			break
+		}
+		if len(l) != len(r) {
+			return fmt.Sprintf(AssertLengthFmt, path, len(l), len(r)), false
+		}
+		for i := range l {
+			if msg, equal := assertDiff(l[i], r[i], fmt.Sprintf("%v[%v]", path, i)); !equal {
+				return msg, false
+			}
+		}
+		return "", true
+	}
+
+	if reflect.DeepEqual(left, right) {
+		return "", true
+	}
+	if path == "" {
+		path = "."
+	}
+	// include the types so that values with the same string representation (e.g. 1 and "1") can be told apart
+	return fmt.Sprintf(AssertDiffersFmt, path, ToJsonStr(left), jsonTypeName(left, ""), ToJsonStr(right),
+		jsonTypeName(right, "")), false
+}
@@ -0,0 +1,74 @@
+package arp
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExecuteAssertion(t *testing.T) {
+	ds := NewDataStore()
+	ds.Put("profile", map[string]interface{}{"id": 1, "name": "test"})
+	ds.Put("other", map[string]interface{}{"id": "1", "name": "test"})
+
+	tests := []struct {
+		name   string
+		left   interface{}
+		right  interface{}
+		passed bool
+		error  string
+	}{
+		{"equal", "@{profile}", map[interface{}]interface{}{"id": 1, "name": "test"}, true, ""},
+		{"different types", "@{profile}", "@{other}", false, "Values differ at '.id'"},
+		{"missing left variable", "@{missing}", "@{profile}", false, "failed to resolve 'left'"},
+		{"missing right variable", "@{profile}", "@{missing}", false, "failed to resolve 'right'"},
+	}
+
+	for _, tt := range tests {
+		test := TestCase{GlobalDataStore: &ds, Config: TestCaseCfg{AssertEquals: &TestCaseAssertCfg{Left: tt.left, Right: tt.right}}}
+		result := &TestResult{}
+		test.executeAssertion(result)
+
+		if result.Passed != tt.passed || len(result.Fields) != 1 {
+			t.Errorf("%v: expected the assertion to pass: %v but got %v", tt.name, tt.passed, ToJsonStr(result.Fields))
+			continue
+		}
+		if !strings.Contains(result.Fields[0].Error, tt.error) {
+			t.Errorf("%v: expected '%v' but got: %v", tt.name, tt.error, result.Fields[0].Error)
+		}
+	}
+}
+
+func TestAssertionDoesNotStopSuite(t *testing.T) {
+	result := runTestFile(t, `
+tests:
+  - name: Missing variable
+    assertEquals:
+      left: "@{missing}"
+      right: 1
+  - name: Equal
+    assertEquals:
+      left: 1
+      right: 1
+`, "", SuiteOptions{})
+
+	if len(result.Results) != 2 {
+		t.Fatalf("expected 2 results but got %v", len(result.Results))
+	}
+	if result.Results[0].Passed || !result.Results[1].Passed {
+		t.Errorf("expected only the assertion with the missing variable to fail but got:\n%v\n%v",
+			failedFields(result.Results[0]), failedFields(result.Results[1]))
+	}
+}
+
+func TestAssertionWithPayload(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tests.yaml")
+	def := "tests:\n  - name: Assertion\n    assertEquals:\n      left: 1\n      right: 1\n    response:\n      payload:\n        id: 1\n"
+	if err := os.WriteFile(file, []byte(def), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTestSuite(file, "", SuiteOptions{}); err == nil {
+		t.Errorf("expected payload validations to be rejected for assertEquals tests")
+	}
+}
@@ -0,0 +1,94 @@
+package arp
+
+import (
+	"fmt"
+	"net/http"
+	"regexp"
+	"sort"
+	"strconv"
+)
+
+const (
+	CFG_RESPONSE_CACHE = "cache"
+
+	// Keys of the values derived from the cache headers of a response
+	CACHE_FROM_CACHE      = "fromCache"
+	CACHE_X_CACHE         = "xCache"
+	CACHE_AGE             = "age"
+	CACHE_CF_CACHE_STATUS = "cfCacheStatus"
+
+	HEADER_X_CACHE         = "X-Cache"
+	HEADER_AGE             = "Age"
+	HEADER_CF_CACHE_STATUS = "CF-Cache-Status"
+
+	CachePath = "response.Cache"
+)
+
+var (
+	// Header patterns used to detect a cached response when a suite doesn't define its own 'cacheRules'
+	DefaultCacheRules = map[string]string{
+		HEADER_X_CACHE:         `(?i)\bhit\b`,
+		HEADER_CF_CACHE_STATUS: `(?i)^hit$`,
+		HEADER_AGE:             `^[1-9][0-9]*$`,
+	}
+)
+
+// CacheRule marks a response as served from a cache when any value of its header matches the pattern
+type CacheRule struct {
+	Header  string
+	Pattern *regexp.Regexp
+}
+
+// ParseCacheRules compiles a mapping of header names to the patterns their values match when a response is served
+// from a cache. The default rules are used when no rules are given.
+func ParseCacheRules(rules map[string]string) ([]CacheRule, error) {
+	if len(rules) == 0 {
+		rules = DefaultCacheRules
+	}
+
+	var parsed []CacheRule
+	for header, pattern := range rules {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid cache rule pattern for header '%v': %v", header, err)
+		}
+		parsed = append(parsed, CacheRule{Header: header, Pattern: re})
+	}
+	sort.Slice(parsed, func(i, j int) bool { return parsed[i].Header < parsed[j].Header })
+	return parsed, nil
+}
+
+// IsFromCache returns whether the headers of a response match any of the cache rules
+func IsFromCache(rules []CacheRule, headers http.Header) bool {
+	for _, rule := range rules {
+		for _, v := range headers.Values(rule.Header) {
+			if rule.Pattern.MatchString(v) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// getCacheJson derives whether a response was served from a cache, along with the values of the common cache
+// headers so that they can be validated without matching against the array of values of each header. Headers
+// missing from the response are omitted so that they can be validated with 'exists: false'.
+func getCacheJson(rules []CacheRule, headers http.Header) map[string]interface{} {
+	cache := map[string]interface{}{
+		CACHE_FROM_CACHE: IsFromCache(rules, headers),
+	}
+	if v := headers.Get(HEADER_X_CACHE); v != "" {
+		cache[CACHE_X_CACHE] = v
+	}
+	if v := headers.Get(HEADER_CF_CACHE_STATUS); v != "" {
+		cache[CACHE_CF_CACHE_STATUS] = v
+	}
+	if v := headers.Get(HEADER_AGE); v != "" {
+		if age, err := strconv.Atoi(v); err == nil {
+			cache[CACHE_AGE] = age
+		} else {
+			cache[CACHE_AGE] = v
+		}
+	}
+	return cache
+}
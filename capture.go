@@ -0,0 +1,87 @@
+package arp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+)
+
+const (
+	CFG_RESPONSE_CAPTURE = "capture"
+
+	CapturePath          = "response.Capture"
+	CaptureMissingFmt    = "Warning: '%v' was not found in the response, stored null instead"
+	CaptureNoResponseFmt = "Warning: no response to capture '%v' from, stored null instead"
+)
+
+// captureValues stores the values at the JSON paths of 'response.capture' under their names, whether or not the
+// response passed its validations. Paths that can't be found store null and are reported with a warning.
+func (t *TestCase) captureValues(result *TestResult) {
+	capture := t.Config.Response.Capture
+	if len(capture) == 0 {
+		return
+	}
+
+	var names []string
+	for name := range capture {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		path := strings.TrimPrefix(capture[name], FIELD_KEY_PREFIX)
+
+		var value interface{}
+		msg := ""
+		if result.Response == nil {
+			msg = fmt.Sprintf(CaptureNoResponseFmt, capture[name])
+		} else if v, err := GetJsonValue(result.Response, path); err != nil {
+			msg = fmt.Sprintf(CaptureMissingFmt, capture[name])
+		} else {
+			value = v
+		}
+		t.GlobalDataStore.Capture(name, value)
+
+		if msg != "" {
+			result.Fields = append(result.Fields, &FieldMatcherResult{
+				ObjectKeyPath: fmt.Sprintf("%v.%v", CapturePath, name),
+				Error:         msg,
+				Status:        true,
+			})
+		}
+	}
+}
+
+// ExportCaptures writes the values stored from test responses during the run as JSON, grouped by test file and keyed
+// by variable name along with the test that stored them. Test files executed against multiple hosts or with a
+// dataset are prefixed with the host and row. Values loaded from fixtures or the environment are left out, and the
+// values of sensitive keys are redacted as in test reports.
+func ExportCaptures(path string, results []MultiSuiteResult, redactor Redactor) error {
+	files := make(map[string]map[string]CapturedValue)
+	for _, suite := range results {
+		if len(suite.TestResults.Captures) == 0 {
+			continue
+		}
+		captures := make(map[string]CapturedValue)
+		for key, c := range suite.TestResults.Captures {
+			if redactor.IsSensitive(key) {
+				c.Value = REDACTED_VALUE
+			} else {
+				c.Value = redactor.Redact(c.Value)
+			}
+			captures[key] = c
+		}
+		files[suite.Label()] = captures
+	}
+
+	data, err := json.MarshalIndent(files, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to serialize captured values: %v", err)
+	}
+	if err := os.WriteFile(path, append(data, '\n'), 0644); err != nil {
+		return fmt.Errorf("failed to write captured values: %v - %v", path, err)
+	}
+	return nil
+}
@@ -2,10 +2,12 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"encoding/json"
 	"flag"
 	"fmt"
 	"os"
+	"sort"
 	"strings"
 	"time"
 
@@ -35,45 +37,151 @@ func (t *testTags) Set(value string) error {
 }
 
 type ProgramArgs struct {
-	Fixtures     *string
-	TestRoot     *string
-	TestFile     *string
-	Threads      *int
-	Short        *bool
-	Tiny         *bool
-	Micro        *bool
-	ShortErrors  *bool
-	ErrorsOnly   *bool
-	PrintHeaders *bool
-	Colorize     *bool
-	Interactive  *bool
-	Variables    varFlags
-	Tags         testTags
+	Fixtures      *string
+	CaptureOut    *string
+	TestRoot      *string
+	TestFile      *string
+	Glob          *string
+	Hosts         *string
+	HostList      []string
+	DatasetFile   *string
+	Dataset       []DatasetRow
+	Threads       *int
+	Short         *bool
+	Tiny          *bool
+	Micro         *bool
+	ShortErrors   *bool
+	ErrorsOnly    *bool
+	PrintHeaders  *bool
+	Colorize      *bool
+	Interactive   *bool
+	NoEnv         *bool
+	EnvPrefix     *string
+	Redact        *string
+	RequireTests  *bool
+	Lint          *bool
+	Strict        *bool
+	TestName      *string
+	Timings       *bool
+	FixturesEnv   *string
+	Timeout       *time.Duration
+	Record        *string
+	Verify        *string
+	IgnoreFields  *string
+	Explain       *bool
+	RequestId     *string
+	HttpOut       *string
+	CompactJSON   *bool
+	IndentSize    *int
+	TimingSummary *bool
+	TimingGroup   *string
+	Changed       *string
+	SLA           *string
+	SLAFile       *string
+	SLAs          map[string]time.Duration
+	Environment   *string
+	Repeat        *int
+	WaitFor       *string
+	WaitInterval  *time.Duration
+	WaitTimeout   *time.Duration
+	GlobalFile    *string
+	AssumeJson    *bool
+	Trace         *string
+	ShowSecrets   *bool
+	Globals       *GlobalAssertionsCfg
+	RemoteTimeout *time.Duration
+	Remote        RemoteOptions
+	RemoteHeaders varFlags
+	RemoteHttp    *bool
+	Variables     varFlags
+	Tags          testTags
 }
 
 func (p *ProgramArgs) Init() {
 	// somewhat alphabetical order...
 	p.PrintHeaders = flag.Bool("always-headers", false, "Always print the request and response headers in long test report output whether any matchers are defined for them or not.")
+	p.AssumeJson = flag.Bool("assume-json", false, "Parse responses as JSON even when they don't have a JSON or text content type, such as when "+
+		"the server omits the header. Bodies that aren't JSON fall back to binary. Tests can override this with 'assumeJson'.")
+	p.CaptureOut = flag.String("capture-out", "", "Path to write the values stored from test responses with 'storeAs' or 'capture' to as JSON, "+
+		"grouped by test file along with the name of the test that stored each value. Sensitive values are redacted as in test reports.")
+	p.Changed = flag.String("changed", "", "Only execute the test files found with '-test-root' or '-glob' that were modified since this git ref "+
+		"(e.g. origin/main). All test files are executed if git is unavailable.")
 	p.Colorize = flag.Bool("colors", true, "Print test report with colors.")
+	p.CompactJSON = flag.Bool("compact-json", false, "Print headers, inputs, and responses as single line JSON in test reports to keep logs short.")
+	p.IgnoreFields = flag.String("contract-ignore", "", "Comma separated list of field names or dot separated paths (e.g. createdAt,data.id) "+
+		"to exclude when recording and verifying contracts. Supports wildcards.")
+	p.DatasetFile = flag.String("dataset", "", "Path to a yaml or JSON file containing a list of variable maps (e.g. the credentials of "+
+		"different user roles). All tests are executed once per row with the row's variables added to the data store, and results are "+
+		"reported per row along with a combined summary.")
 	p.ErrorsOnly = flag.Bool("error-report", false, "Generate a test report that only contain failing test results.")
-	p.TestFile = flag.String("file", "", "Path to an individual test file to execute.")
+	p.Environment = flag.String("env", "", "Name of the environment tests are executed against (e.g. staging). Tests with an 'environments' list "+
+		"that doesn't include it are skipped.")
+	p.EnvPrefix = flag.String("env-prefix", "", "Only populate the tests data store with environment variables starting with this prefix (e.g. ARP_).")
+	p.Explain = flag.Bool("explain", false, "Print the route, headers, and input of each test with all variables and inline commands resolved "+
+		"instead of executing them. Values stored by previous tests are not available.")
+	p.TestFile = flag.String("file", "", "Path or http(s) URL of an individual test file to execute.")
 	p.Fixtures = flag.String("fixtures", "", "Path to yaml file with data to include into the test scope via test variables. "+
-		"This file is also merged with each test file such that any YAML anchors defined within it are available for reference in the test files.")
+		"This file is also merged with each test file such that any YAML anchors defined within it are available for reference in the test files. "+
+		"Use '-' to read the fixtures from stdin.")
+	p.FixturesEnv = flag.String("fixtures-env", "", "Name of an environment variable containing the fixtures yaml. Takes precedence over '-fixtures'.")
+	p.Glob = flag.String("glob", "", "Comma separated list of test files, test file URLs, or glob patterns (e.g. tests/smoke/*.yaml) to execute. "+
+		"The matched files are printed before execution.")
+	p.GlobalFile = flag.String("global-assertions", "", "Path to a yaml file of 'globalAssertions' (headers, payload, maxDuration) that every "+
+		"test response must pass in addition to its own validations. Merged with the 'globalAssertions' of each test file.")
+	p.Hosts = flag.String("hosts", "", "Comma separated list of hosts (e.g. https://us.example.com,https://eu.example.com) to execute "+
+		"all tests against one after another, setting the 'host' variable to each. Results are reported per host along with a combined summary.")
+	p.HttpOut = flag.String("http-out", "", "Directory to write each executed request into as a '.http' file (one per test file) that "+
+		"can be replayed with editors such as VS Code's REST Client. Sensitive values are redacted as in test reports.")
+	p.NoEnv = flag.Bool("no-env", false, "Do not populate the tests data store with environment variables.")
+	p.IndentSize = flag.Int("indent", 1, "Number of spaces to indent nested JSON values by in test reports.")
+	p.Lint = flag.Bool("lint", false, "Print warnings for problems in test definitions, such as unknown matcher keys that would otherwise be silently ignored.")
+	p.Timings = flag.Bool("matcher-timings", false, "Print how long each matcher took to execute in the test report.")
 	p.Micro = flag.Bool("micro", false, "Print out the smallest test report possible for a multi-test suite run.")
+	p.Record = flag.String("record", "", "Path to a contract file to record the structure of each test's response into.")
+	p.Redact = flag.String("redact", strings.Join(DefaultRedactPatterns, ","), "Comma separated list of case-insensitive key patterns (e.g. *token*) "+
+		"whose values are masked in test reports and data store dumps. Set to an empty string to disable redaction.")
+	p.RequestId = flag.String("request-id", "", "Name of a header (e.g. X-Request-Id) to send a unique request ID in with every HTTP request "+
+		"that doesn't already set it. The ID is printed in the test report to help find requests in server logs.")
+	p.Repeat = flag.Int("repeat", 1, "Number of times to execute each test to detect intermittent failures. Tests only pass when every "+
+		"attempt passes. Tests with their own 'repeat' value are executed that many times instead.")
+	p.RequireTests = flag.Bool("require-tests", false, "Fail when a test file does not contain any tests. Useful for catching files with structural mistakes.")
 	p.Short = flag.Bool("short", true, "Print a short report for executed tests containing only the validation results.")
 	p.ShortErrors = flag.Bool("short-fail", false, "Keep the report short when errors are encountered rather than expanding with details.")
+	p.ShowSecrets = flag.Bool("show-secrets", false, "Print the values of sensitive headers, including cookies, in '-trace' output rather than redacting them.")
+	p.SLA = flag.String("sla", "", "Comma separated list of tag=duration pairs (e.g. smoke=500ms,search=2s). Tests with a tag fail when "+
+		"they take longer than its duration. Takes precedence over '-sla-file'.")
+	p.SLAFile = flag.String("sla-file", "", "Path to a yaml file mapping tags to the maximum duration allowed for tests with that tag.")
+	flag.Var(&p.RemoteHeaders, "remote-header", "Header to send when fetching test files from http(s) URLs, as 'Name: value' (e.g. "+
+		"'Authorization: Bearer $TOKEN'). Multiple -remote-header parameters can be provided for additional headers.")
+	p.RemoteHttp = flag.Bool("remote-insecure", false, "Allow test files to be fetched from plain http URLs. Remote test files can run commands "+
+		"locally, so only use this on trusted networks.")
+	p.RemoteTimeout = flag.Duration("remote-timeout", DEFAULT_REMOTE_TIMEOUT, "Maximum duration to wait for each test file fetched from an http(s) URL.")
 	p.Interactive = flag.Bool("step", false, "Run tests in interactive mode. Requires a test file to be provided with '-file'")
 
 	flag.Var(&p.Tags, "tag", "Only execute tests with tags matching this value. Tag input supports comma separated values which will execute "+
 		"tests that contain any on of those values. Subsequent tag parameters will AND with previous tag inputs "+
 		"to determine what tests will be run. Specifying no tag parameters will execute all tests.")
 
-	p.TestRoot = flag.String("test-root", "", "Folder path containing all the test files to execute.")
+	p.Strict = flag.Bool("strict", false, "Fail the run if any test definition contains problems reported by '-lint'.")
+	p.TestName = flag.String("test", "", "Name of a single test to execute within the test file provided with '-file'.")
+	p.TestRoot = flag.String("test-root", "", "Folder path containing all the test files to execute, or the http(s) URL of a single test file.")
 	p.Threads = flag.Int("threads", 16, "Max number of test files to execute concurrently.")
+	p.Timeout = flag.Duration("timeout", 0, "Maximum duration of the entire test run (e.g. 5m). Pending requests are cancelled and "+
+		"remaining tests fail once it expires. Defaults to no timeout.")
+	p.TimingGroup = flag.String("timing-group", "", "Additionally group the '-timing-summary' durations by 'method' or 'route'.")
+	p.TimingSummary = flag.Bool("timing-summary", false, "Print the min, median, p90, p99 and max request durations across all executed tests "+
+		"at the end of the test report.")
+	p.Trace = flag.String("trace", "", "Name of a test to print the raw HTTP requests and responses of to stderr, including the headers "+
+		"added when sending them and any redirects followed. Sensitive headers and cookies are redacted unless '-show-secrets' is set.")
 	p.Tiny = flag.Bool("tiny", false, "Print an even tinier report output than what the short flag provides. "+
 		"Only prints test status, name, and description. Failed tests will still be expanded.")
 
 	flag.Var(&p.Variables, "var", "Prepopulate the tests data store with a single KEY=VALUE pair. Multiple -var parameters can be provided for additional key/value pairs.")
+	p.Verify = flag.String("verify", "", "Path to a contract file recorded with '-record'. Tests fail when the structure of their response differs from it.")
+	p.WaitFor = flag.String("wait-for", "", "URL to poll before executing any tests until it responds with a 2xx status, such as the health check "+
+		"of the service under test. The run fails if it isn't ready within '-wait-timeout'.")
+	p.WaitInterval = flag.Duration("wait-interval", DEFAULT_WAIT_INTERVAL, "How often to poll the '-wait-for' URL.")
+	p.WaitTimeout = flag.Duration("wait-timeout", DEFAULT_WAIT_TIMEOUT, "Maximum duration to wait for the '-wait-for' URL to be ready.")
 
 	if len(os.Args) <= 1 {
 		flag.Usage()
@@ -81,13 +189,153 @@ func (p *ProgramArgs) Init() {
 	}
 	flag.Parse()
 
+	if *p.TestName != "" && *p.TestFile == "" {
+		fmt.Printf("A test file must be provided with '-file' when using '-test'\n")
+		os.Exit(1)
+	}
+
+	if *p.Record != "" && *p.Verify != "" {
+		fmt.Printf("Only one of '-record' or '-verify' can be used at a time\n")
+		os.Exit(1)
+	}
+
+	p.HostList = ParseHosts(*p.Hosts)
+	if (len(p.HostList) > 0 || *p.DatasetFile != "") && (*p.Interactive || *p.Explain) {
+		fmt.Printf("'-hosts' and '-dataset' can't be used with '-step' or '-explain'\n")
+		os.Exit(1)
+	}
+
+	if *p.DatasetFile != "" {
+		dataset, err := LoadDataset(*p.DatasetFile)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		p.Dataset = dataset
+	}
+
+	if g := *p.TimingGroup; g != "" && g != TIMING_GROUP_METHOD && g != TIMING_GROUP_ROUTE {
+		fmt.Printf("'-timing-group' must be either '%v' or '%v'\n", TIMING_GROUP_METHOD, TIMING_GROUP_ROUTE)
+		os.Exit(1)
+	}
+
+	p.SLAs = make(map[string]time.Duration)
+	if *p.SLAFile != "" {
+		slas, err := LoadSLAFile(*p.SLAFile)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		for tag, sla := range slas {
+			p.SLAs[tag] = sla
+		}
+	}
+	if *p.SLA != "" {
+		slas, err := ParseSLAs(*p.SLA)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		for tag, sla := range slas {
+			p.SLAs[tag] = sla
+		}
+	}
+
+	if *p.GlobalFile != "" {
+		globals, err := LoadGlobalAssertionsFile(*p.GlobalFile)
+		if err != nil {
+			fmt.Printf("%v\n", err)
+			os.Exit(1)
+		}
+		p.Globals = globals
+	}
+
+	headers, err := ParseRemoteHeaders(p.RemoteHeaders)
+	if err != nil {
+		fmt.Printf("%v\n", err)
+		os.Exit(1)
+	}
+	p.Remote = RemoteOptions{Headers: headers, Timeout: *p.RemoteTimeout, AllowInsecure: *p.RemoteHttp}
+
 	if *p.Threads < 0 {
 		def := 1
 		p.Threads = &def
 	}
 }
 
-func populateDataStore(ds *DataStore, vars varFlags) error {
+func (p *ProgramArgs) SuiteOptions() SuiteOptions {
+	return SuiteOptions{
+		NoEnv:            *p.NoEnv,
+		EnvPrefix:        *p.EnvPrefix,
+		RequireTests:     *p.RequireTests,
+		Lint:             *p.Lint,
+		Strict:           *p.Strict,
+		TestName:         *p.TestName,
+		FixturesEnv:      *p.FixturesEnv,
+		RequestIdHeader:  *p.RequestId,
+		SLAs:             p.SLAs,
+		Environment:      *p.Environment,
+		Repeat:           *p.Repeat,
+		GlobalAssertions: p.Globals,
+		AssumeJson:       *p.AssumeJson,
+		Remote:           p.Remote,
+		Trace:            *p.Trace,
+		TraceRedactor:    NewTraceRedactor(*p.Redact, *p.ShowSecrets),
+	}
+}
+
+// loadMultiSuite loads the test files found in '-test-root' or matched by '-glob', keeping only those modified
+// since the '-changed' git ref when provided.
+func loadMultiSuite(args ProgramArgs) (*MultiTestSuite, error) {
+	var suite *MultiTestSuite
+	var err error
+	if *args.Glob == "" {
+		suite, err = NewMultiSuiteTest(*args.TestRoot, *args.Fixtures, args.SuiteOptions())
+	} else {
+		var files []string
+		if files, err = ExpandGlobs(*args.Glob); err != nil {
+			return nil, err
+		}
+		fmt.Printf("Matched %v test files:\n", len(files))
+		for _, file := range files {
+			fmt.Printf("  %v\n", file)
+		}
+		fmt.Println()
+		suite, err = NewMultiSuiteTestFromFiles(files, *args.Fixtures, args.SuiteOptions())
+	}
+	if err != nil || *args.Changed == "" {
+		return suite, err
+	}
+
+	dir := *args.TestRoot
+	if dir == "" {
+		dir = "."
+	}
+	changed, cErr := ChangedFiles(dir, *args.Changed)
+	if cErr != nil {
+		fmt.Printf("Warning: executing all test files since changed files could not be determined: %v\n\n", cErr)
+		return suite, nil
+	}
+	total := len(suite.Suites)
+	suite.FilterFiles(changed)
+	fmt.Printf("Executing %v of %v test files changed since %v\n\n", len(suite.Suites), total, *args.Changed)
+	return suite, nil
+}
+
+// testRun describes one execution of the tests when they are executed against multiple hosts or once per row of a
+// dataset. The zero value executes them once as configured.
+type testRun struct {
+	Host string
+	// position of the dataset row, starting at 1
+	Row    int
+	Values DatasetRow
+}
+
+func (r testRun) String() string {
+	return RunLabel(r.Host, r.Row)
+}
+
+func populateDataStore(ds *DataStore, vars varFlags, run testRun) error {
 	ds.Put("host", "http://localhost")
 	for _, v := range vars {
 		pair := strings.SplitN(v, "=", 2)
@@ -100,48 +348,155 @@ func populateDataStore(ds *DataStore, vars varFlags) error {
 			return err
 		}
 	}
+	for k, v := range run.Values {
+		ds.Put(k, v)
+	}
+	// hosts from '-hosts' take precedence over a 'host' variable
+	if run.Host != "" {
+		ds.Put("host", run.Host)
+	}
 	return nil
 }
 
-func runTests(args ProgramArgs) bool {
-	var passed bool
-	var err error
-	var results []MultiSuiteResult
-	var testingDuration time.Duration
+func explainTests(args ProgramArgs) bool {
+	var suites []*TestSuite
+	if *args.TestFile != "" {
+		suite, err := NewTestSuite(*args.TestFile, *args.Fixtures, args.SuiteOptions())
+		if err != nil {
+			fmt.Printf("Failed to initialize test file: %v\n", err)
+			return false
+		}
+		suites = append(suites, suite)
+	} else if *args.TestRoot != "" || *args.Glob != "" {
+		multiTestSuite, err := loadMultiSuite(args)
+		if err != nil {
+			fmt.Printf("Failed to initialize test files: %v\n", err)
+			return false
+		}
+		for _, suite := range multiTestSuite.Suites {
+			suites = append(suites, suite)
+		}
+		sort.Slice(suites, func(i, j int) bool { return suites[i].File < suites[j].File })
+	}
+
+	opts := ReportOptions{
+		Colors: Colorizer{
+			Enabled: *args.Colorize,
+		},
+		Redactor: NewRedactor(*args.Redact),
+	}
+
+	for _, suite := range suites {
+		if err := populateDataStore(&suite.GlobalDataStore, args.Variables, testRun{}); err != nil {
+			fmt.Printf("Failed to populate data store: %v\n", err)
+			return false
+		}
 
+		fmt.Printf("%v\n%v\n\n", opts.Colors.BrightWhite(suite.File), opts.Colors.BrightWhite(strings.Repeat("-", 80)))
+		for _, test := range suite.Tests {
+			if test.Config.Skip || test.SkipTestOnTags(args.Tags) || test.SkipTestOnEnvironment() {
+				continue
+			}
+			PrintTestExplanation(opts, test)
+		}
+	}
+	return true
+}
+
+// executeTests executes the test file or tree once, seeding the data store of every suite with the run's host and
+// dataset row. The results are labeled with the run.
+func executeTests(ctx context.Context, args ProgramArgs, run testRun) (bool, []MultiSuiteResult, time.Duration, error) {
 	if *args.TestFile != "" {
-		suite, sErr := NewTestSuite(*args.TestFile, *args.Fixtures)
-		if sErr != nil {
-			err = sErr
-			goto DIE
+		suite, err := NewTestSuite(*args.TestFile, *args.Fixtures, args.SuiteOptions())
+		if err != nil {
+			return false, nil, 0, err
 		}
 
 		suite.Verbose = true
-		if dsErr := populateDataStore(&suite.GlobalDataStore, args.Variables); dsErr != nil {
-			err = dsErr
-			goto DIE
+		suite.Context = ctx
+		if err := populateDataStore(&suite.GlobalDataStore, args.Variables, run); err != nil {
+			return false, nil, 0, err
 		}
 
 		r := MultiSuiteResult{
 			TestFile: *args.TestFile,
+			Host:     run.Host,
+			Row:      run.Row,
 		}
 		r.Passed, r.TestResults, r.Error = suite.ExecuteTests(args.Tags)
-		results = append(results, r)
-		passed = r.Passed
-		testingDuration = r.TestResults.Duration
-	} else if *args.TestRoot != "" {
-		var multiTestSuite *MultiTestSuite
-		multiTestSuite, err = NewMultiSuiteTest(*args.TestRoot, *args.Fixtures)
+		return r.Passed, []MultiSuiteResult{r}, r.TestResults.Duration, nil
+	} else if *args.TestRoot != "" || *args.Glob != "" {
+		multiTestSuite, err := loadMultiSuite(args)
 		if err != nil {
-			goto DIE
+			return false, nil, 0, err
 		}
+		multiTestSuite.Context = ctx
 
 		for _, suite := range multiTestSuite.Suites {
-			if err = populateDataStore(&suite.GlobalDataStore, args.Variables); err != nil {
-				goto DIE
+			if err := populateDataStore(&suite.GlobalDataStore, args.Variables, run); err != nil {
+				return false, nil, 0, err
+			}
+		}
+		passed, results, duration, err := multiTestSuite.ExecuteTests(*args.Threads, args.Tags)
+		for i := range results {
+			results[i].Host = run.Host
+			results[i].Row = run.Row
+		}
+		return passed, results, duration, err
+	}
+	return false, nil, 0, nil
+}
+
+func runTests(args ProgramArgs) bool {
+	var passed bool
+	var err error
+	var results []MultiSuiteResult
+	var testingDuration time.Duration
+
+	// without '-hosts' or '-dataset' the tests are executed once as configured
+	hosts := args.HostList
+	if len(hosts) == 0 {
+		hosts = []string{""}
+	}
+	var runs []testRun
+	for _, host := range hosts {
+		if len(args.Dataset) == 0 {
+			runs = append(runs, testRun{Host: host})
+		}
+		for i, row := range args.Dataset {
+			runs = append(runs, testRun{Host: host, Row: i + 1, Values: row})
+		}
+	}
+
+	ctx := context.Background()
+	if *args.Timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, *args.Timeout)
+		defer cancel()
+	}
+
+	if *args.WaitFor != "" {
+		elapsed, attempts, wErr := WaitFor(ctx, *args.WaitFor, *args.WaitInterval, *args.WaitTimeout)
+		if wErr != nil {
+			err = wErr
+			goto DIE
+		}
+		fmt.Printf(WaitReadyFmt+"\n\n", *args.WaitFor, elapsed, attempts)
+	}
+
+	passed = true
+	for _, run := range runs {
+		runPassed, runResults, runDuration, rErr := executeTests(ctx, args, run)
+		if rErr != nil {
+			err = rErr
+			if label := run.String(); label != "" {
+				err = fmt.Errorf("%v: %v", label, rErr)
 			}
+			goto DIE
 		}
-		passed, results, testingDuration, err = multiTestSuite.ExecuteTests(*args.Threads, args.Tags)
+		passed = passed && runPassed
+		results = append(results, runResults...)
+		testingDuration += runDuration
 	}
 
 DIE:
@@ -155,7 +510,41 @@ DIE:
 		os.Exit(1)
 	}
 
+	if contractPath := *args.Record + *args.Verify; contractPath != "" {
+		contracts, cErr := LoadContracts(contractPath, *args.IgnoreFields)
+		if cErr != nil {
+			fmt.Printf("Failed to load contracts: %v\n", cErr)
+			os.Exit(1)
+		}
+
+		record := *args.Record != ""
+		passed = contracts.Apply(results, *args.TestRoot, record) && passed
+		if record {
+			if cErr := contracts.Save(); cErr != nil {
+				fmt.Printf("Failed to save contracts: %v\n", cErr)
+				os.Exit(1)
+			}
+		}
+	}
+
+	if *args.HttpOut != "" {
+		if hErr := ExportHttpFiles(*args.HttpOut, results, NewRedactor(*args.Redact)); hErr != nil {
+			fmt.Printf("Failed to export http files: %v\n", hErr)
+			os.Exit(1)
+		}
+	}
+
+	if *args.CaptureOut != "" {
+		if cErr := ExportCaptures(*args.CaptureOut, results, NewRedactor(*args.Redact)); cErr != nil {
+			fmt.Printf("Failed to export captured values: %v\n", cErr)
+			os.Exit(1)
+		}
+	}
+
 	path := *args.TestRoot
+	if path == "" {
+		path = *args.Glob
+	}
 	if path == "" {
 		path = *args.TestFile
 	}
@@ -171,9 +560,21 @@ DIE:
 		Colors: Colorizer{
 			Enabled: *args.Colorize,
 		},
+		Redactor:       NewRedactor(*args.Redact),
+		MatcherTimings: *args.Timings,
+		CompactJSON:    *args.CompactJSON,
+		IndentSize:     *args.IndentSize,
+		TimingSummary:  *args.TimingSummary,
+		TimingGroup:    *args.TimingGroup,
+		Hosts:          args.HostList,
+		Dataset:        args.Dataset,
 	}
 
 	PrintReport(opts, passed, testingDuration, results)
+	if ctx.Err() == context.DeadlineExceeded {
+		fmt.Printf("Test run timed out after %v\n", *args.Timeout)
+		return false
+	}
 	return passed
 }
 
@@ -216,7 +617,7 @@ func interactivePrompt(showOpts bool, canRetry bool, websocketMode bool) {
 	fmt.Printf("\nCommand: ")
 }
 
-func interactiveInput(tests []*TestCase, curTest int, subTest bool, result *TestResult) StepInput {
+func interactiveInput(tests []*TestCase, curTest int, subTest bool, result *TestResult, redactor Redactor) StepInput {
 	nextTestNo := curTest + 1
 	canRetry := true && !subTest
 	websocketPrompt := tests[curTest].Config.Websocket && subTest
@@ -253,7 +654,7 @@ func interactiveInput(tests []*TestCase, curTest int, subTest bool, result *Test
 				return StepInput{Retry: true}
 			}
 		case "d":
-			pretty, _ := json.MarshalIndent(tests[curTest].GlobalDataStore, "", IndentStr(1))
+			pretty, _ := json.MarshalIndent(redactor.Redact(tests[curTest].GlobalDataStore), "", IndentStr(1))
 			fmt.Printf("%v\n", string(pretty))
 		case "x":
 			return StepInput{FallThrough: true, StepThroughToError: true}
@@ -261,7 +662,7 @@ func interactiveInput(tests []*TestCase, curTest int, subTest bool, result *Test
 			return StepInput{HotReload: true}
 		case "y":
 			if canRetry {
-				pretty, _ := json.MarshalIndent(result.Response, "", IndentStr(1))
+				pretty, _ := json.MarshalIndent(redactor.Redact(result.Response), "", IndentStr(1))
 				fmt.Printf("%v\n", string(pretty))
 			}
 		default:
@@ -299,21 +700,25 @@ func interactiveMode(args ProgramArgs) bool {
 		Colors: Colorizer{
 			Enabled: *args.Colorize,
 		},
+		Redactor:       NewRedactor(*args.Redact),
+		MatcherTimings: *args.Timings,
+		CompactJSON:    *args.CompactJSON,
+		IndentSize:     *args.IndentSize,
 	}
 
-	suite, err := NewTestSuite(*args.TestFile, *args.Fixtures)
+	suite, err := NewTestSuite(*args.TestFile, *args.Fixtures, args.SuiteOptions())
 	if err != nil {
 		fmt.Printf("Failed to initialize test file: %v\n", err)
 		return false
 	}
 	defer suite.Close()
 
-	populateDataStore(&suite.GlobalDataStore, args.Variables)
+	populateDataStore(&suite.GlobalDataStore, args.Variables, testRun{})
 
 	allPassed := true
 	var stepInput StepInput
 	testNo := 0
-	stepInput = interactiveInput(suite.Tests, 0, false, nil)
+	stepInput = interactiveInput(suite.Tests, 0, false, nil, opts.Redactor)
 
 	// Using range will create a slice copy of the tests which won't allow us
 	// to hot reload them.
@@ -325,7 +730,7 @@ func interactiveMode(args ProgramArgs) bool {
 		var err error
 
 		// If test is a websocket, lets step through each request/response
-		if test.Config.Websocket && !test.Config.Skip && !test.SkipTestOnTags(args.Tags) {
+		if test.Config.Websocket && !test.Config.Skip && !test.SkipTestOnTags(args.Tags) && !test.SkipTestOnEnvironment() {
 			totalSteps := 1
 			result = &TestResult{
 				TestCase:  *test,
@@ -353,7 +758,7 @@ func interactiveMode(args ProgramArgs) bool {
 						return false
 					}
 					if !stepInput.FallThrough {
-						stepInput = interactiveInput(suite.Tests, testNo, remaining != 0, result)
+						stepInput = interactiveInput(suite.Tests, testNo, remaining != 0, result, opts.Redactor)
 					}
 					// No retry support for individual websocket messages. Must retry the entire test
 					wsStep += 1
@@ -378,7 +783,7 @@ func interactiveMode(args ProgramArgs) bool {
 			}
 
 			if !stepInput.FallThrough {
-				stepInput = interactiveInput(suite.Tests, testNo, false, result)
+				stepInput = interactiveInput(suite.Tests, testNo, false, result, opts.Redactor)
 				if !stepInput.Retry && !stepInput.HotReload {
 					testNo += 1
 				}
@@ -414,6 +819,8 @@ func main() {
 	var passed bool
 	if *args.Interactive {
 		passed = interactiveMode(args)
+	} else if *args.Explain {
+		passed = explainTests(args)
 	} else {
 		passed = runTests(args)
 	}
@@ -0,0 +1,291 @@
+package arp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+const (
+	CONTRACT_TYPE_OBJ    = "object"
+	CONTRACT_TYPE_ARRAY  = "array"
+	CONTRACT_TYPE_STR    = "string"
+	CONTRACT_TYPE_NUM    = "number"
+	CONTRACT_TYPE_BOOL   = "bool"
+	CONTRACT_TYPE_NULL   = "null"
+	CONTRACT_PATH_PREFIX = "contract."
+
+	ContractTypeErrFmt    = "Expected field of type '%v' but found '%v'"
+	ContractRemovedErrMsg = "Field from recorded contract is missing"
+	ContractAddedErrMsg   = "Field is not part of recorded contract"
+	ContractMissingMsg    = "No recorded contract for test"
+)
+
+// ContractStore records the structure of test responses and verifies later responses still match it. Only the
+// shape of a response (keys and value types) is kept, so values that change between runs don't cause failures.
+type ContractStore struct {
+	Path      string
+	Ignore    []string
+	Contracts map[string]interface{}
+	lock      sync.Mutex
+}
+
+// LoadContracts reads a contract file. A missing file results in an empty store so that it can be recorded.
+func LoadContracts(contractPath string, ignoreList string) (*ContractStore, error) {
+	c := &ContractStore{
+		Path:      contractPath,
+		Contracts: make(map[string]interface{}),
+	}
+	for _, p := range strings.Split(ignoreList, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			c.Ignore = append(c.Ignore, p)
+		}
+	}
+
+	data, err := os.ReadFile(contractPath)
+	if os.IsNotExist(err) {
+		return c, nil
+	} else if err != nil {
+		return nil, fmt.Errorf("failed to read contract file: %v", err)
+	}
+
+	if err := json.Unmarshal(data, &c.Contracts); err != nil {
+		return nil, fmt.Errorf("failed to parse contract file: %v", err)
+	}
+	return c, nil
+}
+
+func (c *ContractStore) Save() error {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	var data bytes.Buffer
+	encoder := json.NewEncoder(&data)
+	encoder.SetEscapeHTML(false)
+	encoder.SetIndent("", IndentStr(1))
+	if err := encoder.Encode(c.Contracts); err != nil {
+		return err
+	}
+	return os.WriteFile(c.Path, data.Bytes(), 0644)
+}
+
+func (c *ContractStore) Record(key string, response interface{}) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	c.Contracts[key] = c.shape(response, "")
+}
+
+// Verify compares the structure of a response against its recorded contract and returns a failing result for
+// every field that drifted.
+func (c *ContractStore) Verify(key string, response interface{}) []*FieldMatcherResult {
+	c.lock.Lock()
+	recorded, ok := c.Contracts[key]
+	c.lock.Unlock()
+	if !ok {
+		return []*FieldMatcherResult{{
+			ObjectKeyPath: contractResultPath(""),
+			Error:         ContractMissingMsg,
+			Status:        true,
+		}}
+	}
+
+	var results []*FieldMatcherResult
+	c.compare(recorded, c.shape(response, ""), "", &results)
+	return results
+}
+
+// Apply records or verifies the contracts of all test results. Verification failures are added to the test results
+// and mark them as failed. Returns false if any contract was violated.
+func (c *ContractStore) Apply(results []MultiSuiteResult, testRoot string, record bool) bool {
+	passed := true
+	for i := range results {
+		suiteResult := &results[i]
+		for _, testResult := range suiteResult.TestResults.Results {
+			// skipped and failed to execute tests don't have a response to compare
+			if testResult.Response == nil {
+				continue
+			}
+
+			key := contractKey(testRoot, suiteResult.TestFile, testResult.TestCase.Config.Name)
+			if record {
+				c.Record(key, testResult.Response)
+				continue
+			}
+
+			fields := c.Verify(key, testResult.Response)
+			testResult.Fields = append(testResult.Fields, fields...)
+			for _, f := range fields {
+				if f.Status {
+					continue
+				}
+				if testResult.Passed {
+					testResult.Passed = false
+					suiteResult.TestResults.Passed -= 1
+					suiteResult.TestResults.Failed += 1
+				}
+				suiteResult.Passed = false
+				passed = false
+			}
+		}
+	}
+	return passed
+}
+
+func contractKey(testRoot string, testFile string, testName string) string {
+	file := filepath.Base(testFile)
+	if testRoot != "" {
+		if rel, err := filepath.Rel(testRoot, testFile); err == nil {
+			file = rel
+		}
+	}
+	return fmt.Sprintf("%v > %v", filepath.ToSlash(file), testName)
+}
+
+func (c *ContractStore) isIgnored(fieldPath string, key string) bool {
+	for _, p := range c.Ignore {
+		if matched, _ := path.Match(p, fieldPath); matched {
+			return true
+		}
+		if matched, _ := path.Match(p, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+func contractResultPath(fieldPath string) string {
+	return strings.TrimSuffix(CONTRACT_PATH_PREFIX+fieldPath, ".")
+}
+
+func joinContractPath(parent string, key string) string {
+	if parent == "" {
+		return key
+	}
+	return parent + "." + key
+}
+
+// shape replaces every value of a JSON node with the name of its type. Array elements are merged into a single
+// element describing all of them. Ignored fields are dropped.
+func (c *ContractStore) shape(node interface{}, fieldPath string) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{})
+		for k, v := range n {
+			childPath := joinContractPath(fieldPath, k)
+			if c.isIgnored(childPath, k) {
+				continue
+			}
+			obj[k] = c.shape(v, childPath)
+		}
+		return obj
+	case []interface{}:
+		var merged interface{}
+		for _, e := range n {
+			merged = mergeShapes(merged, c.shape(e, fieldPath))
+		}
+		if merged == nil {
+			return []interface{}{}
+		}
+		return []interface{}{merged}
+	case string:
+		return CONTRACT_TYPE_STR
+	case float64, int, int64:
+		return CONTRACT_TYPE_NUM
+	case bool:
+		return CONTRACT_TYPE_BOOL
+	}
+	return CONTRACT_TYPE_NULL
+}
+
+func mergeShapes(a interface{}, b interface{}) interface{} {
+	if a == nil || a == CONTRACT_TYPE_NULL {
+		return b
+	}
+	aObj, aOk := a.(map[string]interface{})
+	bObj, bOk := b.(map[string]interface{})
+	if aOk && bOk {
+		for k, v := range bObj {
+			aObj[k] = mergeShapes(aObj[k], v)
+		}
+	}
+	return a
+}
+
+func shapeType(s interface{}) string {
+	switch v := s.(type) {
+	case map[string]interface{}:
+		return CONTRACT_TYPE_OBJ
+	case []interface{}:
+		return CONTRACT_TYPE_ARRAY
+	case string:
+		return v
+	}
+	return CONTRACT_TYPE_NULL
+}
+
+func (c *ContractStore) compare(recorded interface{}, actual interface{}, fieldPath string, results *[]*FieldMatcherResult) {
+	recordedType := shapeType(recorded)
+	actualType := shapeType(actual)
+
+	// null values don't tell us anything about the type a field is supposed to have
+	if recordedType == CONTRACT_TYPE_NULL || actualType == CONTRACT_TYPE_NULL {
+		return
+	}
+
+	if recordedType != actualType {
+		*results = append(*results, &FieldMatcherResult{
+			ObjectKeyPath: contractResultPath(fieldPath),
+			Error:         fmt.Sprintf(ContractTypeErrFmt, recordedType, actualType),
+		})
+		return
+	}
+
+	switch r := recorded.(type) {
+	case map[string]interface{}:
+		a := actual.(map[string]interface{})
+		var keys []string
+		for k := range r {
+			keys = append(keys, k)
+		}
+		for k := range a {
+			if _, ok := r[k]; !ok {
+				keys = append(keys, k)
+			}
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			childPath := joinContractPath(fieldPath, k)
+			if c.isIgnored(childPath, k) {
+				continue
+			}
+			recordedChild, rOk := r[k]
+			actualChild, aOk := a[k]
+			if !aOk {
+				*results = append(*results, &FieldMatcherResult{
+					ObjectKeyPath: contractResultPath(childPath),
+					Error:         ContractRemovedErrMsg,
+				})
+			} else if !rOk {
+				*results = append(*results, &FieldMatcherResult{
+					ObjectKeyPath: contractResultPath(childPath),
+					Error:         ContractAddedErrMsg,
+				})
+			} else {
+				c.compare(recordedChild, actualChild, childPath, results)
+			}
+		}
+	case []interface{}:
+		a := actual.([]interface{})
+		// empty arrays have no element structure to compare
+		if len(r) > 0 && len(a) > 0 {
+			c.compare(r[0], a[0], fieldPath, results)
+		}
+	}
+}
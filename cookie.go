@@ -0,0 +1,64 @@
+package arp
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	CFG_RESPONSE_COOKIES = "cookies"
+
+	// Keys of the attributes of each cookie set by a response
+	COOKIE_VALUE     = "Value"
+	COOKIE_PATH      = "Path"
+	COOKIE_DOMAIN    = "Domain"
+	COOKIE_EXPIRES   = "Expires"
+	COOKIE_MAX_AGE   = "MaxAge"
+	COOKIE_SECURE    = "Secure"
+	COOKIE_HTTP_ONLY = "HttpOnly"
+	COOKIE_SAME_SITE = "SameSite"
+	COOKIE_RAW       = "Raw"
+
+	CookiesPath = "response.Cookies"
+)
+
+// getCookiesJson parses the cookies set by a response into a map of cookie names to their attributes so that flags
+// such as HttpOnly can be validated without matching against the raw Set-Cookie headers. Attributes missing from the
+// Set-Cookie header are omitted so that they can be validated with 'exists: false', except for the Secure and
+// HttpOnly flags which are always present. Cookies set more than once keep the attributes of the last one.
+func getCookiesJson(response *http.Response) map[string]interface{} {
+	cookies := make(map[string]interface{})
+	for _, c := range response.Cookies() {
+		cookie := map[string]interface{}{
+			COOKIE_VALUE:     c.Value,
+			COOKIE_SECURE:    c.Secure,
+			COOKIE_HTTP_ONLY: c.HttpOnly,
+			COOKIE_RAW:       c.Raw,
+		}
+		if c.Path != "" {
+			cookie[COOKIE_PATH] = c.Path
+		}
+		if c.Domain != "" {
+			cookie[COOKIE_DOMAIN] = c.Domain
+		}
+		if !c.Expires.IsZero() {
+			cookie[COOKIE_EXPIRES] = c.Expires.UTC().Format(time.RFC3339)
+		}
+		// Max-Age=0 and negative values are both parsed as -1, meaning the cookie is deleted immediately
+		if c.MaxAge > 0 {
+			cookie[COOKIE_MAX_AGE] = c.MaxAge
+		} else if c.MaxAge < 0 {
+			cookie[COOKIE_MAX_AGE] = 0
+		}
+		switch c.SameSite {
+		case http.SameSiteStrictMode:
+			cookie[COOKIE_SAME_SITE] = "Strict"
+		case http.SameSiteLaxMode:
+			cookie[COOKIE_SAME_SITE] = "Lax"
+		case http.SameSiteNoneMode:
+			cookie[COOKIE_SAME_SITE] = "None"
+		}
+		cookies[c.Name] = cookie
+	}
+	return cookies
+}
@@ -0,0 +1,103 @@
+package arp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestGetCookiesJson(t *testing.T) {
+	tests := []struct {
+		name      string
+		setCookie []string
+		expected  map[string]interface{}
+	}{
+		{"no cookies", nil, map[string]interface{}{}},
+		{"value only", []string{"session=abc"}, map[string]interface{}{
+			"session": map[string]interface{}{
+				COOKIE_VALUE: "abc", COOKIE_SECURE: false, COOKIE_HTTP_ONLY: false, COOKIE_RAW: "session=abc",
+			},
+		}},
+		{"all attributes", []string{"session=abc; Path=/; Domain=example.com; Expires=Wed, 21 Oct 2026 07:28:00 GMT; Max-Age=3600; Secure; HttpOnly; SameSite=Strict"}, map[string]interface{}{
+			"session": map[string]interface{}{
+				COOKIE_VALUE:     "abc",
+				COOKIE_PATH:      "/",
+				COOKIE_DOMAIN:    "example.com",
+				COOKIE_EXPIRES:   "2026-10-21T07:28:00Z",
+				COOKIE_MAX_AGE:   3600,
+				COOKIE_SECURE:    true,
+				COOKIE_HTTP_ONLY: true,
+				COOKIE_SAME_SITE: "Strict",
+				COOKIE_RAW:       "session=abc; Path=/; Domain=example.com; Expires=Wed, 21 Oct 2026 07:28:00 GMT; Max-Age=3600; Secure; HttpOnly; SameSite=Strict",
+			},
+		}},
+		{"deleted", []string{"session=; Max-Age=0; SameSite=Lax"}, map[string]interface{}{
+			"session": map[string]interface{}{
+				COOKIE_VALUE: "", COOKIE_MAX_AGE: 0, COOKIE_SAME_SITE: "Lax", COOKIE_SECURE: false, COOKIE_HTTP_ONLY: false,
+				COOKIE_RAW: "session=; Max-Age=0; SameSite=Lax",
+			},
+		}},
+		{"set twice", []string{"theme=light; SameSite=None", "theme=dark"}, map[string]interface{}{
+			"theme": map[string]interface{}{
+				COOKIE_VALUE: "dark", COOKIE_SECURE: false, COOKIE_HTTP_ONLY: false, COOKIE_RAW: "theme=dark",
+			},
+		}},
+	}
+
+	for _, tt := range tests {
+		response := &http.Response{Header: http.Header{"Set-Cookie": tt.setCookie}}
+		if cookies := getCookiesJson(response); !reflect.DeepEqual(cookies, tt.expected) {
+			t.Errorf("%v: expected %v but got %v", tt.name, ToJsonStr(tt.expected), ToJsonStr(cookies))
+		}
+	}
+}
+
+func TestValidateCookies(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Set-Cookie", "session=abc; Path=/; Secure; HttpOnly; SameSite=Strict")
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		cookies string
+		passed  bool
+	}{
+		{"flags", "session:\n  type: object\n  properties:\n    HttpOnly: true\n    Secure: true\n    SameSite: Strict", true},
+		{"value", "session:\n  type: object\n  properties:\n    Value: abc\n    Path: /", true},
+		{"missing attribute", "session:\n  type: object\n  properties:\n    Domain:\n      type: string\n      exists: false", true},
+		{"wrong flag", "session:\n  type: object\n  properties:\n    HttpOnly: false", false},
+		{"missing cookie", "theme:\n  type: object\n  properties:\n    Value: dark", false},
+	}
+
+	for _, tt := range tests {
+		result := runTestFile(t, `
+tests:
+  - name: Cookies
+    route: "@{host}/login"
+    method: POST
+    response:
+      code: 200
+      cookies:
+        `+strings.ReplaceAll(tt.cookies, "\n", "\n        ")+`
+      payload:
+        id: 1
+`, server.URL, SuiteOptions{})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("%v: expected 1 result but got %v", tt.name, len(result.Results))
+		}
+		r := result.Results[0]
+		failed := failedFields(r)
+		if r.Passed != tt.passed {
+			t.Errorf("%v: expected the test to pass: %v but got:\n%v", tt.name, tt.passed, failed)
+		}
+		if !tt.passed && !strings.Contains(failed, CookiesPath) {
+			t.Errorf("%v: expected %v to fail but got:\n%v", tt.name, CookiesPath, failed)
+		}
+	}
+}
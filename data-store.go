@@ -8,10 +8,31 @@ import (
 const (
 	VAR_PREFIX = "@{"
 	VAR_SUFFIX = "}"
+
+	// Prefix of data store keys that are written to the root store (e.g. 'storeAs: global:token') rather than the
+	// store of an isolated test
+	DS_GLOBAL_PREFIX = "global:"
 )
 
 type DataStore struct {
 	Store map[string]interface{}
+	// Store to look up variables that are missing from this one. Set for the stores of isolated tests so that they
+	// can read the suite's variables without their own writes leaking back into it.
+	Parent *DataStore `json:",omitempty"`
+	// Values stored from test responses, keyed by variable name. Only tracked by root stores.
+	Captures map[string]CapturedValue `json:"-"`
+	// Name of the test currently storing values, recorded with each captured value
+	CaptureSource string `json:"-"`
+	// Set for stores whose writes are thrown away, such as those of the earlier attempts of a repeated test, so that
+	// values stored with the global prefix stay in them rather than being written to the root store
+	detached bool
+}
+
+// CapturedValue is a value stored from a test response with 'storeAs' or 'capture', as opposed to one loaded from
+// fixtures or the environment
+type CapturedValue struct {
+	Value interface{} `json:"value"`
+	Test  string      `json:"test"`
 }
 
 func isVar(input string) bool {
@@ -41,17 +62,93 @@ func NewDataStore() DataStore {
 	}
 }
 
+// NewChildDataStore creates an empty store that falls back to the parent store for any variables it doesn't define
+func NewChildDataStore(parent *DataStore) DataStore {
+	child := NewDataStore()
+	child.Parent = parent
+	return child
+}
+
+// NewDetachedDataStore creates a child store that also keeps the values stored with the global prefix to itself
+func NewDetachedDataStore(parent *DataStore) DataStore {
+	child := NewChildDataStore(parent)
+	child.detached = true
+	return child
+}
+
+// Root returns the top most store that this one is layered over, or itself if it has no parent
+func (t *DataStore) Root() *DataStore {
+	root := t
+	for root.Parent != nil {
+		root = root.Parent
+	}
+	return root
+}
+
+// writeRoot returns the store that values with the global prefix are written to: the root store, unless the store
+// is layered over a detached one
+func (t *DataStore) writeRoot() *DataStore {
+	root := t
+	for root.Parent != nil && !root.detached {
+		root = root.Parent
+	}
+	return root
+}
+
 func (t *DataStore) Put(key string, value interface{}) {
+	if strings.HasPrefix(key, DS_GLOBAL_PREFIX) {
+		t.writeRoot().Store[strings.TrimPrefix(key, DS_GLOBAL_PREFIX)] = value
+		return
+	}
 	t.Store[key] = value
 }
 
+// Capture stores a value derived from a test response and records it, along with the test that stored it, in the
+// captures of the root store
+func (t *DataStore) Capture(key string, value interface{}) {
+	t.Put(key, value)
+
+	root := t.writeRoot()
+	if root.Captures == nil {
+		root.Captures = make(map[string]CapturedValue)
+	}
+	root.Captures[strings.TrimPrefix(key, DS_GLOBAL_PREFIX)] = CapturedValue{
+		Value: value,
+		Test:  root.CaptureSource,
+	}
+}
+
 func (t *DataStore) Get(key string) interface{} {
-	return t.Store[key]
+	if v, ok := t.Store[key]; ok || t.Parent == nil {
+		return v
+	}
+	return t.Parent.Get(key)
+}
+
+// Variables returns every variable visible to the store, including those of its parents that it doesn't override
+func (t *DataStore) Variables() map[string]interface{} {
+	if t.Parent == nil {
+		return t.Store
+	}
+	vars := t.Parent.Variables()
+	merged := make(map[string]interface{}, len(vars)+len(t.Store))
+	for k, v := range vars {
+		merged[k] = v
+	}
+	for k, v := range t.Store {
+		merged[k] = v
+	}
+	return merged
 }
 
 func (t *DataStore) resolveVariable(variable string) (interface{}, error) {
 	cleanedVar := variable[len(VAR_PREFIX) : len(variable)-len(VAR_SUFFIX)]
-	return GetJsonValue(t.Store, cleanedVar)
+	value, err := GetJsonValue(t.Store, cleanedVar)
+	if err != nil && t.Parent != nil {
+		// layered lookup: variables the store doesn't define are read from its parent
+		return t.Parent.resolveVariable(variable)
+	}
+	return value, err
 }
 
 // PutVariable Given a variable name (or path in a JSON object) store the value for said path.
@@ -0,0 +1,59 @@
+package arp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+
+	"gopkg.in/yaml.v2"
+)
+
+// DatasetRow holds the variables a run of the tests is seeded with when executing them once per row of a dataset
+type DatasetRow map[string]interface{}
+
+// LoadDataset loads a yaml or JSON file containing a list of variable maps, such as the credentials of different
+// user roles, to execute the tests with once per row.
+func LoadDataset(path string) ([]DatasetRow, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read dataset: %v", err)
+	}
+
+	var raw []interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse dataset: %v - %v", path, err)
+	}
+	if len(raw) == 0 {
+		return nil, fmt.Errorf("dataset does not contain any rows: %v", path)
+	}
+
+	rows := make([]DatasetRow, len(raw))
+	for i, r := range raw {
+		row, ok := YamlToJson(r).(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("dataset row %v is not a map of variables: %v", i+1, path)
+		}
+		rows[i] = row
+	}
+	return rows, nil
+}
+
+// RowName names a row of the dataset by its position, starting at 1
+func RowName(row int) string {
+	return fmt.Sprintf("row %v", row)
+}
+
+// PrintDatasetSummary prints whether the tests passed with each row of the dataset along with the row's variables.
+// Sensitive values are redacted as in the rest of the report.
+func PrintDatasetSummary(opts ReportOptions, results []MultiSuiteResult) {
+	names := make([]string, len(opts.Dataset))
+	for i := range opts.Dataset {
+		names[i] = RowName(i + 1)
+	}
+
+	summaries := SummarizeRuns(names, results, func(r MultiSuiteResult) string { return RowName(r.Row) })
+	printRunSummary(opts, "Dataset", summaries, func(i int) string {
+		data, _ := json.Marshal(opts.Redactor.Redact(map[string]interface{}(opts.Dataset[i])))
+		return string(data)
+	})
+}
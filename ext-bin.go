@@ -10,6 +10,11 @@ import (
 	"os"
 )
 
+const (
+	BIN_KEY_SHA256 = "sha256sum"
+	BIN_KEY_SAVED  = "saved"
+)
+
 // Default built-in handler and validator for responses containing binary data.
 type BinaryParser struct {
 	Fallback bool
@@ -27,6 +32,42 @@ type BinResponseJson struct {
 	SHA256Sum string   `json:"sha256sum"`
 }
 
+// NewBinaryParser creates a parser saving binary responses to the test's 'filePath'. Responses of tests that store the
+// saved path with 'storeAs' are saved to a temporary file when no 'filePath' is given, which is removed once the
+// test's suite has been executed.
+func NewBinaryParser(test *TestCase) (*BinaryParser, error) {
+	resolved, err := test.GlobalDataStore.ExpandVariable(test.Config.Response.FilePath)
+	if err != nil {
+		return nil, fmt.Errorf(BadVarMatcherFmt, test.Config.Response.FilePath)
+	}
+	parser := &BinaryParser{SavePath: varToString(resolved, test.Config.Response.FilePath)}
+
+	if parser.SavePath == "" && test.Config.Response.StoreAs != "" {
+		f, err := os.CreateTemp("", RESPONSE_PATH_FMT)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create temporary file: %v", err)
+		}
+		f.Close()
+		parser.SavePath = f.Name()
+		test.TempFiles = append(test.TempFiles, f.Name())
+	}
+	return parser, nil
+}
+
+// storeSavedPath stores the path a binary response was saved to under the test's 'storeAs' name so that later tests
+// can upload or process the file, e.g. '@{downloadedFile}'
+func (t *TestCase) storeSavedPath(response map[string]interface{}) error {
+	if t.Config.Response.StoreAs == "" {
+		return nil
+	}
+	saved, ok := response[BIN_KEY_SAVED].(string)
+	if !ok || saved == "" {
+		return fmt.Errorf("no binary response was saved to store as '%v'", t.Config.Response.StoreAs)
+	}
+	t.GlobalDataStore.Capture(t.Config.Response.StoreAs, saved)
+	return nil
+}
+
 // Implement ResponseHandler
 func (bp *BinaryParser) Parse(response *http.Response) (map[string]interface{}, interface{}, error) {
 	rj, err := getBinaryJson(bp.SavePath, !bp.Fallback, response.Body)
@@ -38,7 +79,8 @@ func (bp *BinaryParser) Parse(response *http.Response) (map[string]interface{},
 
 // Implement ResponseValidator
 func (bp *BinaryParser) Validate(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error) {
-	return test.ResponseMatcher.Match(result.Response)
+	payloadMatcher, _, _ := test.GetResponseMatchers(result.StatusCode)
+	return payloadMatcher.Match(result.Response)
 }
 
 func (bj *BinResponseJson) GenericJSON() map[string]interface{} {
@@ -78,7 +120,7 @@ func getBinaryJson(savePath string, isExpected bool, response io.Reader) (map[st
 	}
 
 	if targetPath != "" {
-		f, fErr := os.OpenFile(targetPath, os.O_CREATE|os.O_RDWR, 0700)
+		f, fErr := os.OpenFile(targetPath, os.O_CREATE|os.O_RDWR|os.O_TRUNC, 0700)
 		if fErr != nil {
 			return nil, fmt.Errorf("failed to open file %v while writing response: %v", savePath, fErr)
 		}
@@ -86,6 +128,7 @@ func getBinaryJson(savePath string, isExpected bool, response io.Reader) (map[st
 	}
 
 	if file != nil {
+		defer file.Close()
 		io.Copy(file, hashReader)
 		responseJson.Saved = file.Name()
 	} else {
@@ -103,3 +146,18 @@ func getBinaryJson(savePath string, isExpected bool, response io.Reader) (map[st
 
 	return responseJson.GenericJSON(), nil
 }
+
+// fileSha256 returns the hex encoded sha256 sum of a file's contents
+func fileSha256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	hasher := sha256.New()
+	if _, err := io.Copy(hasher, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(hasher.Sum(nil)), nil
+}
@@ -0,0 +1,96 @@
+package arp
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestBinaryResponseOverwritesSavedFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "download.bin")
+	if err := os.WriteFile(path, []byte("a previous and much longer download"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := getBinaryJson(path, true, strings.NewReader("new")); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("expected the saved file to be replaced but got '%v'", string(data))
+	}
+}
+
+func TestDownloadThenUpload(t *testing.T) {
+	download := bytes.Repeat([]byte{0, 1, 2, 255}, 256)
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/download":
+			w.Header().Set(HEADER_CONTENT_TYPE, "application/octet-stream")
+			w.Write(download)
+		case "/upload":
+			file, header, err := r.FormFile("file")
+			if err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			uploaded, _ := io.ReadAll(file)
+			w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+			json.NewEncoder(w).Encode(map[string]interface{}{
+				"same": bytes.Equal(uploaded, download),
+				"name": header.Filename,
+			})
+		}
+	}))
+	defer server.Close()
+
+	result := runTestFile(t, `
+tests:
+  - name: Download
+    route: "@{host}/download"
+    method: GET
+    response:
+      code: 200
+      type: binary
+      storeAs: downloadedFile
+  - name: Upload
+    route: "@{host}/upload"
+    method: POST
+    formInput: true
+    input:
+      file:
+        - "@{downloadedFile}"
+    response:
+      code: 200
+      payload:
+        same: true
+        name: "binary-response-\\d+"
+`, server.URL, SuiteOptions{})
+
+	if len(result.Results) != 2 {
+		t.Fatalf("expected 2 results but got %v", len(result.Results))
+	}
+	for _, r := range result.Results {
+		if !r.Passed {
+			t.Errorf("%v failed:\n%v", r.TestCase.Config.Name, failedFields(r))
+		}
+	}
+
+	// the temporary file is removed once the tests have been executed
+	saved, ok := result.Captures["downloadedFile"]
+	if !ok {
+		t.Fatal("the path of the downloaded file wasn't stored")
+	}
+	if _, err := os.Stat(saved.Value.(string)); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary file to be removed but got %v", err)
+	}
+}
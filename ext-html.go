@@ -43,7 +43,8 @@ func (hp *HtmlExt) Parse(response *http.Response) (map[string]interface{}, inter
 // Implement ResponseValidator
 func (hp *HtmlExt) Validate(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error) {
 	response := result.RawResponse
-	rMatcher := test.ResponseMatcher
+	payloadMatcher, _, _ := test.GetResponseMatchers(result.StatusCode)
+	rMatcher := *payloadMatcher
 
 	var docReader *goquery.Document
 	if v, ok := response.(*html.Node); ok {
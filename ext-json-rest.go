@@ -1,15 +1,26 @@
 package arp
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"io/ioutil"
 	"net/http"
 	"strings"
 )
 
+const (
+	TruncatedBodyErrFmt  = "response body was truncated (connection closed mid-stream) after %v bytes"
+	IncompleteJsonErrFmt = "failed to unmarshal JSON response: the body ended after %v bytes before the JSON was complete"
+)
+
 // Default built-in response handler and validator for JSON rest APIs
-type JSONParser struct{}
+type JSONParser struct {
+	// attempt to parse bodies without a JSON or text content type, falling back to binary if they aren't JSON
+	AssumeJson bool
+}
 
 // Implement ResponseHandler
 func (jp *JSONParser) Parse(response *http.Response) (map[string]interface{}, interface{}, error) {
@@ -18,18 +29,37 @@ func (jp *JSONParser) Parse(response *http.Response) (map[string]interface{}, in
 	// expecting JSON response, we can assume (hopefully) that the JSON data will fit in memory
 	var responseJson map[string]interface{}
 	var responseData []byte
+	declared := false
 	for _, t := range headers.Values(HEADER_CONTENT_TYPE) {
 		if strings.Contains(t, MIME_JSON) || strings.Contains(t, MIME_TEXT) {
-			var rErr error
-			responseData, rErr = ioutil.ReadAll(body)
-			if rErr != nil {
-				return nil, nil, fmt.Errorf("failed to parse API response: %v", rErr)
-			}
+			declared = true
 			break
 		}
 	}
+	if declared || jp.AssumeJson {
+		var rErr error
+		responseData, rErr = ioutil.ReadAll(body)
+		if errors.Is(rErr, io.ErrUnexpectedEOF) {
+			// the connection closed before the declared length or the final chunk was received
+			return nil, nil, fmt.Errorf(TruncatedBodyErrFmt, len(responseData))
+		}
+		if rErr != nil {
+			return nil, nil, fmt.Errorf("failed to parse API response: %v", rErr)
+		}
+	}
 	if len(responseData) > 0 {
 		if err := json.Unmarshal(responseData, &responseJson); err != nil {
+			if !declared {
+				// the body was only assumed to be JSON, so let the binary parser read it again instead
+				response.Body = ioutil.NopCloser(bytes.NewReader(responseData))
+				return nil, nil, InvalidContentType
+			}
+			// bodies without a declared length end when the connection closes, so the JSON may have been cut short
+			// without a read error. encoding/json only reports this through the message of its syntax error.
+			var syntaxErr *json.SyntaxError
+			if errors.As(err, &syntaxErr) && syntaxErr.Error() == "unexpected end of JSON input" {
+				return nil, nil, fmt.Errorf(IncompleteJsonErrFmt, len(responseData))
+			}
 			return nil, nil, fmt.Errorf("failed to unmarshal JSON response: %v", err)
 		}
 	} else {
@@ -40,6 +70,26 @@ func (jp *JSONParser) Parse(response *http.Response) (map[string]interface{}, in
 	return responseJson, nil, nil
 }
 
+// AssumesJson returns whether the response of the test is parsed as JSON regardless of its content type. A test's
+// own 'assumeJson' takes precedence over the option of the run.
+func (t *TestCase) AssumesJson() bool {
+	if t.Config.Response.AssumeJson != nil {
+		return *t.Config.Response.AssumeJson
+	}
+	return t.AssumeJson
+}
+
+// validationError reports an error that occurred while validating a section of the response as a failed result
+// so that the remaining sections can still be validated.
+func validationError(path string, err error) *FieldMatcherResult {
+	return &FieldMatcherResult{
+		ObjectKeyPath:   path,
+		Error:           err.Error(),
+		Status:          false,
+		ShowExtendedMsg: true,
+	}
+}
+
 // Implement ResponseValidator
 func (jp *JSONParser) Validate(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error) {
 	statusCode := result.StatusCode
@@ -52,38 +102,66 @@ func (jp *JSONParser) Validate(test *TestCase, result *TestResult) (bool, []*Fie
 	sPassed, sResult, sErr := test.StatusCodeMatcher.Match(map[string]interface{}{
 		CFG_RESPONSE_CODE: statusCode,
 	})
-	if sErr != nil {
-		return false, sResult, sErr
-	}
 	for _, sR := range sResult {
 		sR.ObjectKeyPath = StatusCodePath
 		newResults = append(newResults, sR)
 	}
+	if sErr != nil {
+		sPassed = false
+		newResults = append(newResults, validationError(StatusCodePath, sErr))
+	}
 
-	// Validate Response Data
-	var status bool
-	var results []*FieldMatcherResult
-	var err error
-	status, results, err = test.ResponseMatcher.Match(response)
-
+	// Validate Response Data using the matchers for the response's status code
+	payloadMatcher, headerMatcher, payloadCfg := test.GetResponseMatchers(statusCode)
+	status, results, err := payloadMatcher.Match(response)
+	newResults = append(newResults, results...)
 	if err != nil {
-		return false, results, err
+		status = false
+		newResults = append(newResults, validationError(PayloadPath, err))
 	}
-	newResults = append(newResults, results...)
 
-	// Validate response headers
-	headerStatus, headerResults, headerErr := test.ResponseHeaderMatcher.Match(headers)
-	if headerErr != nil {
-		return false, headerResults, headerErr
+	// Validate the response doesn't contain anything beyond what was declared
+	ignored := make(map[string]bool)
+	if test.Config.Response.Exact {
+		declared := test.IgnoredPaths.PruneDefinition(payloadCfg)
+		exactResults := exactObjectDiff(declared, test.IgnoredPaths.Prune(response, ignored), "")
+		newResults = append(newResults, exactResults...)
+		status = status && len(exactResults) == 0
+	}
+
+	// Validate the response against an example from an OpenAPI spec
+	if test.Config.Response.Example != nil {
+		for _, r := range test.validateExample(statusCode, response, ignored) {
+			newResults = append(newResults, r)
+			status = status && r.Status
+		}
 	}
+	if r := ignoredResult(ignored); r != nil {
+		newResults = append(newResults, r)
+	}
+
+	// Validate the response contains the expected validation errors
+	if len(test.ValidationErrors) > 0 {
+		vPassed, vResults := test.validateValidationErrors(response)
+		newResults = append(newResults, vResults...)
+		status = status && vPassed
+	}
+
+	// Validate response headers
+	headerStatus, headerResults, headerErr := headerMatcher.Match(headers)
 	for _, hR := range headerResults {
 		hR.ObjectKeyPath = HeadersPath + hR.ObjectKeyPath
 		newResults = append(newResults, hR)
 	}
+	if headerErr != nil {
+		headerStatus = false
+		newResults = append(newResults, validationError(HeadersPath, headerErr))
+	}
+
 	// Wrap things up
 	if status && headerStatus && sPassed {
-		for k := range test.ResponseMatcher.DS.Store {
-			test.GlobalDataStore.Put(k, test.ResponseMatcher.DS.Get(k))
+		for k := range payloadMatcher.DS.Store {
+			test.GlobalDataStore.Put(k, payloadMatcher.DS.Get(k))
 		}
 	}
 	return status && headerStatus && sPassed, newResults, nil
@@ -0,0 +1,112 @@
+package arp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestAssumeJson(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// keep the server from sniffing a content type
+		w.Header()[HEADER_CONTENT_TYPE] = nil
+		switch r.URL.Path {
+		case "/json":
+			w.Write([]byte(`{"id": 1}`))
+		case "/binary":
+			w.Write([]byte{0, 1, 2, 3})
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		option   bool
+		override string
+		path     string
+		payload  string
+		passed   bool
+	}{
+		{"not assumed", false, "", "/json", "id: 1", false},
+		{"assumed by the test", false, "assumeJson: true", "/json", "id: 1", true},
+		{"assumed by the option", true, "", "/json", "id: 1", true},
+		{"option overridden by the test", true, "assumeJson: false", "/json", "id: 1", false},
+		{"binary fallback", true, "", "/binary", BIN_KEY_SHA256 + ":\n          type: string\n          matches: $any", true},
+	}
+
+	for _, tt := range tests {
+		result := runTestFile(t, `
+tests:
+  - name: Assume JSON
+    route: "@{host}`+tt.path+`"
+    method: GET
+    response:
+      code: 200
+      `+tt.override+`
+      payload:
+        `+tt.payload+`
+`, server.URL, SuiteOptions{AssumeJson: tt.option})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("%v: expected 1 result but got %v", tt.name, len(result.Results))
+		}
+		if r := result.Results[0]; r.Passed != tt.passed {
+			t.Errorf("%v: expected the test to pass: %v but got:\n%v", tt.name, tt.passed, failedFields(r))
+		}
+	}
+}
+
+func TestJSONParserTruncated(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, buf, _ := w.(http.Hijacker).Hijack()
+		defer conn.Close()
+		head := "HTTP/1.1 200 OK\r\nContent-Type: application/json\r\n"
+		switch r.URL.Path {
+		case "/length":
+			// closes the connection before the declared length is sent
+			buf.WriteString(head + "Content-Length: 100\r\n\r\n{\"id\": 1")
+		case "/chunked":
+			// closes the connection before the final chunk is sent
+			buf.WriteString(head + "Transfer-Encoding: chunked\r\n\r\n8\r\n{\"id\": 1\r\n")
+		case "/unbounded":
+			// without a length, the body ends when the connection closes
+			buf.WriteString(head + "Connection: close\r\n\r\n{\"id\": 1")
+		case "/malformed":
+			buf.WriteString(head + "Content-Length: 8\r\n\r\n{\"id\": }")
+		case "/complete":
+			buf.WriteString(head + "Content-Length: 9\r\n\r\n{\"id\": 1}")
+		}
+		buf.Flush()
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		path     string
+		expected string
+	}{
+		{"/length", fmt.Sprintf(TruncatedBodyErrFmt, 8)},
+		{"/chunked", fmt.Sprintf(TruncatedBodyErrFmt, 8)},
+		{"/unbounded", fmt.Sprintf(IncompleteJsonErrFmt, 8)},
+		{"/malformed", "failed to unmarshal JSON response: invalid character '}'"},
+		{"/complete", ""},
+	}
+
+	for _, tt := range tests {
+		response, err := http.Get(server.URL + tt.path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		parser := JSONParser{}
+		_, _, err = parser.Parse(response)
+		response.Body.Close()
+
+		if tt.expected == "" && err != nil {
+			t.Errorf("%v: unexpected error: %v", tt.path, err)
+		}
+		if tt.expected != "" && (err == nil || !strings.HasPrefix(err.Error(), tt.expected)) {
+			t.Errorf("%v: expected '%v' but got: %v", tt.path, tt.expected, err)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package arp
+
+import (
+	"bytes"
+	"fmt"
+	"io/ioutil"
+	"mime"
+	"net/http"
+)
+
+const (
+	CFG_RESPONSE_TYPE_MSGPACK    = "msgpack"
+	CFG_REQUEST_ENCODING_JSON    = "json"
+	CFG_REQUEST_ENCODING_MSGPACK = "msgpack"
+
+	MIME_MSGPACK = "application/msgpack"
+)
+
+// MsgpackExt parses MessagePack responses into the same generic types as JSON so that they can be validated with
+// the existing matchers. Responses declaring a JSON content type, such as error responses, are parsed as JSON and
+// anything that isn't a MessagePack map falls back to its binary representation.
+type MsgpackExt struct{}
+
+// Implement ResponseHandler
+func (mp *MsgpackExt) Parse(response *http.Response) (map[string]interface{}, interface{}, error) {
+	if mediaType, _, err := mime.ParseMediaType(response.Header.Get(HEADER_CONTENT_TYPE)); err == nil && mediaType == MIME_JSON {
+		return (&JSONParser{}).Parse(response)
+	}
+
+	data, err := ioutil.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse API response: %v", err)
+	}
+
+	decoded, err := DecodeMsgpack(data)
+	responseJson, ok := decoded.(map[string]interface{})
+	if err != nil || !ok {
+		// let the binary parser read the body again instead
+		response.Body = ioutil.NopCloser(bytes.NewReader(data))
+		return nil, nil, InvalidContentType
+	}
+	return responseJson, nil, nil
+}
+
+// Implement ResponseValidator
+func (mp *MsgpackExt) Validate(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error) {
+	// once decoded, responses are validated the same as JSON responses, including their status and headers
+	return (&JSONParser{}).Validate(test, result)
+}
+
+// usesMsgpackInput returns whether the input of the test is sent as MessagePack rather than JSON
+func (t *TestCase) usesMsgpackInput() bool {
+	return t.Config.Request.Encoding == CFG_REQUEST_ENCODING_MSGPACK && !t.Config.FormInput && !t.Config.Websocket
+}
+
+// loadRequestEncoding validates the encoding of the test's input
+func (t *TestCase) loadRequestEncoding() error {
+	switch t.Config.Request.Encoding {
+	case "", CFG_REQUEST_ENCODING_JSON:
+		return nil
+	case CFG_REQUEST_ENCODING_MSGPACK:
+		if t.Config.FormInput || t.Config.Websocket {
+			return fmt.Errorf("'request.encoding' %v is not supported for form input or websocket tests: %v",
+				CFG_REQUEST_ENCODING_MSGPACK, t.Config.Name)
+		}
+		return nil
+	}
+	return fmt.Errorf("Invalid 'request.encoding' specified for %v: %v. Expected one of: %v, %v", t.Config.Name,
+		t.Config.Request.Encoding, CFG_REQUEST_ENCODING_JSON, CFG_REQUEST_ENCODING_MSGPACK)
+}
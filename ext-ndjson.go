@@ -0,0 +1,123 @@
+package arp
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+const (
+	CFG_RESPONSE_TYPE_NDJSON = "ndjson"
+
+	// key of the array holding each parsed line of a newline-delimited JSON response
+	NDJSON_LINES = "lines"
+
+	DEFAULT_NDJSON_MAX_LINES    = 1000
+	DEFAULT_NDJSON_READ_TIMEOUT = 30 * time.Second
+)
+
+// Response parser for newline-delimited JSON streams. Each line is parsed as a JSON value into the 'lines' array of
+// the response so that it can be validated with array matchers. Reading stops once the stream ends, the max number
+// of lines is read or the read timeout expires, whichever happens first, so that streams left open can be validated.
+type NDJSONParser struct {
+	MaxLines    int
+	ReadTimeout time.Duration
+}
+
+// NewNDJSONParser creates a parser with the limits of a test, falling back to defaults for any that are missing
+func NewNDJSONParser(test *TestCase) *NDJSONParser {
+	parser := &NDJSONParser{
+		MaxLines:    test.Config.Response.MaxLines,
+		ReadTimeout: test.ReadTimeout,
+	}
+	if parser.MaxLines <= 0 {
+		parser.MaxLines = DEFAULT_NDJSON_MAX_LINES
+	}
+	if parser.ReadTimeout <= 0 {
+		parser.ReadTimeout = DEFAULT_NDJSON_READ_TIMEOUT
+	}
+	return parser
+}
+
+// Implement ResponseHandler
+func (np *NDJSONParser) Parse(response *http.Response) (map[string]interface{}, interface{}, error) {
+	lines := make(chan []byte)
+	readErr := make(chan error, 1)
+	done := make(chan struct{})
+	defer close(done)
+
+	go func() {
+		defer close(lines)
+		reader := bufio.NewReader(response.Body)
+		for {
+			line, err := reader.ReadBytes('\n')
+			if len(line) > 0 {
+				select {
+				case lines <- line:
+				case <-done:
+					return
+				}
+			}
+			if err != nil {
+				if err != io.EOF {
+					readErr <- err
+				}
+				return
+			}
+		}
+	}()
+
+	var timeout <-chan time.Time
+	if np.ReadTimeout > 0 {
+		timer := time.NewTimer(np.ReadTimeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	parsed := []interface{}{}
+	result := func() map[string]interface{} {
+		return map[string]interface{}{NDJSON_LINES: parsed}
+	}
+
+	lineNum := 0
+	for np.MaxLines <= 0 || len(parsed) < np.MaxLines {
+		select {
+		case line, ok := <-lines:
+			if !ok {
+				select {
+				case err := <-readErr:
+					return nil, nil, fmt.Errorf("failed to read NDJSON response: %v", err)
+				default:
+					return result(), nil, nil
+				}
+			}
+			lineNum++
+
+			trimmed := bytes.TrimSpace(line)
+			if len(trimmed) == 0 {
+				continue
+			}
+			var value interface{}
+			if err := json.Unmarshal(trimmed, &value); err != nil {
+				// the last line of a stream can be cut off when the connection ends without a trailing newline
+				if !bytes.HasSuffix(line, []byte("\n")) {
+					continue
+				}
+				return nil, nil, fmt.Errorf("failed to unmarshal NDJSON line %v: %v", lineNum, err)
+			}
+			parsed = append(parsed, value)
+		case <-timeout:
+			// the stream is still open, validate what has been read so far
+			response.Body.Close()
+			return result(), nil, nil
+		}
+	}
+
+	// stop reading the rest of the stream once the max number of lines has been read
+	response.Body.Close()
+	return result(), nil, nil
+}
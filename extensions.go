@@ -1,5 +1,7 @@
 package arp
 
+import "sort"
+
 type ResponseParserAndValidator interface {
 	ResponseValidator
 	ResponseParser
@@ -17,9 +19,40 @@ var (
 			ResponseType: "html",
 			Handler:      &HtmlExt{},
 		},
+		{
+			ResponseType: CFG_RESPONSE_TYPE_MSGPACK,
+			Handler:      &MsgpackExt{},
+		},
 	}
 )
 
+// RegisterExtension makes a custom response type available to tests. It must be called before test files are
+// loaded, as the response type of each test is validated against the registered extensions at load time.
+func RegisterExtension(responseType string, handler ResponseParserAndValidator) {
+	AvailableExtensions = append(AvailableExtensions, Extensions{
+		ResponseType: responseType,
+		Handler:      handler,
+	})
+}
+
+// IsKnownResponseType returns whether a response parser is registered for the response type
+func IsKnownResponseType(responseType string) bool {
+	respParser, _ := LoadExtensions(nil)
+	_, ok := respParser[responseType]
+	return ok
+}
+
+// KnownResponseTypes returns the sorted list of response types with a registered response parser
+func KnownResponseTypes() []string {
+	respParser, _ := LoadExtensions(nil)
+	var types []string
+	for responseType := range respParser {
+		types = append(types, responseType)
+	}
+	sort.Strings(types)
+	return types
+}
+
 func LoadExtensions(extList []string) (ResponseParserHandler, ResponseValidatorHandler) {
 	extPool := AvailableExtensions
 	if extList != nil && len(extList) > 0 {
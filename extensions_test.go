@@ -0,0 +1,97 @@
+package arp
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// lineCountExt parses a plain text response into the number of lines it contains
+type lineCountExt struct{}
+
+func (e *lineCountExt) Parse(response *http.Response) (map[string]interface{}, interface{}, error) {
+	body, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	return map[string]interface{}{"lines": float64(strings.Count(string(body), "\n"))}, nil, nil
+}
+
+func (e *lineCountExt) Validate(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error) {
+	return test.ResponseMatcher.Match(result.Response)
+}
+
+func TestKnownResponseTypes(t *testing.T) {
+	defaults := []string{CFG_RESPONSE_TYPE_BIN, "html", CFG_RESPONSE_TYPE_JSON, CFG_RESPONSE_TYPE_MSGPACK, CFG_RESPONSE_TYPE_NDJSON}
+	for _, responseType := range defaults {
+		if !IsKnownResponseType(responseType) {
+			t.Errorf("expected '%v' to be a known response type", responseType)
+		}
+	}
+	if IsKnownResponseType("lines") {
+		t.Error("expected 'lines' to be unknown before it is registered")
+	}
+
+	registered := AvailableExtensions
+	defer func() { AvailableExtensions = registered }()
+	RegisterExtension("lines", &lineCountExt{})
+
+	if !IsKnownResponseType("lines") {
+		t.Error("expected 'lines' to be known once it is registered")
+	}
+	types := KnownResponseTypes()
+	if !reflect.DeepEqual(types, []string{CFG_RESPONSE_TYPE_BIN, "html", CFG_RESPONSE_TYPE_JSON, "lines",
+		CFG_RESPONSE_TYPE_MSGPACK, CFG_RESPONSE_TYPE_NDJSON}) {
+		t.Errorf("unexpected response types %v", types)
+	}
+}
+
+func TestRegisteredResponseType(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("a\nb\nc\n"))
+	}))
+	defer server.Close()
+
+	registered := AvailableExtensions
+	defer func() { AvailableExtensions = registered }()
+	RegisterExtension("lines", &lineCountExt{})
+
+	result := runTestFile(t, `
+tests:
+  - name: Lines
+    route: "@{host}"
+    method: GET
+    response:
+      type: lines
+      payload:
+        lines: 3
+`, server.URL, SuiteOptions{})
+
+	if len(result.Results) != 1 {
+		t.Fatalf("expected 1 result but got %v", len(result.Results))
+	}
+	if r := result.Results[0]; !r.Passed {
+		t.Errorf("expected the response to be parsed by the extension:\n%v", failedFields(r))
+	}
+}
+
+func TestUnknownResponseType(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tests.yaml")
+	def := "tests:\n  - name: Unknown\n    route: \"@{host}\"\n    response:\n      type: lines\n"
+	if err := os.WriteFile(file, []byte(def), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := NewTestSuite(file, "", SuiteOptions{})
+	if err == nil {
+		t.Fatal("expected the unknown response type to be rejected")
+	}
+	if !strings.Contains(err.Error(), "Expected one of: "+strings.Join(KnownResponseTypes(), ", ")) {
+		t.Errorf("expected the error to list the known response types but got: %v", err)
+	}
+}
@@ -0,0 +1,113 @@
+package arp
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	CFG_RESPONSE_FORBIDDEN = "forbidden"
+
+	ForbiddenPath      = "response.Forbidden"
+	ForbiddenMatchFmt  = "Value matches the forbidden pattern '%v'"
+	ForbiddenPassedFmt = "No values match the %v forbidden pattern(s)"
+)
+
+// ForbiddenPattern is a compiled forbidden pattern along with its definition, which is reported instead of the
+// resolved pattern so that sensitive values from variables aren't printed
+type ForbiddenPattern struct {
+	Definition string
+	Pattern    *regexp.Regexp
+}
+
+// quoteVariables replaces the variables of a pattern with their escaped values, so that values such as secrets are
+// matched literally while the rest of the pattern is still a regular expression
+func quoteVariables(pattern string, datastore *DataStore) (string, error) {
+	variables := TokenStack{}
+	variables.Parse(pattern, VAR_PREFIX, VAR_SUFFIX)
+
+	quoted := pattern
+	for _, v := range variables.Frames {
+		// nested variables are resolved along with the variable containing them
+		if v.Nested != 0 {
+			continue
+		}
+		resolved, err := datastore.ExpandVariable(v.Token)
+		if err != nil {
+			return "", err
+		}
+		quoted = strings.ReplaceAll(quoted, v.Token, regexp.QuoteMeta(varToString(resolved, v.Token)))
+	}
+	return quoted, nil
+}
+
+// loadForbiddenPatterns resolves any variables in the forbidden patterns of the test and compiles them
+func (t *TestCase) loadForbiddenPatterns() ([]ForbiddenPattern, error) {
+	var patterns []ForbiddenPattern
+	for _, p := range t.Config.Response.Forbidden {
+		resolved, err := quoteVariables(p, t.GlobalDataStore)
+		if err != nil {
+			return nil, fmt.Errorf(BadVarMatcherFmt, p)
+		}
+		re, err := regexp.Compile(resolved)
+		if err != nil {
+			return nil, fmt.Errorf("invalid '%v' pattern '%v': %v", CFG_RESPONSE_FORBIDDEN, p, err)
+		}
+		patterns = append(patterns, ForbiddenPattern{Definition: p, Pattern: re})
+	}
+	return patterns, nil
+}
+
+// findForbidden walks every value of a response and reports the path of each string matching any of the patterns.
+// Object keys are visited in sorted order so that results are stable.
+func findForbidden(patterns []ForbiddenPattern, node interface{}, path string) []*FieldMatcherResult {
+	var results []*FieldMatcherResult
+	switch n := node.(type) {
+	case map[string]interface{}:
+		var keys []string
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			results = append(results, findForbidden(patterns, n[k], fmt.Sprintf("%v.%v", path, k))...)
+		}
+	case []interface{}:
+		for i, v := range n {
+			results = append(results, findForbidden(patterns, v, fmt.Sprintf("%v[%v]", path, i))...)
+		}
+	case string:
+		for _, p := range patterns {
+			if p.Pattern.MatchString(n) {
+				// the value itself is left out of the report since it is likely sensitive
+				results = append(results, &FieldMatcherResult{
+					ObjectKeyPath: path,
+					Error:         fmt.Sprintf(ForbiddenMatchFmt, p.Definition),
+					Status:        false,
+				})
+				break
+			}
+		}
+	}
+	return results
+}
+
+// validateForbidden fails the response if any string value anywhere in it matches a forbidden pattern
+func (t *TestCase) validateForbidden(response interface{}) []*FieldMatcherResult {
+	patterns, err := t.loadForbiddenPatterns()
+	if err != nil {
+		return []*FieldMatcherResult{validationError(ForbiddenPath, err)}
+	}
+
+	results := findForbidden(patterns, response, "")
+	if len(results) == 0 {
+		results = append(results, &FieldMatcherResult{
+			ObjectKeyPath: ForbiddenPath,
+			Error:         fmt.Sprintf(ForbiddenPassedFmt, len(patterns)),
+			Status:        true,
+		})
+	}
+	return results
+}
@@ -0,0 +1,49 @@
+package arp
+
+import (
+	"testing"
+)
+
+func TestForbiddenVariablesMatchLiterally(t *testing.T) {
+	ds := NewDataStore()
+	ds.Put("password", "p.ss+(word)[1]$")
+	ds.Put("user", map[string]interface{}{"token": "a*b"})
+
+	tests := []struct {
+		name      string
+		forbidden string
+		value     string
+		matches   bool
+	}{
+		{"secret leaked", "@{password}", "your password is p.ss+(word)[1]$!", true},
+		{"secret pattern characters aren't special", "@{password}", "pass word1", false},
+		{"nested path", "@{user.token}", "a*b", true},
+		{"nested path not leaked", "@{user.token}", "aab", false},
+		{"regex around variable", `token=@{user.token}\b`, "token=a*b and more", true},
+		{"plain pattern", `\d{3}-\d{2}-\d{4}`, "123-45-6789", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			test := &TestCase{GlobalDataStore: &ds}
+			test.Config.Response.Forbidden = []string{tt.forbidden}
+			patterns, err := test.loadForbiddenPatterns()
+			if err != nil {
+				t.Fatal(err)
+			}
+			results := findForbidden(patterns, map[string]interface{}{"field": tt.value}, "")
+			if matched := len(results) > 0; matched != tt.matches {
+				t.Errorf("expected match %v for '%v' against '%v'", tt.matches, tt.forbidden, tt.value)
+			}
+		})
+	}
+}
+
+func TestForbiddenUnresolvedVariable(t *testing.T) {
+	ds := NewDataStore()
+	test := &TestCase{GlobalDataStore: &ds}
+	test.Config.Response.Forbidden = []string{"@{missing}"}
+	if _, err := test.loadForbiddenPatterns(); err == nil {
+		t.Error("expected an error for an unresolved variable")
+	}
+}
@@ -0,0 +1,162 @@
+package arp
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	CFG_GLOBAL_ASSERTIONS = "globalAssertions"
+
+	// prefix of the results of global assertions, distinguishing them from the test's own validations
+	GlobalAssertionMarker = "[global] "
+
+	GlobalDurationPath        = "test." + CFG_GLOBAL_ASSERTIONS + ".maxDuration"
+	GlobalDurationExceededFmt = "Duration %v exceeded the global budget of %v"
+	GlobalDurationMetFmt      = "Duration %v is within the global budget of %v"
+)
+
+// GlobalAssertionsCfg defines validations that every response of a run or test file must pass, such as required
+// headers or a time budget. They are validated alongside the validations of each test.
+type GlobalAssertionsCfg struct {
+	Headers     map[interface{}]interface{} `yaml:"headers"`
+	Payload     map[interface{}]interface{} `yaml:"payload"`
+	MaxDuration string                      `yaml:"maxDuration"`
+}
+
+// LoadGlobalAssertionsFile reads a YAML file of global assertions applied to every test of a run
+func LoadGlobalAssertionsFile(path string) (*GlobalAssertionsCfg, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read global assertions file: %v", err)
+	}
+
+	var cfg GlobalAssertionsCfg
+	if err := yaml.Unmarshal(data, &cfg); err != nil {
+		return nil, fmt.Errorf("failed to parse global assertions file: %v - %v", path, err)
+	}
+	if _, err := cfg.GetMaxDuration(); err != nil {
+		return nil, fmt.Errorf("failed to parse global assertions file: %v - %v", path, err)
+	}
+	return &cfg, nil
+}
+
+// MergeGlobalAssertions combines the global assertions of a run with those of a test file. Assertions of the test
+// file take precedence over those of the run for the same header, field or budget.
+func MergeGlobalAssertions(run *GlobalAssertionsCfg, suite *GlobalAssertionsCfg) *GlobalAssertionsCfg {
+	if run == nil {
+		return suite
+	}
+	if suite == nil {
+		return run
+	}
+
+	merged := &GlobalAssertionsCfg{
+		Headers:     mergeHeaders(run.Headers, suite.Headers),
+		Payload:     make(map[interface{}]interface{}),
+		MaxDuration: run.MaxDuration,
+	}
+	for k, v := range run.Payload {
+		merged.Payload[k] = v
+	}
+	for k, v := range suite.Payload {
+		merged.Payload[k] = v
+	}
+	if suite.MaxDuration != "" {
+		merged.MaxDuration = suite.MaxDuration
+	}
+	return merged
+}
+
+// GetMaxDuration parses the time budget of every test. Zero is returned when no budget is defined.
+func (g *GlobalAssertionsCfg) GetMaxDuration() (time.Duration, error) {
+	if g == nil || g.MaxDuration == "" {
+		return 0, nil
+	}
+	duration, err := time.ParseDuration(g.MaxDuration)
+	if err != nil {
+		return 0, fmt.Errorf("invalid '%v' maxDuration: %v", CFG_GLOBAL_ASSERTIONS, err)
+	}
+	return duration, nil
+}
+
+// mergeGlobalMatchers loads global assertion definitions and adds them to a test's matcher. They are marked as
+// global so that they run alongside any of the test's own matchers for the same field rather than replacing them.
+func (t *TestCase) mergeGlobalMatchers(target *ResponseMatcher, fields map[interface{}]interface{}) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, nil
+	}
+
+	global := NewResponseMatcher(t.GlobalDataStore)
+	if err := global.loadObjectFields(fields, fields, FieldMatcherPath{}); err != nil {
+		return nil, fmt.Errorf("invalid '%v': %v", CFG_GLOBAL_ASSERTIONS, err)
+	}
+	for _, c := range global.Config {
+		c.Marker = GlobalAssertionMarker
+		target.Config = append(target.Config, c)
+	}
+	return global.Warnings, nil
+}
+
+// loadGlobalAssertions merges the global header and payload assertions into the matchers of the test, including
+// those of its status specific responses. Assertions, which don't make a request, are left alone. Websocket and RPC
+// responses are shaped differently from those of the HTTP API, so only the time budget applies to them.
+func (t *TestCase) loadGlobalAssertions() ([]string, error) {
+	if t.GlobalAssertions == nil || t.Config.AssertEquals != nil {
+		return nil, nil
+	}
+
+	var err error
+	if t.GlobalMaxDuration, err = t.GlobalAssertions.GetMaxDuration(); err != nil {
+		return nil, err
+	}
+	if t.Config.Websocket || t.IsRPC {
+		return nil, nil
+	}
+
+	targets := [][2]*ResponseMatcher{{&t.ResponseMatcher, &t.ResponseHeaderMatcher}}
+	for _, sr := range t.StatusResponses {
+		targets = append(targets, [2]*ResponseMatcher{&sr.ResponseMatcher, &sr.ResponseHeaderMatcher})
+	}
+
+	var warnings []string
+	for i, target := range targets {
+		payloadWarnings, err := t.mergeGlobalMatchers(target[0], t.GlobalAssertions.Payload)
+		if err != nil {
+			return nil, err
+		}
+		headerWarnings, err := t.mergeGlobalMatchers(target[1], canonicalHeaderKeys(t.GlobalAssertions.Headers))
+		if err != nil {
+			return nil, err
+		}
+		// the same definitions are loaded for every target, so only report their problems once
+		if i == 0 {
+			warnings = append(payloadWarnings, headerWarnings...)
+		}
+	}
+	return warnings, nil
+}
+
+// checkGlobalDuration validates the duration of an executed test against the global time budget, failing the result
+// if it is exceeded.
+func (t *TestCase) checkGlobalDuration(result *TestResult) {
+	if t.GlobalMaxDuration <= 0 {
+		return
+	}
+
+	delta := time.Since(result.StartTime).Round(time.Millisecond)
+	status := delta <= t.GlobalMaxDuration
+	msg := GlobalDurationMetFmt
+	if !status {
+		msg = GlobalDurationExceededFmt
+	}
+	result.Fields = append(result.Fields, &FieldMatcherResult{
+		ObjectKeyPath: GlobalDurationPath,
+		Error:         GlobalAssertionMarker + fmt.Sprintf(msg, delta, t.GlobalMaxDuration),
+		Status:        status,
+	})
+	result.Passed = result.Passed && status
+}
@@ -0,0 +1,52 @@
+package arp
+
+import (
+	"testing"
+	"time"
+)
+
+func TestGlobalAssertionsProtocols(t *testing.T) {
+	global := &GlobalAssertionsCfg{
+		MaxDuration: "2s",
+		Headers:     parseTestYaml(t, "$.X-Request-Id[0]: {type: string, matches: $notEmpty}"),
+		Payload:     parseTestYaml(t, "version: {type: integer}"),
+	}
+
+	tests := []struct {
+		name      string
+		websocket bool
+		rpc       bool
+		merged    bool
+	}{
+		{"rest", false, false, true},
+		{"websocket", true, false, false},
+		{"rpc", false, true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := NewDataStore()
+			test := &TestCase{
+				GlobalDataStore:       &ds,
+				GlobalAssertions:      global,
+				ResponseMatcher:       NewResponseMatcher(&ds),
+				ResponseHeaderMatcher: NewResponseMatcher(&ds),
+				IsRPC:                 tt.rpc,
+			}
+			test.Config.Websocket = tt.websocket
+			if _, err := test.loadGlobalAssertions(); err != nil {
+				t.Fatal(err)
+			}
+
+			if merged := len(test.ResponseMatcher.Config) > 0; merged != tt.merged {
+				t.Errorf("expected payload assertions merged: %v", tt.merged)
+			}
+			if merged := len(test.ResponseHeaderMatcher.Config) > 0; merged != tt.merged {
+				t.Errorf("expected header assertions merged: %v", tt.merged)
+			}
+			if test.GlobalMaxDuration != 2*time.Second {
+				t.Errorf("expected the time budget to apply but got %v", test.GlobalMaxDuration)
+			}
+		})
+	}
+}
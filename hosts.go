@@ -0,0 +1,83 @@
+package arp
+
+import (
+	"strings"
+	"time"
+)
+
+// ParseHosts parses a comma separated list of hosts to execute the same tests against, ignoring empty entries
+func ParseHosts(list string) []string {
+	var hosts []string
+	for _, h := range strings.Split(list, ",") {
+		if h = strings.TrimSpace(h); h != "" {
+			hosts = append(hosts, h)
+		}
+	}
+	return hosts
+}
+
+// RunLabel identifies a run of the tests by the host and dataset row it was executed with. Empty when the tests are
+// only executed once.
+func RunLabel(host string, row int) string {
+	var run []string
+	if host != "" {
+		run = append(run, host)
+	}
+	if row > 0 {
+		run = append(run, RowName(row))
+	}
+	return strings.Join(run, ", ")
+}
+
+// RunSummary aggregates the results of every test file executed in one run of the tests, such as the run against a
+// single host
+type RunSummary struct {
+	Name      string
+	Passed    bool
+	Total     int
+	Succeeded int
+	Failed    int
+	Duration  time.Duration
+}
+
+// SummarizeRuns aggregates suite results by the run they belong to, in the order the runs are named
+func SummarizeRuns(names []string, results []MultiSuiteResult, runName func(MultiSuiteResult) string) []RunSummary {
+	summaries := make([]RunSummary, len(names))
+	index := make(map[string]int)
+	for i, n := range names {
+		summaries[i] = RunSummary{Name: n, Passed: true}
+		index[n] = i
+	}
+
+	for _, r := range results {
+		i, ok := index[runName(r)]
+		if !ok {
+			continue
+		}
+		s := &summaries[i]
+		s.Passed = s.Passed && r.Passed
+		s.Total += r.TestResults.Total
+		s.Succeeded += r.TestResults.Passed
+		s.Failed += r.TestResults.Failed
+		s.Duration += r.TestResults.Duration
+	}
+	return summaries
+}
+
+// printRunSummary prints whether the tests passed in each run, optionally followed by a description of the run
+func printRunSummary(opts ReportOptions, title string, summaries []RunSummary, describe func(i int) string) {
+	PrintIndentedLn(0, "\n%v\n", opts.Colors.BrightWhite(title))
+	for i, s := range summaries {
+		PrintIndentedLn(1, "[%v] %v\n", getSuccessString(opts.Colors, s.Passed, ""), opts.Colors.BrightWhite(s.Name))
+		if describe != nil {
+			PrintIndentedLn(2, "%v\n", describe(i))
+		}
+		PrintIndentedLn(2, "Passed: %v, Failed: %v, Total: %v, Duration: %v\n", s.Succeeded, s.Failed, s.Total, s.Duration)
+	}
+}
+
+// PrintHostSummary prints whether the tests passed against each host they were executed against
+func PrintHostSummary(opts ReportOptions, results []MultiSuiteResult) {
+	summaries := SummarizeRuns(opts.Hosts, results, func(r MultiSuiteResult) string { return r.Host })
+	printRunSummary(opts, "Hosts", summaries, nil)
+}
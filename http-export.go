@@ -0,0 +1,107 @@
+package arp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	HTTP_FILE_EXT       = ".http"
+	HTTP_FILE_SEPARATOR = "###"
+)
+
+// ExportHttpFiles writes the requests made by each test file into a '.http' file within the output directory that
+// editors such as VS Code's REST Client can replay. Each request is written with its resolved route, headers and
+// body, followed by the response as a comment. Websocket and RPC tests are skipped as they can't be represented.
+// Test files executed against multiple hosts or with a dataset are written once per host and row.
+func ExportHttpFiles(outDir string, results []MultiSuiteResult, redactor Redactor) error {
+	if err := os.MkdirAll(outDir, 0755); err != nil {
+		return fmt.Errorf("failed to create http export directory: %v", err)
+	}
+
+	for _, suite := range results {
+		var out bytes.Buffer
+		for _, result := range suite.TestResults.Results {
+			writeHttpRequest(&out, result, &redactor)
+		}
+		if out.Len() == 0 {
+			continue
+		}
+
+		name := suite.TestFile
+		if suite.Row > 0 {
+			name = fmt.Sprintf("row%v/%v", suite.Row, name)
+		}
+		if suite.Host != "" {
+			name = suite.Host + "/" + name
+		}
+		path := filepath.Join(outDir, httpFileName(name))
+		if err := os.WriteFile(path, out.Bytes(), 0644); err != nil {
+			return fmt.Errorf("failed to write http file: %v - %v", path, err)
+		}
+	}
+	return nil
+}
+
+// httpFileName flattens a test file path into a file name so that test files with the same name in different
+// directories don't overwrite each other.
+func httpFileName(testFile string) string {
+	// remote test files are named after their host and path
+	if IsRemoteFile(testFile) {
+		testFile = strings.ReplaceAll(testFile[strings.Index(testFile, "://")+len("://"):], ":", "_")
+	}
+	name := strings.TrimSuffix(filepath.ToSlash(filepath.Clean(testFile)), filepath.Ext(testFile))
+	// cleaned paths only have parent directory elements at their start, which are dropped along with the root
+	for strings.HasPrefix(name, "../") {
+		name = strings.TrimPrefix(name, "../")
+	}
+	name = strings.TrimPrefix(name, "/")
+	return strings.ReplaceAll(name, "/", "_") + HTTP_FILE_EXT
+}
+
+func writeHttpRequest(out *bytes.Buffer, result *TestResult, redactor *Redactor) {
+	test := result.TestCase
+	if test.Config.Websocket || test.IsRPC || result.ResolvedRoute == "" {
+		return
+	}
+
+	fmt.Fprintf(out, "%v %v\n", HTTP_FILE_SEPARATOR, test.Config.Name)
+	fmt.Fprintf(out, "%v %v\n", strings.ToUpper(test.Config.Method), result.ResolvedRoute)
+
+	var keys []string
+	for k := range result.RequestHeaders {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		for _, v := range result.RequestHeaders[k] {
+			if redactor.IsSensitive(k) {
+				v = REDACTED_VALUE
+			}
+			fmt.Fprintf(out, "%v: %v\n", k, v)
+		}
+	}
+
+	if strings.ToLower(test.Config.Method) != "get" && result.ResolvedInput != nil {
+		if test.Config.FormInput {
+			fmt.Fprintf(out, "\n# multipart form input can't be exported\n")
+		} else {
+			body, _ := json.MarshalIndent(redactor.Redact(YamlToJson(result.ResolvedInput)), "", IndentStr(1))
+			fmt.Fprintf(out, "\n%v\n", string(body))
+		}
+	}
+
+	fmt.Fprintf(out, "\n# Response: %v\n", result.StatusCode)
+	if result.Response != nil {
+		response, _ := json.MarshalIndent(redactor.Redact(result.Response), "", IndentStr(1))
+		for _, line := range strings.Split(string(response), "\n") {
+			fmt.Fprintf(out, "# %v\n", line)
+		}
+	}
+	out.WriteString("\n")
+}
@@ -0,0 +1,29 @@
+package arp
+
+import (
+	"testing"
+)
+
+func TestHttpFileName(t *testing.T) {
+	tests := []struct {
+		testFile string
+		expected string
+	}{
+		{"users.yaml", "users.http"},
+		{"./tests/users.yaml", "tests_users.http"},
+		{"tests/users/create.yaml", "tests_users_create.http"},
+		{"../../tests/users.yaml", "tests_users.http"},
+		{"/home/arp/tests/users.yaml", "home_arp_tests_users.http"},
+		{".hidden/users.yaml", ".hidden_users.http"},
+		{"tests/.users.yaml", "tests_.users.http"},
+		{"tests/..users.yaml", "tests_..users.http"},
+		{"tests/../users.yaml", "users.http"},
+		{"https://example.com:8443/tests/users.yaml", "example.com_8443_tests_users.http"},
+	}
+
+	for _, tt := range tests {
+		if name := httpFileName(tt.testFile); name != tt.expected {
+			t.Errorf("expected '%v' to be named '%v' but got '%v'", tt.testFile, tt.expected, name)
+		}
+	}
+}
@@ -1,16 +1,1005 @@
 package arp
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
+	"strconv"
+	"strings"
 )
 
+const (
+	// key used to wrap individual array elements when counting matches
+	ARRAY_COUNT_ITEM_KEY = "item"
+
+	MatchCountErrFmt = "Expected %v elements matching item %v but found %v instead."
+
+	// sequence definition keys
+	TEST_KEY_SEQ_FIELD      = "field"
+	TEST_KEY_SEQ_STEP       = "step"
+	TEST_KEY_SEQ_INCREASING = "strictlyIncreasing"
+
+	SequenceStepErrFmt       = "Expected a step of %v between index %v (%v) and index %v (%v)"
+	SequenceIncreasingErrFmt = "Expected strictly increasing values between index %v (%v) and index %v (%v)"
+	SequenceTypeErrFmt       = "Expected a numeric sequence value at index %v but found '%v' of type '%v'"
+
+	// orderedBy definition keys and directions
+	TEST_KEY_ORDERED_BY      = "orderedBy"
+	TEST_KEY_ORDER_FIELD     = "field"
+	TEST_KEY_ORDER_DIRECTION = "direction"
+	ORDER_ASC                = "asc"
+	ORDER_DESC               = "desc"
+
+	OrderedByErrFmt          = "Expected elements ordered by '%v' %v but index %v (%v) comes before index %v (%v)"
+	OrderedByTypeErrFmt      = "Expected a string or numeric '%v' at index %v but found '%v' of type '%v'"
+	OrderedByDirectionErrFmt = "Unknown direction '%v' for '%v'. Expected '%v' or '%v'"
+
+	TEST_KEY_UNIQUE_BY = "uniqueBy"
+
+	UniqueByErrFmt        = "Expected unique '%v' values but found duplicates: %v"
+	UniqueByDuplicateFmt  = "'%v' at indices %v"
+	UniqueByMissingErrFmt = "Expected the field '%v' at index %v but found '%v'"
+
+	// find definition keys
+	TEST_KEY_FIND_FIELD  = "field"
+	TEST_KEY_FIND_EQUALS = "equals"
+
+	FindNotFoundErrFmt = "No element found where '%v' equals '%v'"
+
+	TEST_KEY_CONTAINS = "contains"
+	ContainsErrFmt    = "Expected array to contain %v but found %v instead"
+
+	// aggregate definition keys and operations
+	TEST_KEY_AGG_OP    = "op"
+	TEST_KEY_AGG_FIELD = "field"
+	AGG_SUM            = "sum"
+	AGG_AVG            = "avg"
+	AGG_MIN            = "min"
+	AGG_MAX            = "max"
+
+	AggregateErrFmt         = "%v = %v did not match the expected value: %v"
+	AggregateTypeErrFmt     = "Expected a numeric value for '%v' at index %v but found '%v' of type '%v'"
+	AggregateEmptyErrFmt    = "Cannot compute %v of an empty array"
+	AggregateExpectedErrFmt = "Expected value '%v' for '%v' is not a number or numeric expression"
+
+	// type name of null elements when checking arrays are homogeneous
+	TYPE_NULL = "null"
+
+	HomogeneousErrFmt = "Expected every element to be of type '%v' but index %v is of type '%v'"
+
+	// elementRange definition keys
+	TEST_KEY_ELEMENT_RANGE = "elementRange"
+	TEST_KEY_RANGE_MIN     = "min"
+	TEST_KEY_RANGE_MAX     = "max"
+	TEST_KEY_RANGE_FIELD   = "field"
+
+	ElementRangeErrFmt      = "Expected every element within %v but index %v is %v"
+	ElementRangeTypeErrFmt  = "Expected a numeric element at index %v but found '%v' of type '%v'"
+	ElementRangeBoundErrFmt = "'%v' of '%v' is not a number: %v"
+
+	// slice definition keys
+	TEST_KEY_SLICE_OF     = "of"
+	TEST_KEY_SLICE_OFFSET = "offset"
+	TEST_KEY_SLICE_LENGTH = "length"
+
+	SliceLengthErrFmt   = "Expected %v elements from %v but found %v instead"
+	SliceDiffErrFmt     = "%v of %v elements differ from %v"
+	SliceTypeErrFmt     = "'%v' does not resolve to an array: %v"
+	SliceBoundErrFmt    = "'%v' of '%v' is not an integer or integer expression: %v"
+	SliceNegativeErrFmt = "'%v' of '%v' must not be negative: %v"
+	SliceClampedFmt     = " (clamped from [%v:%v] of %v elements)"
+)
+
+// ArraySequence validates that a numeric field increments across the elements of an array
+type ArraySequence struct {
+	// path of the field within each element. If empty, the elements themselves are used.
+	Field              string
+	Step               float64
+	StrictlyIncreasing bool
+}
+
+func (s *ArraySequence) Parse(parentNode interface{}, node interface{}) error {
+	s.Step = 1
+
+	switch v := node.(type) {
+	case string:
+		s.Field = v
+		return nil
+	case map[interface{}]interface{}:
+	default:
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_SEQUENCE, TYPE_ARRAY), parentNode))
+	}
+
+	seqNode := node.(map[interface{}]interface{})
+	if field, ok := seqNode[TEST_KEY_SEQ_FIELD]; ok {
+		s.Field = fmt.Sprintf("%v", field)
+	}
+
+	if step, ok := seqNode[TEST_KEY_SEQ_STEP]; ok {
+		switch v := step.(type) {
+		case int:
+			s.Step = float64(v)
+		case float64:
+			s.Step = v
+		default:
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_SEQ_STEP, TYPE_ARRAY), parentNode))
+		}
+	}
+
+	if increasing, ok := seqNode[TEST_KEY_SEQ_INCREASING]; ok {
+		if s.StrictlyIncreasing, ok = increasing.(bool); !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_SEQ_INCREASING, TYPE_ARRAY), parentNode))
+		}
+	}
+	return nil
+}
+
+// Validate checks each pair of neighbouring elements and describes the first pair that breaks the sequence
+func (s *ArraySequence) Validate(elements []interface{}) (bool, string) {
+	var prev float64
+	for i, e := range elements {
+		value := e
+		if s.Field != "" {
+			obj, ok := e.(map[string]interface{})
+			if !ok {
+				return false, fmt.Sprintf(SequenceTypeErrFmt, i, e, reflect.TypeOf(e))
+			}
+			value, _ = GetJsonValue(obj, s.Field)
+		}
+
+		var cur float64
+		switch v := value.(type) {
+		case float64:
+			cur = v
+		case int:
+			cur = float64(v)
+		case int64:
+			cur = float64(v)
+		default:
+			return false, fmt.Sprintf(SequenceTypeErrFmt, i, value, reflect.TypeOf(value))
+		}
+
+		if i > 0 {
+			if s.StrictlyIncreasing && cur <= prev {
+				return false, fmt.Sprintf(SequenceIncreasingErrFmt, i-1, prev, i, cur)
+			} else if !s.StrictlyIncreasing && !s.isStep(prev, cur) {
+				return false, fmt.Sprintf(SequenceStepErrFmt, s.Step, i-1, prev, i, cur)
+			}
+		}
+		prev = cur
+	}
+	return true, ""
+}
+
+// isStep returns whether cur follows prev by the step. Differences are compared with a small tolerance, relative to
+// the values, as fractional steps such as 0.1 can't be represented exactly.
+func (s *ArraySequence) isStep(prev float64, cur float64) bool {
+	tolerance := 1e-9 * math.Max(1, math.Max(math.Abs(prev), math.Abs(cur)))
+	return math.Abs(cur-prev-s.Step) <= tolerance
+}
+
+// ArrayOrder validates that the elements of an array are sorted by a field. The field and direction can be data store
+// variables so that the order is driven by the sort parameter of the request, e.g. '@{REQUEST_QUERY.sort}'. Fields
+// prefixed with '-' or suffixed with ':desc' are sorted in descending order, as is common for sort parameters.
+type ArrayOrder struct {
+	Field     string
+	Direction string
+}
+
+func (o *ArrayOrder) Parse(parentNode interface{}, node interface{}) error {
+	switch v := node.(type) {
+	case string:
+		o.Field = v
+		return nil
+	case map[interface{}]interface{}:
+		field, ok := v[TEST_KEY_ORDER_FIELD]
+		if !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_ORDER_FIELD, TYPE_ARRAY), parentNode))
+		}
+		o.Field = fmt.Sprintf("%v", field)
+		if direction, ok := v[TEST_KEY_ORDER_DIRECTION]; ok {
+			o.Direction = fmt.Sprintf("%v", direction)
+		}
+		return nil
+	}
+	return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_ORDERED_BY, TYPE_ARRAY), parentNode))
+}
+
+// Resolve expands the variables of the field and direction, returning the field and whether it is sorted in
+// descending order. An explicit direction takes precedence over one given with the field.
+func (o *ArrayOrder) Resolve(datastore *DataStore) (string, bool, error) {
+	resolved, err := datastore.ExpandVariable(o.Field)
+	if err != nil {
+		return "", false, fmt.Errorf(BadVarMatcherFmt, o.Field)
+	}
+	field := strings.TrimSpace(varToString(resolved, o.Field))
+
+	direction := ORDER_ASC
+	if strings.HasPrefix(field, "-") {
+		field, direction = field[1:], ORDER_DESC
+	} else if strings.HasPrefix(field, "+") {
+		field = field[1:]
+	} else if i := strings.LastIndex(field, ":"); i >= 0 {
+		field, direction = field[:i], field[i+1:]
+	}
+
+	if o.Direction != "" {
+		resolved, err := datastore.ExpandVariable(o.Direction)
+		if err != nil {
+			return "", false, fmt.Errorf(BadVarMatcherFmt, o.Direction)
+		}
+		direction = varToString(resolved, o.Direction)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(direction)) {
+	case ORDER_ASC, "ascending":
+		return field, false, nil
+	case ORDER_DESC, "descending":
+		return field, true, nil
+	}
+	return "", false, fmt.Errorf(OrderedByDirectionErrFmt, direction, TEST_KEY_ORDERED_BY, ORDER_ASC, ORDER_DESC)
+}
+
+// Validate checks each pair of neighbouring elements and describes the first pair that is out of order. Elements with
+// equal values are in order in both directions.
+func (o *ArrayOrder) Validate(elements []interface{}, datastore *DataStore) (bool, string, error) {
+	field, descending, err := o.Resolve(datastore)
+	if err != nil {
+		return false, "", err
+	}
+	direction := ORDER_ASC
+	if descending {
+		direction = ORDER_DESC
+	}
+
+	var prev interface{}
+	for i, e := range elements {
+		value := e
+		if field != "" {
+			obj, ok := e.(map[string]interface{})
+			if !ok {
+				return false, fmt.Sprintf(OrderedByTypeErrFmt, field, i, e, reflect.TypeOf(e)), nil
+			}
+			value, _ = GetJsonValue(obj, field)
+		}
+
+		switch value.(type) {
+		case string, float64, int, int64:
+		default:
+			return false, fmt.Sprintf(OrderedByTypeErrFmt, field, i, value, reflect.TypeOf(value)), nil
+		}
+
+		if i > 0 {
+			cmp, ok := compareOrderValues(prev, value)
+			if !ok {
+				return false, fmt.Sprintf(OrderedByTypeErrFmt, field, i, value, reflect.TypeOf(value)), nil
+			}
+			if (cmp > 0 && !descending) || (cmp < 0 && descending) {
+				return false, fmt.Sprintf(OrderedByErrFmt, field, direction, i-1, prev, i, value), nil
+			}
+		}
+		prev = value
+	}
+	return true, fmt.Sprintf("%v %v", field, direction), nil
+}
+
+// compareOrderValues compares two strings or two numbers, returning false when they can't be compared
+func compareOrderValues(a interface{}, b interface{}) (int, bool) {
+	if aStr, ok := a.(string); ok {
+		bStr, ok := b.(string)
+		if !ok {
+			return 0, false
+		}
+		return strings.Compare(aStr, bStr), true
+	}
+
+	aNum, aOk := orderNumber(a)
+	bNum, bOk := orderNumber(b)
+	if !aOk || !bOk {
+		return 0, false
+	}
+	switch {
+	case aNum < bNum:
+		return -1, true
+	case aNum > bNum:
+		return 1, true
+	}
+	return 0, true
+}
+
+func orderNumber(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int:
+		return float64(n), true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// ArrayUniqueness validates that a field of the elements of an array is unique, such as the email of each user in a
+// list, without requiring the elements to differ as a whole
+type ArrayUniqueness struct {
+	Field string
+}
+
+func (u *ArrayUniqueness) Parse(parentNode interface{}, node interface{}) error {
+	if field, ok := node.(string); ok {
+		u.Field = strings.TrimPrefix(field, FIELD_KEY_PREFIX)
+	}
+	if u.Field != "" {
+		return nil
+	}
+	return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_UNIQUE_BY, TYPE_ARRAY), parentNode))
+}
+
+// Validate reports every duplicated value along with the indices of the elements sharing it. Values are compared by
+// their JSON representation so that e.g. 1 and "1" are distinct. Elements without the field fail the validation.
+func (u *ArrayUniqueness) Validate(elements []interface{}, datastore *DataStore) (bool, string, error) {
+	resolved, err := datastore.ExpandVariable(u.Field)
+	if err != nil {
+		return false, "", fmt.Errorf(BadVarMatcherFmt, u.Field)
+	}
+	field := varToString(resolved, u.Field)
+
+	indices := make(map[string][]int)
+	var values []string
+	for i, e := range elements {
+		obj, ok := e.(map[string]interface{})
+		if !ok {
+			return false, fmt.Sprintf(UniqueByMissingErrFmt, field, i, varToString(e)), nil
+		}
+		value, err := GetJsonValue(obj, field)
+		if err != nil {
+			return false, fmt.Sprintf(UniqueByMissingErrFmt, field, i, varToString(e)), nil
+		}
+
+		b, _ := json.Marshal(value)
+		key := string(b)
+		if _, ok := indices[key]; !ok {
+			values = append(values, key)
+		}
+		indices[key] = append(indices[key], i)
+	}
+
+	var duplicates []string
+	for _, v := range values {
+		if len(indices[v]) > 1 {
+			duplicates = append(duplicates, fmt.Sprintf(UniqueByDuplicateFmt, strings.Trim(v, `"`), indices[v]))
+		}
+	}
+	if len(duplicates) > 0 {
+		return false, fmt.Sprintf(UniqueByErrFmt, field, strings.Join(duplicates, ", ")), nil
+	}
+	return true, fmt.Sprintf("%v unique '%v' values", len(values), field), nil
+}
+
+// ArrayHomogeneity validates that every element of an array has the same JSON type. If a type is given, the
+// elements must all be of that type rather than the type of the first element.
+type ArrayHomogeneity struct {
+	Type string
+}
+
+func (h *ArrayHomogeneity) Parse(parentNode interface{}, node interface{}) error {
+	switch v := node.(type) {
+	case bool:
+		return nil
+	case nil:
+		// an unquoted 'null' is parsed as a null value rather than the name of the type
+		h.Type = TYPE_NULL
+		return nil
+	case string:
+		switch v {
+		case TYPE_INT, TYPE_NUM, TYPE_STR, TYPE_BOOL, TYPE_OBJ, TYPE_ARRAY, TYPE_NULL:
+			h.Type = v
+			return nil
+		}
+	}
+	return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_HOMOGENEOUS, TYPE_ARRAY), parentNode))
+}
+
+// jsonTypeName returns the matcher type name of a value decoded from JSON. Numbers are reported as integers when
+// the expected type is an integer and the value has no fractional part.
+func jsonTypeName(value interface{}, expected string) string {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return TYPE_OBJ
+	case []interface{}:
+		return TYPE_ARRAY
+	case string:
+		return TYPE_STR
+	case bool:
+		return TYPE_BOOL
+	case float64:
+		if expected == TYPE_INT && v == math.Trunc(v) {
+			return TYPE_INT
+		}
+		return TYPE_NUM
+	case int, int64:
+		return TYPE_INT
+	}
+	return TYPE_NULL
+}
+
+// Validate returns the type shared by the elements, or a description of the first element with a different type
+func (h *ArrayHomogeneity) Validate(elements []interface{}) (bool, string) {
+	expected := h.Type
+	for i, e := range elements {
+		actual := jsonTypeName(e, expected)
+		if expected == "" {
+			expected = actual
+		} else if actual != expected {
+			return false, fmt.Sprintf(HomogeneousErrFmt, expected, i, actual)
+		}
+	}
+	return true, expected
+}
+
+// ArrayElementRange validates that every element of a numeric array, such as a series of sensor readings, is within
+// bounds without a matcher per index. The range is either inclusive 'min' and 'max' bounds or numeric expressions
+// (e.g. '$>= 0, $< 100'). Bounds and expressions can be data store variables.
+type ArrayElementRange struct {
+	// path of the field within each element. If empty, the elements themselves are used.
+	Field string
+	Min   interface{}
+	Max   interface{}
+	Expr  string
+}
+
+func (r *ArrayElementRange) Parse(parentNode interface{}, node interface{}) error {
+	switch v := node.(type) {
+	case string:
+		r.Expr = v
+		return nil
+	case map[interface{}]interface{}:
+		r.Min, r.Max = v[TEST_KEY_RANGE_MIN], v[TEST_KEY_RANGE_MAX]
+		if r.Min == nil && r.Max == nil {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_ELEMENT_RANGE, TYPE_ARRAY), parentNode))
+		}
+		for _, bound := range []interface{}{r.Min, r.Max} {
+			switch bound.(type) {
+			case nil, int, float64, string:
+			default:
+				return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_ELEMENT_RANGE, TYPE_ARRAY), parentNode))
+			}
+		}
+		if field, ok := v[TEST_KEY_RANGE_FIELD]; ok {
+			r.Field = fmt.Sprintf("%v", field)
+		}
+		return nil
+	}
+	return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_ELEMENT_RANGE, TYPE_ARRAY), parentNode))
+}
+
+// resolveBound resolves a 'min' or 'max' bound to a number. Nil is returned if it isn't defined.
+func (r *ArrayElementRange) resolveBound(key string, bound interface{}, datastore *DataStore) (*float64, error) {
+	var value float64
+	switch v := bound.(type) {
+	case nil:
+		return nil, nil
+	case int:
+		value = float64(v)
+	case float64:
+		value = v
+	case string:
+		resolved, err := datastore.ExpandVariable(v)
+		if err != nil {
+			return nil, fmt.Errorf(BadVarMatcherFmt, v)
+		}
+		resolvedStr := varToString(resolved, v)
+		if value, err = strconv.ParseFloat(strings.TrimSpace(resolvedStr), 64); err != nil {
+			return nil, fmt.Errorf(ElementRangeBoundErrFmt, key, TEST_KEY_ELEMENT_RANGE, resolvedStr)
+		}
+	}
+	return &value, nil
+}
+
+// Validate checks every element against the range and describes the first one outside of it
+func (r *ArrayElementRange) Validate(elements []interface{}, datastore *DataStore) (bool, string, error) {
+	var min, max *float64
+	var expr, description string
+	if r.Expr != "" {
+		resolved, err := datastore.ExpandVariable(r.Expr)
+		if err != nil {
+			return false, "", fmt.Errorf(BadVarMatcherFmt, r.Expr)
+		}
+		expr = varToString(resolved, r.Expr)
+		description = fmt.Sprintf("'%v'", expr)
+	} else {
+		var err error
+		if min, err = r.resolveBound(TEST_KEY_RANGE_MIN, r.Min, datastore); err != nil {
+			return false, "", err
+		}
+		if max, err = r.resolveBound(TEST_KEY_RANGE_MAX, r.Max, datastore); err != nil {
+			return false, "", err
+		}
+		lower, upper := "-inf", "inf"
+		if min != nil {
+			lower = fmt.Sprintf("%v", *min)
+		}
+		if max != nil {
+			upper = fmt.Sprintf("%v", *max)
+		}
+		description = fmt.Sprintf("[%v, %v]", lower, upper)
+	}
+
+	for i, e := range elements {
+		value := e
+		if r.Field != "" {
+			obj, ok := e.(map[string]interface{})
+			if !ok {
+				return false, fmt.Sprintf(ElementRangeTypeErrFmt, i, e, reflect.TypeOf(e)), nil
+			}
+			value, _ = GetJsonValue(obj, r.Field)
+		}
+
+		num, ok := orderNumber(value)
+		if !ok {
+			return false, fmt.Sprintf(ElementRangeTypeErrFmt, i, value, reflect.TypeOf(value)), nil
+		}
+
+		inRange := (min == nil || num >= *min) && (max == nil || num <= *max)
+		if expr != "" {
+			var err error
+			var evaluated bool
+			inRange, evaluated, _, err = evaluateFloatExpr(expr, num)
+			if err == nil && !evaluated {
+				err = fmt.Errorf("invalid numeric expression '%v'", expr)
+			}
+			if err != nil {
+				return false, "", fmt.Errorf("invalid '%v' expression: %v", TEST_KEY_ELEMENT_RANGE, err)
+			}
+		}
+		if !inRange {
+			return false, fmt.Sprintf(ElementRangeErrFmt, description, i, num), nil
+		}
+	}
+	return true, fmt.Sprintf("%v elements within %v", len(elements), description), nil
+}
+
+// ArrayFinder locates the first element of an array where a field equals an expected value and validates the
+// properties of that element.
+type ArrayFinder struct {
+	Field      string
+	Equals     interface{}
+	Properties map[interface{}]interface{}
+	Matcher    ResponseMatcher
+	Results    []*FieldMatcherResult
+}
+
+func (f *ArrayFinder) Parse(parentNode interface{}, node interface{}) error {
+	findNode, ok := node.(map[interface{}]interface{})
+	if !ok {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_FIND, TYPE_ARRAY), parentNode))
+	}
+
+	field, fOk := findNode[TEST_KEY_FIND_FIELD]
+	equals, eOk := findNode[TEST_KEY_FIND_EQUALS]
+	if !fOk || !eOk {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_FIND, TYPE_ARRAY), parentNode))
+	}
+	f.Field = fmt.Sprintf("%v", field)
+	f.Equals = equals
+
+	if v, ok := findNode[TEST_KEY_PROPERTIES]; ok {
+		if f.Properties, ok = v.(map[interface{}]interface{}); !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_PROPERTIES, TEST_KEY_FIND), parentNode))
+		}
+	}
+	return nil
+}
+
+// Find returns the index of the first element matching the predicate or -1 if there is none. Values are compared
+// by their string representation so that variables can be used regardless of the field's type.
+func (f *ArrayFinder) Find(elements []interface{}, datastore *DataStore) (int, string, error) {
+	expected := f.Equals
+	if s, ok := f.Equals.(string); ok {
+		resolved, err := datastore.ExpandVariable(s)
+		if err != nil {
+			return -1, "", fmt.Errorf(BadVarMatcherFmt, s)
+		}
+		expected = resolved
+	}
+	expectedStr := varToString(expected)
+
+	for i, e := range elements {
+		obj, ok := e.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		if value, err := GetJsonValue(obj, f.Field); err == nil && value != nil && varToString(value) == expectedStr {
+			return i, expectedStr, nil
+		}
+	}
+	return -1, expectedStr, nil
+}
+
+// Validate matches the properties of the found element. Values stored by the properties are put directly into
+// the data store.
+func (f *ArrayFinder) Validate(element interface{}, datastore *DataStore) (bool, error) {
+	f.Matcher.DS = datastore
+	f.Matcher.NodeCache = NodeCache{Cache: make(map[string]NodeCacheObj)}
+
+	passed, results, err := f.Matcher.Match(element)
+	f.Results = results
+	return passed, err
+}
+
+// ArraySlice validates that an array equals a slice of another array stored in the data store, such as a page of a
+// previously captured full list. The offset and length can be integers, variables or simple integer expressions of
+// them (e.g. '@{page} * @{pageSize}'). A missing length takes the rest of the stored array.
+type ArraySlice struct {
+	Of      string
+	Offset  interface{}
+	Length  interface{}
+	Results []*FieldMatcherResult
+}
+
+func (s *ArraySlice) Parse(parentNode interface{}, node interface{}) error {
+	sliceNode, ok := node.(map[interface{}]interface{})
+	if !ok {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_SLICE, TYPE_ARRAY), parentNode))
+	}
+
+	of, ok := sliceNode[TEST_KEY_SLICE_OF].(string)
+	if !ok || of == "" {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_SLICE_OF, TEST_KEY_SLICE), parentNode))
+	}
+	s.Of = of
+
+	for _, key := range []string{TEST_KEY_SLICE_OFFSET, TEST_KEY_SLICE_LENGTH} {
+		v, ok := sliceNode[key]
+		if !ok {
+			continue
+		}
+		switch bound := v.(type) {
+		case int:
+			// variable bounds are checked once they're resolved
+			if bound < 0 {
+				return errors.New(ObjectPrintf(fmt.Sprintf(SliceNegativeErrFmt, key, TEST_KEY_SLICE, bound), parentNode))
+			}
+		case string:
+		default:
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, key, TEST_KEY_SLICE), parentNode))
+		}
+		if key == TEST_KEY_SLICE_OFFSET {
+			s.Offset = v
+		} else {
+			s.Length = v
+		}
+	}
+	return nil
+}
+
+// resolveBound resolves the offset or length of the slice to an integer. Nil is returned if it isn't defined.
+func (s *ArraySlice) resolveBound(key string, bound interface{}, datastore *DataStore) (*int, error) {
+	if bound == nil {
+		return nil, nil
+	}
+	if i, ok := bound.(int); ok {
+		return &i, nil
+	}
+
+	str := bound.(string)
+	resolved, err := datastore.ExpandVariable(str)
+	if err != nil {
+		return nil, fmt.Errorf(BadVarMatcherFmt, str)
+	}
+	value, err := evaluateIntExpr(varToString(resolved, str))
+	if err != nil {
+		return nil, fmt.Errorf(SliceBoundErrFmt, key, TEST_KEY_SLICE, str)
+	}
+	if value < 0 {
+		return nil, fmt.Errorf(SliceNegativeErrFmt, key, TEST_KEY_SLICE, value)
+	}
+	return &value, nil
+}
+
+// Expected resolves the stored array and returns the expected slice of it, along with a description of the slice
+// for reports. Bounds past the end of the stored array are clamped to it rather than failing, which results in a
+// shorter or empty expected slice.
+func (s *ArraySlice) Expected(datastore *DataStore) ([]interface{}, string, error) {
+	resolved, err := datastore.ExpandVariable(s.Of)
+	if err != nil {
+		return nil, "", fmt.Errorf(BadVarMatcherFmt, s.Of)
+	}
+	normalized, err := normalizeJson(resolved)
+	if err != nil {
+		return nil, "", err
+	}
+	full, ok := normalized.([]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf(SliceTypeErrFmt, s.Of, varToString(resolved))
+	}
+
+	offset, err := s.resolveBound(TEST_KEY_SLICE_OFFSET, s.Offset, datastore)
+	if err != nil {
+		return nil, "", err
+	}
+	length, err := s.resolveBound(TEST_KEY_SLICE_LENGTH, s.Length, datastore)
+	if err != nil {
+		return nil, "", err
+	}
+
+	start := 0
+	if offset != nil {
+		start = *offset
+	}
+	end := len(full)
+	if length != nil {
+		end = start + *length
+	}
+
+	clamped := ""
+	if start > len(full) || end > len(full) {
+		clamped = fmt.Sprintf(SliceClampedFmt, start, end, len(full))
+		start = int(math.Min(float64(start), float64(len(full))))
+		end = int(math.Min(float64(end), float64(len(full))))
+	}
+	return full[start:end], fmt.Sprintf("%v[%v:%v]%v", s.Of, start, end, clamped), nil
+}
+
+// Validate compares the array against the expected slice element by element. The difference of each mismatched
+// element is kept as a result at its index.
+func (s *ArraySlice) Validate(elements []interface{}, datastore *DataStore) (bool, string, error) {
+	s.Results = nil
+	expected, desc, err := s.Expected(datastore)
+	if err != nil {
+		return false, "", err
+	}
+	actual, err := normalizeJson(elements)
+	if err != nil {
+		return false, "", err
+	}
+	actualElements, _ := actual.([]interface{})
+
+	diffs := 0
+	for i := 0; i < len(actualElements) && i < len(expected); i++ {
+		if msg, equal := assertDiff(actualElements[i], expected[i], ""); !equal {
+			diffs++
+			s.Results = append(s.Results, &FieldMatcherResult{
+				ObjectKeyPath: fmt.Sprintf("[%v]", i),
+				Error:         msg,
+				Status:        false,
+			})
+		}
+	}
+
+	if len(elements) != len(expected) {
+		return false, fmt.Sprintf(SliceLengthErrFmt, len(expected), desc, len(elements)), nil
+	}
+	if diffs > 0 {
+		return false, fmt.Sprintf(SliceDiffErrFmt, diffs, len(expected), desc), nil
+	}
+	return true, desc, nil
+}
+
+// evaluateIntExpr evaluates a sum of products of integers such as '2 * 25' or '3 * 10 - 5', which is enough to
+// compute slice bounds from variables
+func evaluateIntExpr(expr string) (int, error) {
+	expr = strings.ReplaceAll(expr, " ", "")
+	if expr == "" {
+		return 0, fmt.Errorf("empty expression")
+	}
+
+	total := 0
+	sign := 1
+	start := 0
+	for i := 0; i <= len(expr); i++ {
+		// a leading sign belongs to the first term rather than separating terms
+		if i < len(expr) && ((expr[i] != '+' && expr[i] != '-') || i == start) {
+			continue
+		}
+
+		product := 1
+		for _, factor := range strings.Split(expr[start:i], "*") {
+			value, err := strconv.Atoi(factor)
+			if err != nil {
+				return 0, err
+			}
+			product *= value
+		}
+		total += sign * product
+
+		if i < len(expr) && expr[i] == '-' {
+			sign = -1
+		} else {
+			sign = 1
+		}
+		start = i + 1
+	}
+	return total, nil
+}
+
+// ArrayAggregate computes a sum, average, minimum or maximum over the elements of an array and compares it to an
+// expected value. The expected value can be a number, numeric expression (e.g. '$> 0'), variable, or a '$.' prefixed
+// path to another field of the response.
+type ArrayAggregate struct {
+	Op      string
+	Field   string
+	Matches interface{}
+	Root    interface{}
+}
+
+func (a *ArrayAggregate) Parse(parentNode interface{}, node interface{}) error {
+	aggNode, ok := node.(map[interface{}]interface{})
+	if !ok {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_AGGREGATE, TYPE_ARRAY), parentNode))
+	}
+
+	a.Op = fmt.Sprintf("%v", aggNode[TEST_KEY_AGG_OP])
+	switch a.Op {
+	case AGG_SUM, AGG_AVG, AGG_MIN, AGG_MAX:
+	default:
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_AGG_OP, TEST_KEY_AGGREGATE), parentNode))
+	}
+
+	if field, ok := aggNode[TEST_KEY_AGG_FIELD]; ok {
+		a.Field = fmt.Sprintf("%v", field)
+	}
+
+	var mOk bool
+	if a.Matches, mOk = aggNode[TEST_KEY_MATCHES]; !mOk {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_MATCHES, TEST_KEY_AGGREGATE), parentNode))
+	}
+	return nil
+}
+
+func (a *ArrayAggregate) Name() string {
+	return fmt.Sprintf("%v(%v)", a.Op, a.Field)
+}
+
+// Compute returns the aggregated value of the array. A non-empty message is returned if it cannot be computed.
+func (a *ArrayAggregate) Compute(elements []interface{}) (float64, string) {
+	if len(elements) == 0 && a.Op != AGG_SUM {
+		return 0, fmt.Sprintf(AggregateEmptyErrFmt, a.Name())
+	}
+
+	var result float64
+	for i, e := range elements {
+		value := e
+		if a.Field != "" {
+			obj, ok := e.(map[string]interface{})
+			if !ok {
+				return 0, fmt.Sprintf(AggregateTypeErrFmt, a.Field, i, e, reflect.TypeOf(e))
+			}
+			value, _ = GetJsonValue(obj, a.Field)
+		}
+
+		num, ok := value.(float64)
+		if !ok {
+			return 0, fmt.Sprintf(AggregateTypeErrFmt, a.Field, i, value, reflect.TypeOf(value))
+		}
+
+		if i == 0 {
+			result = num
+			continue
+		}
+		switch a.Op {
+		case AGG_SUM, AGG_AVG:
+			result += num
+		case AGG_MIN:
+			result = math.Min(result, num)
+		case AGG_MAX:
+			result = math.Max(result, num)
+		}
+	}
+
+	if a.Op == AGG_AVG {
+		result /= float64(len(elements))
+	}
+	return result, ""
+}
+
+// Evaluate compares the aggregated value with the expected value. Values are considered equal within a small
+// tolerance to account for floating point rounding (e.g. summing prices).
+func (a *ArrayAggregate) Evaluate(value float64, datastore *DataStore) (bool, string, error) {
+	expected := a.Matches
+	if s, ok := expected.(string); ok {
+		resolved, err := datastore.ExpandVariable(s)
+		if err != nil {
+			return false, "", fmt.Errorf(BadVarMatcherFmt, s)
+		}
+		expected = resolved
+
+		if resolvedStr, ok := resolved.(string); ok && strings.HasPrefix(resolvedStr, FIELD_KEY_PREFIX) {
+			root, _ := a.Root.(map[string]interface{})
+			expected, _ = GetJsonValue(root, strings.TrimPrefix(resolvedStr, FIELD_KEY_PREFIX))
+		}
+	}
+
+	var expectedNum float64
+	switch v := expected.(type) {
+	case float64:
+		expectedNum = v
+	case int:
+		expectedNum = float64(v)
+	case int64:
+		expectedNum = float64(v)
+	case string:
+		if isNumExpr(strings.TrimSpace(v)) {
+			passed, _, _, err := evaluateFloatExpr(v, value)
+			return passed, v, err
+		}
+		var err error
+		if expectedNum, err = strconv.ParseFloat(v, 64); err != nil {
+			return false, v, fmt.Errorf(AggregateExpectedErrFmt, v, TEST_KEY_AGGREGATE)
+		}
+	default:
+		return false, fmt.Sprintf("%v", expected), fmt.Errorf(AggregateExpectedErrFmt, expected, TEST_KEY_AGGREGATE)
+	}
+
+	tolerance := 1e-9 * math.Max(1, math.Abs(expectedNum))
+	return math.Abs(value-expectedNum) <= tolerance, fmt.Sprintf("%v", expectedNum), nil
+}
+
+func (a *ArrayAggregate) SetRoot(root interface{}) {
+	a.Root = root
+}
+
+// ArrayItemCounter counts how many elements of an array satisfy an item definition
+type ArrayItemCounter struct {
+	Expr    string
+	Matcher ResponseMatcher
+}
+
+// Count returns the number of elements that satisfy the item definition. Matches are performed against a copy
+// of the datastore so that values stored while counting do not leak into the test.
+func (c *ArrayItemCounter) Count(elements []interface{}, datastore *DataStore) (int64, error) {
+	var count int64
+	for _, e := range elements {
+		scratch := NewChildDataStore(datastore.Parent)
+		for k, v := range datastore.Store {
+			scratch.Put(k, v)
+		}
+		c.Matcher.DS = &scratch
+		c.Matcher.NodeCache = NodeCache{Cache: make(map[string]NodeCacheObj)}
+
+		passed, _, err := c.Matcher.Match(map[string]interface{}{ARRAY_COUNT_ITEM_KEY: e})
+		if err != nil {
+			return count, err
+		}
+		if passed {
+			count++
+		}
+	}
+	return count, nil
+}
+
+// Evaluate compares the count against the counter's expression. An expression can either be an exact
+// number or a numeric expression such as '$>= 3'.
+func (c *ArrayItemCounter) Evaluate(count int64) (bool, string, error) {
+	if expected, err := strconv.ParseInt(c.Expr, 10, 64); err == nil {
+		return expected == count, fmt.Sprintf("%v", expected), nil
+	}
+
+	status, evaluated, _, err := evaluateNumExpr(c.Expr, count)
+	if !evaluated {
+		return false, c.Expr, fmt.Errorf("invalid '%v' expression: %v", TEST_KEY_MATCH_COUNT, c.Expr)
+	}
+	return status, c.Expr, err
+}
+
 type ArrayMatcher struct {
-	Length    *int64
-	LengthStr *string
-	Items     []interface{}
-	Sorted    bool
+	Length      *int64
+	LengthStr   *string
+	Items       []interface{}
+	Sorted      bool
+	MatchCount  string
+	Counters    []*ArrayItemCounter
+	Sequence    *ArraySequence
+	Order       *ArrayOrder
+	Unique      *ArrayUniqueness
+	Finder      *ArrayFinder
+	Aggregate   *ArrayAggregate
+	Homogeneous *ArrayHomogeneity
+	Range       *ArrayElementRange
+	Contains    []interface{}
+	Slice       *ArraySlice
 	FieldMatcherProps
 }
 
@@ -39,6 +1028,77 @@ func (m *ArrayMatcher) Parse(parentNode interface{}, node map[interface{}]interf
 		}
 	}
 
+	if v, ok := node[TEST_KEY_MATCH_COUNT]; ok {
+		m.MatchCount = fmt.Sprintf("%v", v)
+	}
+
+	if v, ok := node[TEST_KEY_SEQUENCE]; ok {
+		m.Sequence = &ArraySequence{}
+		if err := m.Sequence.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := node[TEST_KEY_ORDERED_BY]; ok {
+		m.Order = &ArrayOrder{}
+		if err := m.Order.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := node[TEST_KEY_UNIQUE_BY]; ok {
+		m.Unique = &ArrayUniqueness{}
+		if err := m.Unique.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := node[TEST_KEY_HOMOGENEOUS]; ok && v != false {
+		m.Homogeneous = &ArrayHomogeneity{}
+		if err := m.Homogeneous.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := node[TEST_KEY_ELEMENT_RANGE]; ok {
+		m.Range = &ArrayElementRange{}
+		if err := m.Range.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := node[TEST_KEY_CONTAINS]; ok {
+		switch val := v.(type) {
+		case []interface{}:
+			m.Contains = val
+		case map[interface{}]interface{}, nil:
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_CONTAINS, TYPE_ARRAY), parentNode))
+		default:
+			m.Contains = []interface{}{val}
+		}
+	}
+
+	if v, ok := node[TEST_KEY_FIND]; ok {
+		m.Finder = &ArrayFinder{}
+		if err := m.Finder.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := node[TEST_KEY_AGGREGATE]; ok {
+		m.Aggregate = &ArrayAggregate{}
+		if err := m.Aggregate.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
+	if v, ok := node[TEST_KEY_SLICE]; ok {
+		m.Slice = &ArraySlice{}
+		if err := m.Slice.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
 	if v, ok := node[TEST_KEY_SORTED]; ok {
 		m.Sorted = v.(bool)
 	} else {
@@ -66,6 +1126,8 @@ func (m *ArrayMatcher) Match(responseValue interface{}, datastore *DataStore) (b
 	var err error
 
 	responseLength := int64(len(typedResponseValue))
+	// each validation only runs if the previous ones passed or none were defined
+	validated := m.Length != nil || m.LengthStr != nil
 	if m.Length != nil {
 		status = responseLength == *m.Length
 		if !status {
@@ -95,8 +1157,202 @@ func (m *ArrayMatcher) Match(responseValue interface{}, datastore *DataStore) (b
 		m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_LENGTH, responseLength)
 	}
 
+	if len(m.Counters) > 0 && (status || !validated) {
+		status = true
+		var counts []int64
+		for i, c := range m.Counters {
+			count, cErr := c.Count(typedResponseValue, datastore)
+			if cErr != nil {
+				return false, store, cErr
+			}
+
+			passed, expected, cErr := c.Evaluate(count)
+			if cErr != nil {
+				return false, store, cErr
+			}
+
+			if !passed {
+				m.ErrorStr = fmt.Sprintf("[%v] "+MatchCountErrFmt, TEST_KEY_MATCH_COUNT, expected, i, count)
+				status = false
+				break
+			}
+			counts = append(counts, count)
+		}
+
+		if status {
+			m.ErrorStr = fmt.Sprintf("[%v] %v [%v] %v", TEST_KEY_LENGTH, responseLength, TEST_KEY_MATCH_COUNT, counts)
+		}
+		validated = true
+	}
+
+	if m.Sequence != nil && (status || !validated) {
+		var seqErr string
+		if status, seqErr = m.Sequence.Validate(typedResponseValue); !status {
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_SEQUENCE, seqErr)
+		} else if !validated {
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_LENGTH, responseLength)
+		}
+		validated = true
+	}
+
+	if m.Order != nil && (status || !validated) {
+		var orderMsg string
+		if status, orderMsg, err = m.Order.Validate(typedResponseValue, datastore); err != nil {
+			return false, store, err
+		}
+		if !status || !validated {
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_ORDERED_BY, orderMsg)
+		}
+		validated = true
+	}
+
+	if m.Unique != nil && (status || !validated) {
+		var uniqueMsg string
+		if status, uniqueMsg, err = m.Unique.Validate(typedResponseValue, datastore); err != nil {
+			return false, store, err
+		}
+		if !status || !validated {
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_UNIQUE_BY, uniqueMsg)
+		}
+		validated = true
+	}
+
+	if m.Homogeneous != nil && (status || !validated) {
+		var typeMsg string
+		status, typeMsg = m.Homogeneous.Validate(typedResponseValue)
+		if !status || !validated {
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_HOMOGENEOUS, typeMsg)
+		}
+		validated = true
+	}
+
+	if m.Range != nil && (status || !validated) {
+		var rangeMsg string
+		if status, rangeMsg, err = m.Range.Validate(typedResponseValue, datastore); err != nil {
+			return false, store, err
+		}
+		if !status || !validated {
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_ELEMENT_RANGE, rangeMsg)
+		}
+		validated = true
+	}
+
+	if m.Aggregate != nil && (status || !validated) {
+		value, aggErr := m.Aggregate.Compute(typedResponseValue)
+		if aggErr != "" {
+			status = false
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_AGGREGATE, aggErr)
+		} else {
+			var expected string
+			if status, expected, err = m.Aggregate.Evaluate(value, datastore); err != nil {
+				return false, store, err
+			}
+			if !status {
+				m.ErrorStr = fmt.Sprintf("[%v] "+AggregateErrFmt, TEST_KEY_AGGREGATE, m.Aggregate.Name(), value, expected)
+			} else if !validated {
+				m.ErrorStr = fmt.Sprintf("[%v] %v = %v", TEST_KEY_AGGREGATE, m.Aggregate.Name(), value)
+			}
+		}
+		validated = true
+	}
+
+	if m.Contains != nil && (status || !validated) {
+		missing, cErr := m.missingValues(typedResponseValue, datastore)
+		if cErr != nil {
+			return false, store, cErr
+		}
+		status = len(missing) == 0
+		if !status {
+			m.ErrorStr = fmt.Sprintf("[%v] "+ContainsErrFmt, TEST_KEY_CONTAINS, strings.Join(missing, ", "),
+				varToString(typedResponseValue))
+		} else if !validated {
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_CONTAINS, varToString(m.Contains))
+		}
+		validated = true
+	}
+
+	if m.Slice != nil {
+		m.Slice.Results = nil
+	}
+	if m.Slice != nil && (status || !validated) {
+		var sliceMsg string
+		if status, sliceMsg, err = m.Slice.Validate(typedResponseValue, datastore); err != nil {
+			return false, store, err
+		}
+		if !status || !validated {
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_SLICE, sliceMsg)
+		}
+		validated = true
+	}
+
+	if m.Finder != nil {
+		m.Finder.Results = nil
+	}
+	if m.Finder != nil && (status || !validated) {
+		index, expected, fErr := m.Finder.Find(typedResponseValue, datastore)
+		if fErr != nil {
+			return false, store, fErr
+		}
+
+		if index < 0 {
+			status = false
+			m.ErrorStr = fmt.Sprintf("[%v] "+FindNotFoundErrFmt, TEST_KEY_FIND, m.Finder.Field, expected)
+		} else {
+			if status, err = m.Finder.Validate(typedResponseValue[index], datastore); err != nil {
+				return false, store, err
+			}
+			for _, r := range m.Finder.Results {
+				r.ObjectKeyPath = fmt.Sprintf("[%v]%v", index, r.ObjectKeyPath)
+			}
+			m.ErrorStr = fmt.Sprintf("[%v] %v", TEST_KEY_FIND, index)
+		}
+	}
+
 	if status && m.DSName != "" {
 		err = store.PutVariable(m.DSName, responseValue)
 	}
 	return status, store, err
 }
+
+// missingValues returns the expected 'contains' values that aren't elements of the array. Values are compared by
+// their string representation after resolving any variables, so that they can be used regardless of the
+// elements' types.
+func (m *ArrayMatcher) missingValues(elements []interface{}, datastore *DataStore) ([]string, error) {
+	present := map[string]bool{}
+	for _, e := range elements {
+		present[varToString(e)] = true
+	}
+
+	var missing []string
+	for _, v := range m.Contains {
+		expected := varToString(v)
+		if s, ok := v.(string); ok {
+			resolved, err := datastore.ExpandVariable(s)
+			if err != nil {
+				return nil, fmt.Errorf(BadVarMatcherFmt, s)
+			}
+			expected = varToString(resolved, s)
+		}
+		if !present[expected] {
+			missing = append(missing, fmt.Sprintf("'%v'", expected))
+		}
+	}
+	return missing, nil
+}
+
+func (m *ArrayMatcher) SetRoot(root interface{}) {
+	if m.Aggregate != nil {
+		m.Aggregate.SetRoot(root)
+	}
+}
+
+func (m *ArrayMatcher) NestedResults() []*FieldMatcherResult {
+	var results []*FieldMatcherResult
+	if m.Slice != nil {
+		results = append(results, m.Slice.Results...)
+	}
+	if m.Finder != nil {
+		results = append(results, m.Finder.Results...)
+	}
+	return results
+}
@@ -0,0 +1,298 @@
+package arp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestArrayAggregate(t *testing.T) {
+	ds := NewDataStore()
+	ds.Put("expectedTotal", 6.6)
+
+	response := `{"total": 6.6, "items": [{"price": 1.1}, {"price": 2.2}, {"price": 3.3}], "empty": []}`
+	tests := []struct {
+		name    string
+		op      string
+		field   string
+		matches string
+		passed  bool
+	}{
+		{"sum within tolerance", "sum", "price", "6.6", true},
+		{"sum of another field", "sum", "price", "$.total", true},
+		{"sum of a variable", "sum", "price", "'@{expectedTotal}'", true},
+		{"wrong sum", "sum", "price", "6.5", false},
+		{"average expression", "avg", "price", "'$> 2.19, $< 2.21'", true},
+		{"failing average expression", "avg", "price", "'$> 2.21'", false},
+		{"minimum", "min", "price", "1.1", true},
+		{"maximum expression", "max", "price", "'$<= 3.3'", true},
+		{"failing maximum expression", "max", "price", "'$< 3.3'", false},
+		{"missing field", "sum", "cost", "0", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := loadTestMatcher(t, `
+items:
+  type: array
+  aggregate:
+    op: `+tt.op+`
+    field: `+tt.field+`
+    matches: `+tt.matches+`
+`, &ds)
+			if passed, errs := matchTestJson(t, matcher, response); passed != tt.passed {
+				t.Errorf("expected the aggregate to pass: %v but got: %v", tt.passed, errs)
+			}
+		})
+	}
+
+	// only sums can be computed for empty arrays
+	for op, passed := range map[string]bool{"sum": true, "avg": false, "min": false, "max": false} {
+		matcher := loadTestMatcher(t, "empty:\n  type: array\n  aggregate:\n    op: "+op+"\n    matches: 0\n", &ds)
+		if status, errs := matchTestJson(t, matcher, response); status != passed {
+			t.Errorf("expected the %v of an empty array to pass: %v but got: %v", op, passed, errs)
+		}
+	}
+}
+
+func TestArraySequence(t *testing.T) {
+	tests := []struct {
+		name     string
+		sequence ArraySequence
+		elements []interface{}
+		passed   bool
+	}{
+		{"default step", ArraySequence{Step: 1}, []interface{}{1.0, 2.0, 3.0}, true},
+		{"skipped value", ArraySequence{Step: 1}, []interface{}{1.0, 2.0, 4.0}, false},
+		{"fractional step", ArraySequence{Step: 0.1}, []interface{}{0.1, 0.2, 0.3, 0.4}, true},
+		{"large fractional values", ArraySequence{Step: 0.1}, []interface{}{1e6 + 0.1, 1e6 + 0.2, 1e6 + 0.3}, true},
+		{"wrong fractional step", ArraySequence{Step: 0.1}, []interface{}{0.1, 0.2, 0.31}, false},
+		{"negative step", ArraySequence{Step: -0.5}, []interface{}{1.0, 0.5, 0.0, -0.5}, true},
+		{"field", ArraySequence{Field: "offset", Step: 10}, []interface{}{
+			map[string]interface{}{"offset": 10.0},
+			map[string]interface{}{"offset": 20.0},
+		}, true},
+		{"strictly increasing", ArraySequence{StrictlyIncreasing: true}, []interface{}{1.0, 1.5, 7.0}, true},
+		{"not strictly increasing", ArraySequence{StrictlyIncreasing: true}, []interface{}{1.0, 1.0}, false},
+		{"non-numeric value", ArraySequence{Step: 1}, []interface{}{1.0, "2"}, false},
+	}
+
+	for _, tt := range tests {
+		if passed, message := tt.sequence.Validate(tt.elements); passed != tt.passed {
+			t.Errorf("%v: expected the sequence to pass: %v but got: %v", tt.name, tt.passed, message)
+		}
+	}
+}
+
+func TestArrayHomogeneous(t *testing.T) {
+	tests := []struct {
+		name        string
+		homogeneous string
+		response    string
+		passed      bool
+	}{
+		{"same type as the first element", "true", `{"list": ["a", "b"]}`, true},
+		{"mixed in null", "true", `{"list": ["a", null]}`, false},
+		{"mixed in object", "true", `{"list": [{"a": 1}, {"b": 2}, [1]]}`, false},
+		{"empty array", "true", `{"list": []}`, true},
+		{"disabled", "false", `{"list": ["a", 1]}`, true},
+		{"integers", "integer", `{"list": [1, 2, 3]}`, true},
+		{"fractional number among integers", "integer", `{"list": [1, 2.5]}`, false},
+		{"numbers", "number", `{"list": [1.5, 2]}`, true},
+		{"specific type", "bool", `{"list": ["true"]}`, false},
+		{"nulls", "null", `{"list": [null, null]}`, true},
+		{"quoted null", "'null'", `{"list": [null, 1]}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := loadTestMatcher(t, "list:\n  type: array\n  length: $any\n  homogeneous: "+tt.homogeneous+"\n", nil)
+			if passed, errs := matchTestJson(t, matcher, tt.response); passed != tt.passed {
+				t.Errorf("expected the array to pass: %v but got: %v", tt.passed, errs)
+			}
+		})
+	}
+}
+
+func TestArrayHomogeneousError(t *testing.T) {
+	matcher := loadTestMatcher(t, "list:\n  type: array\n  homogeneous: true\n", nil)
+	_, errs := matchTestJson(t, matcher, `{"list": ["a", "b", null]}`)
+	if expected := fmt.Sprintf(HomogeneousErrFmt, TYPE_STR, 2, TYPE_NULL); !strings.Contains(errs, expected) {
+		t.Errorf("expected '%v' but got: %v", expected, errs)
+	}
+
+	def := parseTestYaml(t, "list:\n  type: array\n  homogeneous: date\n")
+	malformed := NewResponseMatcher(nil)
+	if err := malformed.loadObjectFields(def, def, FieldMatcherPath{}); err == nil {
+		t.Error("expected an unknown type to be rejected")
+	}
+}
+
+func TestArrayContains(t *testing.T) {
+	ds := NewDataStore()
+	ds.Put("expectedRole", "editor")
+
+	tests := []struct {
+		name     string
+		contains string
+		response string
+		passed   bool
+	}{
+		{"single value", "admin", `{"list": ["editor", "admin"]}`, true},
+		{"list of values", "[admin, editor]", `{"list": ["editor", "viewer", "admin"]}`, true},
+		{"variable", `["@{expectedRole}"]`, `{"list": ["editor"]}`, true},
+		{"numbers", "[1, 2]", `{"list": [2, 3, 1]}`, true},
+		{"missing value", "[admin, owner]", `{"list": ["admin", "editor"]}`, false},
+		{"empty array", "admin", `{"list": []}`, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := loadTestMatcher(t, "list:\n  type: array\n  contains: "+tt.contains+"\n", &ds)
+			if passed, errs := matchTestJson(t, matcher, tt.response); passed != tt.passed {
+				t.Errorf("expected the array to pass: %v but got: %v", tt.passed, errs)
+			}
+		})
+	}
+
+	matcher := loadTestMatcher(t, "list:\n  type: array\n  contains: [admin, owner]\n", &ds)
+	_, errs := matchTestJson(t, matcher, `{"list": ["admin"]}`)
+	if !strings.Contains(errs, "[contains] Expected array to contain 'owner' but found [\"admin\"] instead") {
+		t.Errorf("expected the missing value to be reported but got: %v", errs)
+	}
+
+	// only failing once the length has passed
+	matcher = loadTestMatcher(t, "list:\n  type: array\n  length: 1\n  contains: admin\n", &ds)
+	if passed, errs := matchTestJson(t, matcher, `{"list": ["admin", "owner"]}`); passed || !strings.Contains(errs, "Expected array with length = 1") {
+		t.Errorf("expected the length to fail first but got: %v", errs)
+	}
+
+	for _, contains := range []string{"{role: admin}", "null"} {
+		def := parseTestYaml(t, "list:\n  type: array\n  contains: "+contains+"\n")
+		malformed := NewResponseMatcher(&ds)
+		if err := malformed.loadObjectFields(def, def, FieldMatcherPath{}); err == nil {
+			t.Errorf("expected 'contains: %v' to be rejected", contains)
+		}
+	}
+}
+
+func TestArrayUniqueBy(t *testing.T) {
+	ds := NewDataStore()
+	ds.Put("uniqueField", "email")
+
+	tests := []struct {
+		name     string
+		field    string
+		response string
+		passed   bool
+	}{
+		{"unique values", "email", `{"list": [{"email": "a@x.com", "id": 1}, {"email": "b@x.com", "id": 1}]}`, true},
+		{"duplicates among distinct objects", "email", `{"list": [{"email": "a@x.com", "id": 1}, {"email": "a@x.com", "id": 2}]}`, false},
+		{"nested field", "profile.email", `{"list": [{"profile": {"email": "a@x.com"}}, {"profile": {"email": "b@x.com"}}]}`, true},
+		{"prefixed field", "$.email", `{"list": [{"email": "a@x.com"}, {"email": "a@x.com"}]}`, false},
+		{"variable", "@{uniqueField}", `{"list": [{"email": "a@x.com"}, {"email": "a@x.com"}]}`, false},
+		{"values of different types", "id", `{"list": [{"id": 1}, {"id": "1"}]}`, true},
+		{"missing field", "email", `{"list": [{"email": "a@x.com"}, {"id": 2}]}`, false},
+		{"not an object", "email", `{"list": [{"email": "a@x.com"}, "a@x.com"]}`, false},
+		{"empty array", "email", `{"list": []}`, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := loadTestMatcher(t, "list:\n  type: array\n  uniqueBy: \""+tt.field+"\"\n", &ds)
+			if passed, errs := matchTestJson(t, matcher, tt.response); passed != tt.passed {
+				t.Errorf("expected the array to pass: %v but got: %v", tt.passed, errs)
+			}
+		})
+	}
+
+	matcher := loadTestMatcher(t, "list:\n  type: array\n  uniqueBy: email\n", &ds)
+	_, errs := matchTestJson(t, matcher, `{"list": [{"email": "a@x.com", "id": 1}, {"email": "b@x.com", "id": 2}, {"email": "a@x.com", "id": 3}]}`)
+	if !strings.Contains(errs, "[uniqueBy] Expected unique 'email' values but found duplicates: 'a@x.com' at indices [0 2]") {
+		t.Errorf("expected the duplicate indices to be reported but got: %v", errs)
+	}
+
+	for _, field := range []string{"\"\"", "[email]"} {
+		def := parseTestYaml(t, "list:\n  type: array\n  uniqueBy: "+field+"\n")
+		malformed := NewResponseMatcher(&ds)
+		if err := malformed.loadObjectFields(def, def, FieldMatcherPath{}); err == nil {
+			t.Errorf("expected 'uniqueBy: %v' to be rejected", field)
+		}
+	}
+}
+
+func TestArrayContainsHeaders(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		w.Header().Add("Vary", "Origin")
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		w.Write([]byte(`{}`))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		contains string
+		passed   bool
+	}{
+		{"[Origin, Accept-Encoding]", true},
+		{"Origin", true},
+		{"[Origin, Cookie]", false},
+	}
+
+	for _, tt := range tests {
+		result := runTestFile(t, `
+tests:
+  - name: Vary
+    route: "@{host}"
+    method: GET
+    response:
+      code: 200
+      headers:
+        Vary:
+          type: array
+          contains: `+tt.contains+`
+`, server.URL, SuiteOptions{})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("expected 1 result but got %v", len(result.Results))
+		}
+		if r := result.Results[0]; r.Passed != tt.passed {
+			t.Errorf("expected 'contains: %v' to pass: %v but got:\n%v", tt.contains, tt.passed, failedFields(r))
+		}
+	}
+}
+
+func TestArraySlice(t *testing.T) {
+	ds := NewDataStore()
+	ds.Put("allUsers", []interface{}{1, 2, 3, 4, 5})
+	ds.Put("pageSize", 2)
+
+	tests := []struct {
+		slice    string
+		response string
+		passed   bool
+	}{
+		{"offset: 2\n    length: 2", `{"list": [3, 4]}`, true},
+		{"offset: 0", `{"list": [1, 2, 3, 4, 5]}`, true},
+		{"offset: \"@{pageSize}\"\n    length: \"@{pageSize}\"", `{"list": [3, 4]}`, true},
+		{"offset: 4\n    length: 2", `{"list": [5]}`, true},
+		{"offset: 2\n    length: 2", `{"list": [2, 3]}`, false},
+	}
+	for _, tt := range tests {
+		matcher := loadTestMatcher(t, "list:\n  type: array\n  slice:\n    of: \"@{allUsers}\"\n    "+tt.slice+"\n", &ds)
+		if passed, errs := matchTestJson(t, matcher, tt.response); passed != tt.passed {
+			t.Errorf("%v: expected the slice to pass: %v but got: %v", tt.slice, tt.passed, errs)
+		}
+	}
+
+	// negative integers would panic when slicing the stored array
+	for _, bound := range []string{"offset: -1", "length: -1"} {
+		def := parseTestYaml(t, "list:\n  type: array\n  slice:\n    of: \"@{allUsers}\"\n    "+bound+"\n")
+		malformed := NewResponseMatcher(&ds)
+		if err := malformed.loadObjectFields(def, def, FieldMatcherPath{}); err == nil {
+			t.Errorf("%v: expected the negative bound to be rejected", bound)
+		}
+	}
+}
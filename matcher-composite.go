@@ -0,0 +1,137 @@
+package arp
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+const (
+	TEST_KEY_ALL_OF = "allOf"
+	TEST_KEY_ANY_OF = "anyOf"
+
+	CompositeErrFmt       = "%v validation failed: %v"
+	CompositeSubErrFmt    = "[%v] %v"
+	CompositeBothKeysFmt  = "\nOnly one of '%v' or '%v' can be defined on %v"
+	CompositeNestedErrFmt = "\n'%v' validations do not support '%v' definitions: %v"
+)
+
+// CompositeMatcher validates a single field against multiple matcher definitions. With 'allOf' every definition must
+// pass while 'anyOf' requires at least one of them to pass.
+type CompositeMatcher struct {
+	Key      string
+	Matchers []FieldMatcher
+	FieldMatcherProps
+}
+
+func isCompositeMatcher(node map[interface{}]interface{}) bool {
+	_, allOf := node[TEST_KEY_ALL_OF]
+	_, anyOf := node[TEST_KEY_ANY_OF]
+	return allOf || anyOf
+}
+
+// compositeDefinitions returns the key used by a composite matcher definition along with its sub definitions.
+func compositeDefinitions(parentNode interface{}, node map[interface{}]interface{}) (string, []map[interface{}]interface{}, error) {
+	allOf, hasAllOf := node[TEST_KEY_ALL_OF]
+	anyOf, hasAnyOf := node[TEST_KEY_ANY_OF]
+	if hasAllOf && hasAnyOf {
+		return "", nil, errors.New(ObjectPrintf(fmt.Sprintf(CompositeBothKeysFmt, TEST_KEY_ALL_OF, TEST_KEY_ANY_OF, "definition"), parentNode))
+	}
+
+	key := TEST_KEY_ALL_OF
+	list := allOf
+	if hasAnyOf {
+		key = TEST_KEY_ANY_OF
+		list = anyOf
+	}
+
+	items, ok := list.([]interface{})
+	if !ok || len(items) == 0 {
+		return "", nil, errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, key, "definition"), parentNode))
+	}
+
+	var definitions []map[interface{}]interface{}
+	for _, item := range items {
+		definition, ok := item.(map[interface{}]interface{})
+		if !ok {
+			return "", nil, errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, key, "definition"), parentNode))
+		}
+		definitions = append(definitions, definition)
+	}
+	return key, definitions, nil
+}
+
+func (m *CompositeMatcher) Parse(parentNode interface{}, node map[interface{}]interface{}) error {
+	key, definitions, err := compositeDefinitions(parentNode, node)
+	if err != nil {
+		return err
+	}
+	m.Key = key
+
+	for _, definition := range definitions {
+		// nested nodes are loaded as separate matchers on the response matcher, which can't be tied to a
+		// single sub definition
+		for _, nested := range []string{TEST_KEY_ITEMS, TEST_KEY_PROPERTIES} {
+			if _, ok := definition[nested]; ok {
+				return errors.New(ObjectPrintf(fmt.Sprintf(CompositeNestedErrFmt, key, nested, "definition"), parentNode))
+			}
+		}
+
+		matcher, _, err := parseFieldMatcher(parentNode, definition)
+		if err != nil {
+			return err
+		}
+		if _, ok := matcher.(*ExecutableMatcher); ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(CompositeNestedErrFmt, key, TYPE_EXEC, "definition"), parentNode))
+		}
+		m.Matchers = append(m.Matchers, matcher)
+	}
+
+	return m.ParseProps(node)
+}
+
+func (m *CompositeMatcher) Match(responseValue interface{}, datastore *DataStore) (bool, DataStore, error) {
+	store := NewDataStore()
+	m.ErrorStr = ""
+
+	var failures []string
+	passed := 0
+	for i, matcher := range m.Matchers {
+		status, passthrough := matcher.ValidateExistance(responseValue)
+		if passthrough {
+			var subStore DataStore
+			var err error
+			status, subStore, err = matcher.Match(responseValue, datastore)
+			if err != nil {
+				return false, store, err
+			}
+			if status {
+				for k := range subStore.Store {
+					store.Put(k, subStore.Store[k])
+				}
+			}
+		}
+
+		if status {
+			passed++
+		} else {
+			failures = append(failures, fmt.Sprintf(CompositeSubErrFmt, i, matcher.Error()))
+		}
+	}
+
+	status := passed == len(m.Matchers)
+	if m.Key == TEST_KEY_ANY_OF {
+		status = passed > 0
+	}
+
+	var err error
+	if status {
+		m.ErrorStr = varToString(responseValue)
+		if m.DSName != "" {
+			err = store.PutVariable(m.DSName, responseValue)
+		}
+	} else {
+		m.ErrorStr = fmt.Sprintf(CompositeErrFmt, m.Key, strings.Join(failures, "; "))
+	}
+	return status, store, err
+}
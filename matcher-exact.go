@@ -0,0 +1,275 @@
+package arp
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+const (
+	CFG_RESPONSE_IGNORE = "ignore"
+
+	IgnorePath = "response.Ignore"
+
+	ExactExtraFieldErrMsg   = "Unexpected field that is not part of the exact response definition"
+	ExactExtraElementErrMsg = "Unexpected array element that is not part of the exact response definition"
+	IgnoredPathsFmt         = "Ignored paths: %v"
+)
+
+// IgnoredPaths are JSON paths (e.g. data[*].createdAt) of volatile response values, such as timestamps or generated
+// ids, that are excluded from exact response and example comparisons. Each key of a path may contain wildcards.
+type IgnoredPaths [][]string
+
+// ParseIgnoredPaths splits the JSON paths of a response's 'ignore' list into their keys
+func ParseIgnoredPaths(paths []string) (IgnoredPaths, error) {
+	var ignored IgnoredPaths
+	for _, p := range paths {
+		trimmed := strings.TrimPrefix(strings.TrimSpace(p), FIELD_KEY_PREFIX)
+		if trimmed == "" {
+			return nil, fmt.Errorf("invalid '%v' path: '%v'", CFG_RESPONSE_IGNORE, p)
+		}
+		var keys []string
+		for _, k := range SplitJsonPath(trimmed) {
+			if _, err := path.Match(k.Name, ""); err != nil {
+				return nil, fmt.Errorf("invalid '%v' path: '%v' - %v", CFG_RESPONSE_IGNORE, p, err)
+			}
+			keys = append(keys, k.Name)
+		}
+		ignored = append(ignored, keys)
+	}
+	return ignored, nil
+}
+
+// matches returns whether the keys of a response path match any of the ignored paths
+func (ip IgnoredPaths) matches(keys []string) bool {
+	for _, pattern := range ip {
+		if len(pattern) != len(keys) {
+			continue
+		}
+		matched := true
+		for i := range pattern {
+			if ok, _ := path.Match(pattern[i], keys[i]); !ok {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Prune returns a copy of a JSON value without the fields and array elements at any of the ignored paths. The paths
+// that were removed are added to the set of removed paths.
+func (ip IgnoredPaths) Prune(node interface{}, removed map[string]bool) interface{} {
+	if len(ip) == 0 {
+		return node
+	}
+	return ip.prune(node, nil, "", removed)
+}
+
+func (ip IgnoredPaths) prune(node interface{}, keys []string, nodePath string, removed map[string]bool) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		pruned := make(map[string]interface{}, len(n))
+		for k, v := range n {
+			fieldKeys := append(keys[:len(keys):len(keys)], k)
+			fieldPath := fmt.Sprintf("%v.%v", nodePath, k)
+			if ip.matches(fieldKeys) {
+				removed[fieldPath] = true
+				continue
+			}
+			pruned[k] = ip.prune(v, fieldKeys, fieldPath, removed)
+		}
+		return pruned
+	case []interface{}:
+		pruned := make([]interface{}, 0, len(n))
+		for i, e := range n {
+			elementKeys := append(keys[:len(keys):len(keys)], strconv.Itoa(i))
+			elementPath := fmt.Sprintf("%v[%v]", nodePath, i)
+			if ip.matches(elementKeys) {
+				removed[elementPath] = true
+				continue
+			}
+			pruned = append(pruned, ip.prune(e, elementKeys, elementPath, removed))
+		}
+		return pruned
+	}
+	return node
+}
+
+// PruneDefinition returns a copy of a payload definition without the fields and array items declared at any of the
+// ignored paths, so that the remaining array items still line up with the elements of the pruned response.
+func (ip IgnoredPaths) PruneDefinition(declared map[interface{}]interface{}) map[interface{}]interface{} {
+	if len(ip) == 0 {
+		return declared
+	}
+	pruned, _ := ip.pruneDefinition(declared, nil).(map[interface{}]interface{})
+	return pruned
+}
+
+// pruneDefinition follows the structure of a definition the same way exactDiff does
+func (ip IgnoredPaths) pruneDefinition(declared interface{}, keys []string) interface{} {
+	switch d := declared.(type) {
+	case map[interface{}]interface{}:
+		if isCompositeMatcher(d) {
+			return d
+		}
+		pruned := make(map[interface{}]interface{}, len(d))
+		if t, ok := d[TEST_KEY_TYPE]; ok {
+			for k, v := range d {
+				pruned[k] = v
+			}
+			if properties, ok := d[TEST_KEY_PROPERTIES]; ok && t == TYPE_OBJ {
+				pruned[TEST_KEY_PROPERTIES] = ip.pruneDefinition(properties, keys)
+			}
+			if items, ok := d[TEST_KEY_ITEMS]; ok && t == TYPE_ARRAY {
+				pruned[TEST_KEY_ITEMS] = ip.pruneDefinition(items, keys)
+			}
+			return pruned
+		}
+		for k, v := range d {
+			key := fmt.Sprintf("%v", k)
+			fieldKeys := append(keys[:len(keys):len(keys)], key)
+			// '$.' paths aren't compared beyond their own key, so they're kept as they are
+			if !strings.HasPrefix(key, FIELD_KEY_PREFIX) && ip.matches(fieldKeys) {
+				continue
+			}
+			pruned[k] = ip.pruneDefinition(v, fieldKeys)
+		}
+		return pruned
+	case []interface{}:
+		pruned := make([]interface{}, 0, len(d))
+		for i, e := range d {
+			elementKeys := append(keys[:len(keys):len(keys)], strconv.Itoa(i))
+			if ip.matches(elementKeys) {
+				continue
+			}
+			pruned = append(pruned, ip.pruneDefinition(e, elementKeys))
+		}
+		return pruned
+	}
+	return declared
+}
+
+// ignoredResult reports the paths removed from a response by its ignored paths, or nil if none were removed
+func ignoredResult(removed map[string]bool) *FieldMatcherResult {
+	if len(removed) == 0 {
+		return nil
+	}
+	var paths []string
+	for p := range removed {
+		paths = append(paths, p)
+	}
+	sort.Strings(paths)
+	return &FieldMatcherResult{
+		ObjectKeyPath: IgnorePath,
+		Error:         fmt.Sprintf(IgnoredPathsFmt, strings.Join(paths, ", ")),
+		Status:        true,
+	}
+}
+
+// exactDiff compares a response value against its declared definition and reports every field and array element
+// within the response that isn't declared. Values are validated by the regular matchers, so only the structure is
+// compared here. Fields declared with a type but without 'properties' or 'items', unsorted arrays, composite
+// definitions and '$.' paths are not compared beyond their own key.
+func exactDiff(declared interface{}, actual interface{}, path string) []*FieldMatcherResult {
+	switch d := declared.(type) {
+	case map[interface{}]interface{}:
+		if isCompositeMatcher(d) {
+			return nil
+		}
+		if t, ok := d[TEST_KEY_TYPE]; ok {
+			switch t {
+			case TYPE_OBJ:
+				if properties, ok := d[TEST_KEY_PROPERTIES].(map[interface{}]interface{}); ok {
+					return exactObjectDiff(properties, actual, path)
+				}
+			case TYPE_ARRAY:
+				if sorted, ok := d[TEST_KEY_SORTED].(bool); ok && !sorted {
+					return nil
+				}
+				if items, ok := d[TEST_KEY_ITEMS]; ok {
+					return exactDiff(items, actual, path)
+				}
+			}
+			return nil
+		}
+		return exactObjectDiff(d, actual, path)
+	case []interface{}:
+		return exactArrayDiff(d, actual, path)
+	}
+	return nil
+}
+
+// exactObjectDiff compares the fields of a response object against the fields declared for it
+func exactObjectDiff(declared map[interface{}]interface{}, actual interface{}, path string) []*FieldMatcherResult {
+	obj, ok := actual.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	declaredKeys := make(map[string]interface{})
+	for k, v := range declared {
+		key := fmt.Sprintf("%v", k)
+		if strings.HasPrefix(key, FIELD_KEY_PREFIX) {
+			// only the top level key of a path is known to be declared
+			if pathKeys := SplitJsonPath(strings.TrimPrefix(key, FIELD_KEY_PREFIX)); len(pathKeys) > 0 {
+				if _, exists := declaredKeys[pathKeys[0].Name]; !exists {
+					declaredKeys[pathKeys[0].Name] = nil
+				}
+			}
+			continue
+		}
+		declaredKeys[key] = v
+	}
+
+	var keys []string
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	var results []*FieldMatcherResult
+	for _, k := range keys {
+		fieldPath := fmt.Sprintf("%v.%v", path, k)
+		if v, ok := declaredKeys[k]; !ok {
+			results = append(results, &FieldMatcherResult{
+				ObjectKeyPath:   fieldPath,
+				Error:           ExactExtraFieldErrMsg,
+				Status:          false,
+				ShowExtendedMsg: true,
+			})
+		} else if v != nil {
+			results = append(results, exactDiff(v, obj[k], fieldPath)...)
+		}
+	}
+	return results
+}
+
+// exactArrayDiff compares the elements of a response array against the items declared for it
+func exactArrayDiff(declared []interface{}, actual interface{}, path string) []*FieldMatcherResult {
+	elements, ok := actual.([]interface{})
+	if !ok {
+		return nil
+	}
+
+	var results []*FieldMatcherResult
+	for i, e := range elements {
+		elementPath := fmt.Sprintf("%v[%v]", path, i)
+		if i >= len(declared) {
+			results = append(results, &FieldMatcherResult{
+				ObjectKeyPath:   elementPath,
+				Error:           ExactExtraElementErrMsg,
+				Status:          false,
+				ShowExtendedMsg: true,
+			})
+			continue
+		}
+		results = append(results, exactDiff(declared[i], e, elementPath)...)
+	}
+	return results
+}
@@ -0,0 +1,101 @@
+package arp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestPruneDefinition(t *testing.T) {
+	declared := parseTestYaml(t, `
+id: 1
+createdAt: "2021"
+$.meta.requestId: abc
+tags:
+  - volatile
+  - stable
+data:
+  type: array
+  items:
+    - id: 1
+      updatedAt: "2021"
+    - id: 2
+      updatedAt: "2022"
+owner:
+  type: object
+  properties:
+    id: 3
+    lastSeen: "2021"
+any:
+  anyOf:
+    - lastSeen: "2021"
+`)
+	ignored, err := ParseIgnoredPaths([]string{"createdAt", "meta.requestId", "tags[0]", "data[*].updatedAt", "*.lastSeen"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := parseTestYaml(t, `
+id: 1
+$.meta.requestId: abc
+tags:
+  - stable
+data:
+  type: array
+  items:
+    - id: 1
+    - id: 2
+owner:
+  type: object
+  properties:
+    id: 3
+any:
+  anyOf:
+    - lastSeen: "2021"
+`)
+	if pruned := ignored.PruneDefinition(declared); !reflect.DeepEqual(pruned, expected) {
+		t.Errorf("expected:\n%v\nbut got:\n%v", ToJsonStr(YamlToJson(expected)), ToJsonStr(YamlToJson(pruned)))
+	}
+}
+
+func TestExactIgnoredElements(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		w.Write([]byte(`{"data": [{"generated": "x1"}, {"id": 2, "name": "b"}]}`))
+	}))
+	defer server.Close()
+
+	result := runTestFile(t, `
+tests:
+  - name: Exact
+    route: "@{host}"
+    method: GET
+    response:
+      code: 200
+      exact: true
+      ignore:
+        - data[0]
+      payload:
+        data:
+          type: array
+          length: 2
+          items:
+            - type: object
+              properties:
+                generated:
+                  type: string
+                  matches: $any
+            - type: object
+              properties:
+                id: 2
+                name: b
+`, server.URL, SuiteOptions{})
+
+	if len(result.Results) != 1 {
+		t.Fatalf("expected 1 result but got %v", len(result.Results))
+	}
+	if r := result.Results[0]; !r.Passed {
+		t.Errorf("expected the remaining elements to be compared against their own definitions:\n%v", failedFields(r))
+	}
+}
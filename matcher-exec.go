@@ -5,7 +5,6 @@ import (
 	"errors"
 	"fmt"
 	"os/exec"
-	"reflect"
 	"strings"
 )
 
@@ -65,6 +64,30 @@ func (m *ExecutableMatcher) Parse(parentNode interface{}, node map[interface{}]i
 	return m.ParseProps(node)
 }
 
+// resolveCommand resolves the variables in the path and arguments of a program. Arguments that resolve to anything other
+// than a string, such as objects stored from a response, are passed as JSON.
+func resolveCommand(datastore *DataStore, binPath string, args []string) (string, []string, error) {
+	resolvedBinPath, err := datastore.ExpandVariable(binPath)
+	if err != nil {
+		return "", nil, fmt.Errorf(BadVarMatcherFmt, binPath)
+	}
+
+	var resolvedArgs []string
+	for _, a := range args {
+		resolved, err := datastore.ExpandVariable(a)
+		if err != nil {
+			return "", nil, fmt.Errorf(BadVarMatcherFmt, a)
+		}
+		if s, isStr := resolved.(string); isStr {
+			resolvedArgs = append(resolvedArgs, s)
+		} else {
+			b, _ := json.Marshal(resolved)
+			resolvedArgs = append(resolvedArgs, string(b))
+		}
+	}
+	return varToString(resolvedBinPath, binPath), resolvedArgs, nil
+}
+
 func (m *ExecutableMatcher) Match(responseValue interface{}, datastore *DataStore) (bool, DataStore, error) {
 	store := NewDataStore()
 	m.ErrorStr = ""
@@ -81,35 +104,13 @@ func (m *ExecutableMatcher) Match(responseValue interface{}, datastore *DataStor
 	var status bool
 
 	if m.Cmd == "" {
-		resolvedBinPath, err := datastore.ExpandVariable(m.BinPath)
+		resolvedBinPath, argStrings, err := resolveCommand(datastore, m.BinPath, m.PrgmArgs)
 		if err != nil {
-			return false, store, fmt.Errorf(BadVarMatcherFmt, m.BinPath)
-		}
-
-		// resolve variables in the program
-		resolvedArgs, argErr := datastore.RecursiveResolveVariables(m.PrgmArgs)
-		if argErr != nil {
-			return false, store, fmt.Errorf(BadVarMatcherFmt, m.PrgmArgs)
-		}
-
-		argArray, aOk := resolvedArgs.([]interface{})
-		if !aOk {
-			m.ErrorStr = fmt.Sprintf(MismatchedMatcher, TYPE_ARRAY, reflect.TypeOf(resolvedArgs))
-			return false, store, nil
-		}
-
-		var argStrings []string
-		for _, aA := range argArray {
-			if s, isStr := aA.(string); isStr {
-				argStrings = append(argStrings, s)
-			} else {
-				b, _ := json.Marshal(aA)
-				argStrings = append(argStrings, string(b))
-			}
+			return false, store, err
 		}
 
 		status := true
-		cmd := exec.Command(resolvedBinPath.(string), argStrings...)
+		cmd := exec.Command(resolvedBinPath, argStrings...)
 
 		result, err := cmd.CombinedOutput()
 		sanitizedResult := string(result)
@@ -3,16 +3,57 @@ package arp
 import (
 	"errors"
 	"fmt"
+	"math"
 	"reflect"
 	"strconv"
 )
 
+const (
+	TEST_KEY_INTEGRAL     = "integral"
+	TEST_KEY_COERCE       = "coerce"
+	TEST_KEY_SAFE_INTEGER = "safeInteger"
+
+	// largest integer that JavaScript numbers can represent exactly (2^53 - 1)
+	MaxSafeInteger = 1<<53 - 1
+
+	IntegralErrFmt    = "Expected a whole number but got '%v'"
+	CoerceErrFmt      = "Expected a number or a string containing a number but got '%v'"
+	SafeIntegerErrFmt = "Expected a value within the safe integer range of ±%v but got '%v'"
+)
+
 type FloatMatcher struct {
-	Value   *float64
-	Pattern *string
+	Value     *float64
+	Pattern   *string
+	OneOf     *OneOf
+	OneOfFile *string
+	// fail when the number has a fractional part
+	Integral bool
+	// accept numbers encoded as strings (e.g. "3")
+	Coerce bool
+	// fail when the number can't be represented exactly by JavaScript clients
+	SafeInteger bool
 	FieldMatcherProps
 }
 
+// isSafeInteger returns whether a number is within the range of integers that JavaScript numbers can represent
+// exactly. Values decoded from JSON beyond this range may already have lost precision, but are still outside of it.
+func isSafeInteger(value float64) bool {
+	return math.Abs(value) <= MaxSafeInteger
+}
+
+// parseSafeInteger parses the 'safeInteger' option of a numeric matcher of the given type
+func parseSafeInteger(parentNode interface{}, node map[interface{}]interface{}, typeStr string) (bool, error) {
+	v, ok := node[TEST_KEY_SAFE_INTEGER]
+	if !ok {
+		return false, nil
+	}
+	safe, ok := v.(bool)
+	if !ok {
+		return false, errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_SAFE_INTEGER, typeStr), parentNode))
+	}
+	return safe, nil
+}
+
 func (m *FloatMatcher) Parse(parentNode interface{}, node map[interface{}]interface{}) error {
 	if v, ok := node[TEST_KEY_MATCHES]; ok {
 		switch val := v.(type) {
@@ -23,10 +64,30 @@ func (m *FloatMatcher) Parse(parentNode interface{}, node map[interface{}]interf
 			m.Value = &floatVal
 		case string:
 			m.Pattern = &val
+		case []interface{}:
+			// a list of allowed values, parsed by getOneOf
 		default:
 			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_MATCHES, TYPE_NUM), parentNode))
 		}
 	}
+	if v, ok := node[TEST_KEY_INTEGRAL]; ok {
+		if m.Integral, ok = v.(bool); !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_INTEGRAL, TYPE_NUM), parentNode))
+		}
+	}
+	if v, ok := node[TEST_KEY_COERCE]; ok {
+		if m.Coerce, ok = v.(bool); !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_COERCE, TYPE_NUM), parentNode))
+		}
+	}
+	var err error
+	if m.SafeInteger, err = parseSafeInteger(parentNode, node, TYPE_NUM); err != nil {
+		return err
+	}
+	if m.OneOf, err = getOneOf(parentNode, node, TYPE_NUM); err != nil {
+		return err
+	}
+	m.OneOfFile = getOneOfFile(node)
 	return m.ParseProps(node)
 }
 
@@ -38,11 +99,36 @@ func (m *FloatMatcher) Match(responseValue interface{}, datastore *DataStore) (b
 	var err error
 
 	typedResponseValue, ok := responseValue.(float64)
+	if str, isStr := responseValue.(string); isStr && m.Coerce {
+		parsed, pErr := strconv.ParseFloat(str, 64)
+		if pErr != nil {
+			m.ErrorStr = fmt.Sprintf(CoerceErrFmt, str)
+			return false, store, nil
+		}
+		typedResponseValue, ok = parsed, true
+	}
 	if !ok {
 		m.ErrorStr = fmt.Sprintf(MismatchedMatcher, TYPE_NUM, reflect.TypeOf(responseValue))
 		return false, store, nil
 	}
 
+	if m.Integral {
+		if typedResponseValue != math.Trunc(typedResponseValue) || math.IsInf(typedResponseValue, 0) {
+			m.ErrorStr = fmt.Sprintf(IntegralErrFmt, responseValue)
+			return false, store, nil
+		}
+		// whole numbers pass when nothing else is validated
+		status = m.Value == nil && m.Pattern == nil
+	}
+
+	if m.SafeInteger {
+		if !isSafeInteger(typedResponseValue) {
+			m.ErrorStr = fmt.Sprintf(SafeIntegerErrFmt, MaxSafeInteger, strconv.FormatFloat(typedResponseValue, 'f', -1, 64))
+			return false, store, nil
+		}
+		status = m.Value == nil && m.Pattern == nil
+	}
+
 	if m.Value != nil {
 		status = *m.Value == typedResponseValue
 		if !status {
@@ -67,12 +153,30 @@ func (m *FloatMatcher) Match(responseValue interface{}, datastore *DataStore) (b
 		}
 	}
 
-	if status {
+	// candidates are compared to the value formatted the same way, so that '2' allows 2.0
+	var oneOfMsg string
+	if m.OneOf != nil && (status || (m.Value == nil && m.Pattern == nil)) {
+		if status, oneOfMsg, err = m.OneOf.Match(varToString(typedResponseValue), datastore, &store); err != nil {
+			return false, store, err
+		}
+		m.ErrorStr = oneOfMsg
+	}
+
+	if m.OneOfFile != nil && (status || (m.Value == nil && m.Pattern == nil && m.OneOf == nil)) {
+		status, m.ErrorStr, err = matchOneOfFile(*m.OneOfFile, strconv.FormatFloat(typedResponseValue, 'f', -1, 64), datastore)
+		if err != nil {
+			return false, store, err
+		}
+	}
+
+	if status && m.OneOf != nil {
+		m.ErrorStr = oneOfMsg
+	} else if status {
 		m.ErrorStr = fmt.Sprintf("%v", typedResponseValue)
 	}
 
 	if status && m.DSName != "" {
-		err = store.PutVariable(m.DSName, responseValue)
+		err = store.PutVariable(m.DSName, typedResponseValue)
 	}
 
 	return status, store, err
@@ -0,0 +1,327 @@
+package arp
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	FORMAT_HEX_COLOR   = "hexcolor"
+	FORMAT_SEMVER      = "semver"
+	FORMAT_CREDIT_CARD = "creditcard"
+	FORMAT_ISBN        = "isbn"
+	FORMAT_BASE64_URL  = "base64url"
+	FORMAT_SLUG        = "slug"
+	FORMAT_CRON        = "cron"
+	FORMAT_HTTP_DATE   = "httpdate"
+	FORMAT_URL         = "url"
+
+	// url reachability keys
+	TEST_KEY_REACHABLE         = "reachable"
+	TEST_KEY_REACHABLE_TIMEOUT = "reachableTimeout"
+
+	DEFAULT_REACHABLE_TIMEOUT = 5 * time.Second
+
+	ReachableFmt       = "%v (HEAD %v)"
+	ReachableStatusFmt = "HEAD %v returned status %v"
+	ReachableFailedFmt = "HEAD %v failed: %v"
+
+	FormatErrFmt        = "Value '%v' is not a valid %v"
+	UnknownFormatErrFmt = "\nUnknown format '%v' detected on %v. Supported formats: %v"
+)
+
+var (
+	hexColorRegex = regexp.MustCompile(`^#([0-9a-fA-F]{3}|[0-9a-fA-F]{4}|[0-9a-fA-F]{6}|[0-9a-fA-F]{8})$`)
+	semverRegex   = regexp.MustCompile(`^(0|[1-9]\d*)\.(0|[1-9]\d*)\.(0|[1-9]\d*)` +
+		`(?:-((?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*)(?:\.(?:0|[1-9]\d*|\d*[a-zA-Z-][0-9a-zA-Z-]*))*))?` +
+		`(?:\+([0-9a-zA-Z-]+(?:\.[0-9a-zA-Z-]+)*))?$`)
+	slugRegex      = regexp.MustCompile(`^[a-z0-9]+(?:-[a-z0-9]+)*$`)
+	base64URLRegex = regexp.MustCompile(`^[A-Za-z0-9_-]*={0,2}$`)
+
+	// Named validators available to the string matcher 'format' key
+	formatValidators = map[string]func(value string) bool{
+		FORMAT_HEX_COLOR:   hexColorRegex.MatchString,
+		FORMAT_SEMVER:      semverRegex.MatchString,
+		FORMAT_CREDIT_CARD: isCreditCard,
+		FORMAT_ISBN:        isISBN,
+		FORMAT_BASE64_URL:  isBase64URL,
+		FORMAT_SLUG:        slugRegex.MatchString,
+		FORMAT_CRON:        isCron,
+		FORMAT_HTTP_DATE:   isHTTPDate,
+		FORMAT_URL:         isURL,
+	}
+
+	// Validators that can describe why a value is invalid, used to extend the error of the matching format
+	formatDetails = map[string]func(value string) error{
+		FORMAT_CRON:      validateCron,
+		FORMAT_HTTP_DATE: validateHTTPDate,
+		FORMAT_URL:       validateURL,
+	}
+
+	cronMonthNames = map[string]int{"JAN": 1, "FEB": 2, "MAR": 3, "APR": 4, "MAY": 5, "JUN": 6, "JUL": 7, "AUG": 8,
+		"SEP": 9, "OCT": 10, "NOV": 11, "DEC": 12}
+	cronDayNames = map[string]int{"SUN": 0, "MON": 1, "TUE": 2, "WED": 3, "THU": 4, "FRI": 5, "SAT": 6}
+
+	cronMacros = map[string]bool{"@yearly": true, "@annually": true, "@monthly": true, "@weekly": true,
+		"@daily": true, "@midnight": true, "@hourly": true}
+)
+
+func supportedFormats() string {
+	var names []string
+	for name := range formatValidators {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return strings.Join(names, ", ")
+}
+
+// stripSeparators removes the spaces and dashes commonly used to group digits in card numbers and ISBNs
+func stripSeparators(value string) string {
+	return strings.NewReplacer(" ", "", "-", "").Replace(value)
+}
+
+// isCreditCard validates a card number's length and Luhn checksum
+func isCreditCard(value string) bool {
+	digits := stripSeparators(value)
+	if len(digits) < 12 || len(digits) > 19 {
+		return false
+	}
+
+	sum := 0
+	double := false
+	for i := len(digits) - 1; i >= 0; i-- {
+		c := digits[i]
+		if c < '0' || c > '9' {
+			return false
+		}
+		d := int(c - '0')
+		if double {
+			d *= 2
+			if d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		double = !double
+	}
+	return sum%10 == 0
+}
+
+// isISBN validates either an ISBN-10 or ISBN-13 including its check digit
+func isISBN(value string) bool {
+	digits := stripSeparators(value)
+	switch len(digits) {
+	case 10:
+		sum := 0
+		for i := 0; i < 10; i++ {
+			c := digits[i]
+			var d int
+			if c >= '0' && c <= '9' {
+				d = int(c - '0')
+			} else if i == 9 && (c == 'X' || c == 'x') {
+				d = 10
+			} else {
+				return false
+			}
+			sum += d * (10 - i)
+		}
+		return sum%11 == 0
+	case 13:
+		sum := 0
+		for i := 0; i < 13; i++ {
+			c := digits[i]
+			if c < '0' || c > '9' {
+				return false
+			}
+			d := int(c - '0')
+			if i%2 == 1 {
+				d *= 3
+			}
+			sum += d
+		}
+		return sum%10 == 0
+	}
+	return false
+}
+
+// isBase64URL validates the URL safe base64 alphabet, with or without padding
+func isBase64URL(value string) bool {
+	if !base64URLRegex.MatchString(value) {
+		return false
+	}
+	if strings.HasSuffix(value, "=") {
+		_, err := base64.URLEncoding.DecodeString(value)
+		return err == nil
+	}
+	_, err := base64.RawURLEncoding.DecodeString(value)
+	return err == nil
+}
+
+// cronField describes the allowed values of a single field of a cron expression
+type cronField struct {
+	Name  string
+	Min   int
+	Max   int
+	Names map[string]int
+	// whether '?' may be used in place of '*', as with the day fields
+	AllowAny bool
+}
+
+var (
+	cronFields = []cronField{
+		{Name: "minute", Min: 0, Max: 59},
+		{Name: "hour", Min: 0, Max: 23},
+		{Name: "day of month", Min: 1, Max: 31, AllowAny: true},
+		{Name: "month", Min: 1, Max: 12, Names: cronMonthNames},
+		// both 0 and 7 are sunday
+		{Name: "day of week", Min: 0, Max: 7, Names: cronDayNames, AllowAny: true},
+	}
+	cronSecondField = cronField{Name: "second", Min: 0, Max: 59}
+)
+
+func isHTTPDate(value string) bool {
+	return validateHTTPDate(value) == nil
+}
+
+// validateHTTPDate validates a date in any of the formats allowed by HTTP (e.g. 'Mon, 02 Jan 2006 15:04:05 GMT')
+func validateHTTPDate(value string) error {
+	_, err := http.ParseTime(value)
+	return err
+}
+
+func isURL(value string) bool {
+	return validateURL(value) == nil
+}
+
+// validateURL validates an absolute URL with a scheme and host (e.g. 'https://example.com/file.zip')
+func validateURL(value string) error {
+	u, err := url.Parse(value)
+	if err != nil {
+		return err
+	}
+	if u.Scheme == "" || u.Host == "" {
+		return fmt.Errorf("missing scheme or host")
+	}
+	return nil
+}
+
+// checkReachable sends a HEAD request to a URL, failing unless it responds with a 2xx status within the timeout.
+// Redirects are followed. The returned message includes the status of the response.
+func checkReachable(value string, timeout time.Duration) (bool, string) {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodHead, value, nil)
+	if err != nil {
+		return false, fmt.Sprintf(ReachableFailedFmt, value, err)
+	}
+	client := http.Client{}
+	defer client.CloseIdleConnections()
+	response, err := client.Do(request)
+	if err != nil {
+		return false, fmt.Sprintf(ReachableFailedFmt, value, err)
+	}
+	response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return false, fmt.Sprintf(ReachableStatusFmt, value, response.StatusCode)
+	}
+	return true, fmt.Sprintf(ReachableFmt, value, response.StatusCode)
+}
+
+func isCron(value string) bool {
+	return validateCron(value) == nil
+}
+
+// validateCron validates a 5 field (minute to day of week) or 6 field (with leading seconds) cron expression, or one
+// of the '@daily' style macros including '@every <duration>'. The error names the field that is invalid.
+func validateCron(value string) error {
+	expr := strings.TrimSpace(value)
+	if strings.HasPrefix(expr, "@") {
+		if strings.HasPrefix(expr, "@every ") {
+			d, err := time.ParseDuration(strings.TrimSpace(strings.TrimPrefix(expr, "@every ")))
+			if err != nil || d <= 0 {
+				return fmt.Errorf("invalid '@every' duration")
+			}
+			return nil
+		}
+		if !cronMacros[expr] {
+			return fmt.Errorf("unknown macro '%v'", expr)
+		}
+		return nil
+	}
+
+	parts := strings.Fields(expr)
+	fields := cronFields
+	switch len(parts) {
+	case 5:
+	case 6:
+		fields = append([]cronField{cronSecondField}, cronFields...)
+	default:
+		return fmt.Errorf("expected 5 or 6 fields but got %v", len(parts))
+	}
+
+	for i, part := range parts {
+		if err := fields[i].validate(part); err != nil {
+			return fmt.Errorf("invalid %v field '%v': %v", fields[i].Name, part, err)
+		}
+	}
+	return nil
+}
+
+// validate checks a comma separated list of values, ranges and steps (e.g. '1-5', '*/15', 'MON-FRI')
+func (f cronField) validate(field string) error {
+	for _, item := range strings.Split(field, ",") {
+		rangePart := item
+		if i := strings.Index(item, "/"); i >= 0 {
+			rangePart = item[:i]
+			step, err := strconv.Atoi(item[i+1:])
+			if err != nil || step < 1 {
+				return fmt.Errorf("invalid step '%v'", item[i+1:])
+			}
+		}
+
+		if rangePart == "*" || (rangePart == "?" && f.AllowAny) {
+			continue
+		}
+
+		bounds := strings.SplitN(rangePart, "-", 2)
+		low, err := f.value(bounds[0])
+		if err != nil {
+			return err
+		}
+		if len(bounds) == 2 {
+			high, err := f.value(bounds[1])
+			if err != nil {
+				return err
+			}
+			if low > high {
+				return fmt.Errorf("range '%v' starts after it ends", rangePart)
+			}
+		}
+	}
+	return nil
+}
+
+// value parses a single number or name of a field and checks it is within the allowed range
+func (f cronField) value(s string) (int, error) {
+	if v, ok := f.Names[strings.ToUpper(s)]; ok {
+		return v, nil
+	}
+	v, err := strconv.Atoi(s)
+	if err != nil {
+		return 0, fmt.Errorf("'%v' is not a number", s)
+	}
+	if v < f.Min || v > f.Max {
+		return 0, fmt.Errorf("%v is out of range %v-%v", v, f.Min, f.Max)
+	}
+	return v, nil
+}
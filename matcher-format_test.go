@@ -0,0 +1,145 @@
+package arp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestFormatValidators(t *testing.T) {
+	tests := []struct {
+		format string
+		value  string
+		valid  bool
+	}{
+		{FORMAT_HEX_COLOR, "#fff", true},
+		{FORMAT_HEX_COLOR, "#FFFA", true},
+		{FORMAT_HEX_COLOR, "#1a2b3c", true},
+		{FORMAT_HEX_COLOR, "#1a2b3c4d", true},
+		{FORMAT_HEX_COLOR, "1a2b3c", false},
+		{FORMAT_HEX_COLOR, "#1a2b3", false},
+		{FORMAT_HEX_COLOR, "#ggg", false},
+
+		{FORMAT_SEMVER, "1.0.0", true},
+		{FORMAT_SEMVER, "1.2.3-beta.1+build.5", true},
+		{FORMAT_SEMVER, "1.2", false},
+		{FORMAT_SEMVER, "01.2.3", false},
+		{FORMAT_SEMVER, "v1.2.3", false},
+
+		{FORMAT_CREDIT_CARD, "4111111111111111", true},
+		{FORMAT_CREDIT_CARD, "4111 1111 1111 1111", true},
+		{FORMAT_CREDIT_CARD, "5500-0000-0000-0004", true},
+		{FORMAT_CREDIT_CARD, "4111111111111112", false},
+		{FORMAT_CREDIT_CARD, "41111111111", false},
+		{FORMAT_CREDIT_CARD, "4111a11111111111", false},
+
+		{FORMAT_ISBN, "0-306-40615-2", true},
+		{FORMAT_ISBN, "080442957X", true},
+		{FORMAT_ISBN, "978-0-306-40615-7", true},
+		{FORMAT_ISBN, "0-306-40615-3", false},
+		{FORMAT_ISBN, "978-0-306-40615-8", false},
+		{FORMAT_ISBN, "X804429570", false},
+
+		{FORMAT_BASE64_URL, "", true},
+		{FORMAT_BASE64_URL, "aGk_LQ", true},
+		{FORMAT_BASE64_URL, "aGk_LQ==", true},
+		{FORMAT_BASE64_URL, "aGk/LQ==", false},
+		{FORMAT_BASE64_URL, "aGk_LQ=", false},
+		{FORMAT_BASE64_URL, "a", false},
+
+		{FORMAT_SLUG, "release-notes-2021", true},
+		{FORMAT_SLUG, "release", true},
+		{FORMAT_SLUG, "Release-Notes", false},
+		{FORMAT_SLUG, "release--notes", false},
+		{FORMAT_SLUG, "-release", false},
+	}
+
+	for _, tt := range tests {
+		if valid := formatValidators[tt.format](tt.value); valid != tt.valid {
+			t.Errorf("expected '%v' to be a valid %v: %v", tt.value, tt.format, tt.valid)
+		}
+	}
+}
+
+func TestStringFormat(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		response   string
+		passed     bool
+	}{
+		{"valid format", "version:\n  type: string\n  format: semver", `{"version": "1.0.0"}`, true},
+		{"invalid format", "version:\n  type: string\n  format: semver", `{"version": "1.0"}`, false},
+		{"format with a matching value", "version:\n  type: string\n  matches: '1\\..*'\n  format: semver", `{"version": "1.0.0"}`, true},
+		{"format with a failing value", "version:\n  type: string\n  matches: '2\\..*'\n  format: semver", `{"version": "1.0.0"}`, false},
+		{"value matching an invalid format", "version:\n  type: string\n  matches: '1\\..*'\n  format: semver", `{"version": "1.0"}`, false},
+	}
+
+	for _, tt := range tests {
+		matcher := loadTestMatcher(t, tt.definition, nil)
+		if passed, errs := matchTestJson(t, matcher, tt.response); passed != tt.passed {
+			t.Errorf("%v: expected the match to pass: %v but got: %v", tt.name, tt.passed, errs)
+		}
+	}
+}
+
+func TestStringFormatMalformed(t *testing.T) {
+	for _, definition := range []string{
+		"version:\n  type: string\n  format: version",
+		"version:\n  type: string\n  format: [semver]",
+	} {
+		def := parseTestYaml(t, definition)
+		matcher := NewResponseMatcher(nil)
+		if err := matcher.loadObjectFields(def, def, FieldMatcherPath{}); err == nil {
+			t.Errorf("expected the definition to be rejected:\n%v", definition)
+		}
+	}
+}
+
+func TestCronFormat(t *testing.T) {
+	tests := []struct {
+		value string
+		valid bool
+	}{
+		{"* * * * *", true},
+		{"*/15 0-6 1,15 * MON-FRI", true},
+		{"0 0 ? JAN-jun 7", true},
+		{"30 */5 * * * ?", true},
+		{"@daily", true},
+		{"@every 1h30m", true},
+		{"* * * *", false},
+		{"* * * * * * *", false},
+		{"60 * * * *", false},
+		{"* 24 * * *", false},
+		{"* * 0 * *", false},
+		{"* * * 13 *", false},
+		{"* * * * 8", false},
+		{"? * * * *", false},
+		{"*/0 * * * *", false},
+		{"5-1 * * * *", false},
+		{"* * * FOO *", false},
+		{"@fortnightly", false},
+		{"@every 0s", false},
+		{"@every soon", false},
+	}
+
+	for _, tt := range tests {
+		err := validateCron(tt.value)
+		if (err == nil) != tt.valid {
+			t.Errorf("expected '%v' to be a valid cron expression: %v but got %v", tt.value, tt.valid, err)
+		}
+		if isCron(tt.value) != tt.valid {
+			t.Errorf("expected isCron('%v') to be %v", tt.value, tt.valid)
+		}
+	}
+}
+
+func TestCronFormatError(t *testing.T) {
+	matcher := loadTestMatcher(t, "schedule:\n  type: string\n  format: cron", nil)
+	passed, errs := matchTestJson(t, matcher, `{"schedule": "0 25 * * *"}`)
+	if passed {
+		t.Fatal("expected the invalid hour to fail")
+	}
+	if !strings.Contains(errs, "invalid hour field '25'") {
+		t.Errorf("expected the error to name the invalid field but got: %v", errs)
+	}
+}
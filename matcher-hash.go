@@ -0,0 +1,177 @@
+package arp
+
+import (
+	"crypto/md5"
+	"crypto/sha1"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+const (
+	TEST_KEY_HASH_OF = "hashOf"
+
+	HASH_OF_KEY_PATH     = "path"
+	HASH_OF_KEY_FILE     = "file"
+	HASH_OF_KEY_ALGO     = "algo"
+	HASH_OF_KEY_ENCODING = "encoding"
+
+	HASH_ENCODING_HEX    = "hex"
+	HASH_ENCODING_BASE64 = "base64"
+
+	DEFAULT_HASH_ALGO = "sha256"
+
+	HashOfErrFmt        = "Expected '%v', the %v hash of %v, but got '%v'"
+	HashOfSuccessFmt    = "%v (%v hash of %v)"
+	HashOfMissingErrFmt = "Failed to find the field '%v' to hash"
+	HashOfFileErrFmt    = "Failed to read the file '%v' to hash: %v"
+)
+
+var (
+	// algorithms supported by 'hashOf'
+	hashAlgorithms = map[string]func() hash.Hash{
+		"md5":    md5.New,
+		"sha1":   sha1.New,
+		"sha256": sha256.New,
+		"sha512": sha512.New,
+	}
+)
+
+// HashOf validates that a string is the hash of another field of the response or of a local file, such as the
+// checksum of an uploaded file or a content addressed ID.
+type HashOf struct {
+	// path of the field within the response to hash
+	Path string
+	// path of the file to hash, relative to the directory of the test file
+	File     string
+	Algo     string
+	Encoding string
+	Root     interface{}
+}
+
+func supportedHashAlgorithms() string {
+	var algos []string
+	for a := range hashAlgorithms {
+		algos = append(algos, a)
+	}
+	sort.Strings(algos)
+	return strings.Join(algos, ", ")
+}
+
+func (h *HashOf) Parse(parentNode interface{}, node map[interface{}]interface{}) error {
+	def, ok := node[TEST_KEY_HASH_OF].(map[interface{}]interface{})
+	if !ok {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_HASH_OF, TYPE_STR), parentNode))
+	}
+
+	if path, ok := def[HASH_OF_KEY_PATH]; ok {
+		h.Path = strings.TrimPrefix(fmt.Sprintf("%v", path), FIELD_KEY_PREFIX)
+	}
+	if file, ok := def[HASH_OF_KEY_FILE]; ok {
+		h.File = fmt.Sprintf("%v", file)
+	}
+	// exactly one source is hashed
+	if (h.Path == "") == (h.File == "") {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_HASH_OF, TYPE_STR), parentNode))
+	}
+
+	h.Algo = DEFAULT_HASH_ALGO
+	if algo, ok := def[HASH_OF_KEY_ALGO]; ok {
+		h.Algo = strings.ToLower(fmt.Sprintf("%v", algo))
+		if _, ok := hashAlgorithms[h.Algo]; !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf("unsupported '%v' algorithm '%v'. Supported algorithms are: %v",
+				TEST_KEY_HASH_OF, algo, supportedHashAlgorithms()), parentNode))
+		}
+	}
+
+	h.Encoding = HASH_ENCODING_HEX
+	if encoding, ok := def[HASH_OF_KEY_ENCODING]; ok {
+		h.Encoding = fmt.Sprintf("%v", encoding)
+		if h.Encoding != HASH_ENCODING_HEX && h.Encoding != HASH_ENCODING_BASE64 {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, HASH_OF_KEY_ENCODING, TYPE_STR), parentNode))
+		}
+	}
+	return nil
+}
+
+// source returns the bytes to hash along with a description of where they came from. Fields that aren't strings are
+// hashed as JSON.
+func (h *HashOf) source(datastore *DataStore) ([]byte, string, error) {
+	if h.File != "" {
+		resolved, err := datastore.ExpandVariable(h.File)
+		if err != nil {
+			return nil, "", fmt.Errorf(BadVarMatcherFmt, h.File)
+		}
+		path := varToString(resolved, h.File)
+		if !filepath.IsAbs(path) {
+			if testDir, ok := datastore.Get(DS_TEST_DIR).(string); ok {
+				path = filepath.Join(testDir, path)
+			}
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, "", fmt.Errorf(HashOfFileErrFmt, path, err)
+		}
+		return data, fmt.Sprintf("file '%v'", path), nil
+	}
+
+	resolved, err := datastore.ExpandVariable(h.Path)
+	if err != nil {
+		return nil, "", fmt.Errorf(BadVarMatcherFmt, h.Path)
+	}
+	path := varToString(resolved, h.Path)
+	root, _ := h.Root.(map[string]interface{})
+	value, err := GetJsonValue(root, path)
+	if err != nil || value == nil {
+		return nil, "", fmt.Errorf(HashOfMissingErrFmt, path)
+	}
+	if s, ok := value.(string); ok {
+		return []byte(s), fmt.Sprintf("field '%v'", path), nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to serialize the field '%v' to hash: %v", path, err)
+	}
+	return data, fmt.Sprintf("field '%v'", path), nil
+}
+
+// Validate returns whether the value is the hash of the source and a message reporting the computed hash. Hex
+// encoded hashes are compared case-insensitively.
+func (h *HashOf) Validate(value string, datastore *DataStore) (bool, string, error) {
+	data, sourceStr, err := h.source(datastore)
+	if err != nil {
+		return false, err.Error(), nil
+	}
+
+	hasher := hashAlgorithms[h.Algo]()
+	hasher.Write(data)
+	sum := hasher.Sum(nil)
+
+	var computed string
+	var status bool
+	if h.Encoding == HASH_ENCODING_BASE64 {
+		computed = base64.StdEncoding.EncodeToString(sum)
+		status = value == computed
+	} else {
+		computed = hex.EncodeToString(sum)
+		status = strings.EqualFold(value, computed)
+	}
+
+	if !status {
+		return false, fmt.Sprintf(HashOfErrFmt, computed, h.Algo, sourceStr, value), nil
+	}
+	return true, fmt.Sprintf(HashOfSuccessFmt, value, h.Algo, sourceStr), nil
+}
+
+func (h *HashOf) SetRoot(root interface{}) {
+	h.Root = root
+}
@@ -0,0 +1,152 @@
+package arp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"
+	_ "image/jpeg"
+	_ "image/png"
+	"reflect"
+	"strings"
+)
+
+const (
+	TYPE_IMAGE = "image"
+
+	TEST_KEY_WIDTH  = "width"
+	TEST_KEY_HEIGHT = "height"
+
+	ImageDecodeErrFmt    = "Value is not a base64 encoded image: %v"
+	ImageFormatErrFmt    = "Expected image format '%v' but got '%v' instead"
+	ImageDimensionErrFmt = "Expected image %v of %v but got %v instead"
+)
+
+// ImageMatcher validates a base64 encoded png, jpeg or gif image (optionally as a data URI), along with its format
+// and dimensions. Dimensions can be exact values or numeric expressions (e.g. '$>= 100').
+type ImageMatcher struct {
+	Format string
+	Width  interface{}
+	Height interface{}
+	FieldMatcherProps
+}
+
+func (m *ImageMatcher) Parse(parentNode interface{}, node map[interface{}]interface{}) error {
+	if v, ok := node[TEST_KEY_FORMAT]; ok {
+		m.Format = strings.ToLower(fmt.Sprintf("%v", v))
+		if m.Format == "jpg" {
+			m.Format = "jpeg"
+		}
+	}
+
+	for key, dest := range map[string]*interface{}{TEST_KEY_WIDTH: &m.Width, TEST_KEY_HEIGHT: &m.Height} {
+		v, ok := node[key]
+		if !ok {
+			continue
+		}
+		switch v.(type) {
+		case int, string:
+			*dest = v
+		default:
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, key, TYPE_IMAGE), parentNode))
+		}
+	}
+	return m.ParseProps(node)
+}
+
+// decodeImageConfig reads the format and dimensions of a base64 encoded image. Any data URI prefix
+// (e.g. 'data:image/png;base64,') is ignored.
+func decodeImageConfig(value string) (image.Config, string, error) {
+	if strings.HasPrefix(value, "data:") {
+		if i := strings.Index(value, ","); i >= 0 {
+			value = value[i+1:]
+		}
+	}
+
+	data, err := base64.StdEncoding.DecodeString(strings.TrimSpace(value))
+	if err != nil {
+		return image.Config{}, "", err
+	}
+	return image.DecodeConfig(bytes.NewReader(data))
+}
+
+// matchDimension compares an image dimension against an exact value or numeric expression
+func matchDimension(name string, expected interface{}, actual int, datastore *DataStore) (bool, string, error) {
+	if expected == nil {
+		return true, "", nil
+	}
+
+	if exact, ok := expected.(int); ok {
+		if exact != actual {
+			return false, fmt.Sprintf(ImageDimensionErrFmt, name, exact, actual), nil
+		}
+		return true, "", nil
+	}
+
+	exprStr := expected.(string)
+	resolved, err := datastore.ExpandVariable(exprStr)
+	if err != nil {
+		return false, "", fmt.Errorf(BadVarMatcherFmt, exprStr)
+	}
+	resolvedStr := varToString(resolved, exprStr)
+	if resolvedStr == Any {
+		return true, "", nil
+	}
+
+	status, evaluated, msg, err := evaluateNumExpr(resolvedStr, int64(actual))
+	if err != nil {
+		return false, "", err
+	}
+	if !evaluated {
+		if resolvedStr != fmt.Sprintf("%v", actual) {
+			return false, fmt.Sprintf(ImageDimensionErrFmt, name, resolvedStr, actual), nil
+		}
+		return true, "", nil
+	}
+	if !status {
+		return false, fmt.Sprintf("[%v] %v", name, msg), nil
+	}
+	return true, "", nil
+}
+
+func (m *ImageMatcher) Match(responseValue interface{}, datastore *DataStore) (bool, DataStore, error) {
+	store := NewDataStore()
+	m.ErrorStr = ""
+
+	typedResponseValue, ok := responseValue.(string)
+	if !ok {
+		m.ErrorStr = fmt.Sprintf(MismatchedMatcher, TYPE_STR, reflect.TypeOf(responseValue))
+		return false, store, nil
+	}
+
+	config, format, err := decodeImageConfig(typedResponseValue)
+	if err != nil {
+		m.ErrorStr = fmt.Sprintf(ImageDecodeErrFmt, err)
+		return false, store, nil
+	}
+
+	if m.Format != "" && m.Format != format {
+		m.ErrorStr = fmt.Sprintf(ImageFormatErrFmt, m.Format, format)
+		return false, store, nil
+	}
+
+	for _, d := range []struct {
+		Name     string
+		Expected interface{}
+		Actual   int
+	}{{TEST_KEY_WIDTH, m.Width, config.Width}, {TEST_KEY_HEIGHT, m.Height, config.Height}} {
+		status, msg, err := matchDimension(d.Name, d.Expected, d.Actual, datastore)
+		if err != nil || !status {
+			m.ErrorStr = msg
+			return false, store, err
+		}
+	}
+
+	m.ErrorStr = fmt.Sprintf("%v %vx%v", format, config.Width, config.Height)
+	if m.DSName != "" {
+		err = store.PutVariable(m.DSName, typedResponseValue)
+	}
+	return true, store, err
+}
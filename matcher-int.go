@@ -5,14 +5,34 @@ import (
 	"fmt"
 	"reflect"
 	"strconv"
+	"strings"
+)
+
+const (
+	StatusClassErrFmt = "Expected a value in class '%vxx' but got '%v' instead"
 )
 
 type IntegerMatcher struct {
 	Value   *int64
 	Pattern *string
+	// Class of values to match, e.g. 2 will match any value from 200 to 299 when written as '2xx'
+	Class     *int64
+	OneOf     *OneOf
+	OneOfFile *string
+	// fail when the value can't be represented exactly by JavaScript clients
+	SafeInteger bool
 	FieldMatcherProps
 }
 
+// parseStatusClass parses a status class string such as '2xx' or '4XX' into its leading digit
+func parseStatusClass(input string) (int64, bool) {
+	s := strings.ToLower(strings.TrimSpace(input))
+	if len(s) != 3 || !strings.HasSuffix(s, "xx") || s[0] < '1' || s[0] > '9' {
+		return 0, false
+	}
+	return int64(s[0] - '0'), true
+}
+
 func (m *IntegerMatcher) Parse(parentNode interface{}, node map[interface{}]interface{}) error {
 	if v, ok := node[TEST_KEY_MATCHES]; ok {
 		switch val := v.(type) {
@@ -23,11 +43,26 @@ func (m *IntegerMatcher) Parse(parentNode interface{}, node map[interface{}]inte
 			intVal := int64(val)
 			m.Value = &intVal
 		case string:
-			m.Pattern = &val
+			if class, ok := parseStatusClass(val); ok {
+				m.Class = &class
+			} else {
+				m.Pattern = &val
+			}
+		case []interface{}:
+			// a list of allowed values, parsed by getOneOf
 		default:
 			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_MATCHES, TYPE_INT), parentNode))
 		}
 	}
+	oneOf, err := getOneOf(parentNode, node, TYPE_INT)
+	if err != nil {
+		return err
+	}
+	m.OneOf = oneOf
+	m.OneOfFile = getOneOfFile(node)
+	if m.SafeInteger, err = parseSafeInteger(parentNode, node, TYPE_INT); err != nil {
+		return err
+	}
 	return m.ParseProps(node)
 }
 
@@ -50,11 +85,29 @@ func (m *IntegerMatcher) Match(responseValue interface{}, datastore *DataStore)
 		return false, store, nil
 	}
 
+	if m.SafeInteger {
+		// check the value before it was truncated, as values beyond the range of int64 saturate
+		if f, ok := responseValue.(float64); ok && !isSafeInteger(f) {
+			m.ErrorStr = fmt.Sprintf(SafeIntegerErrFmt, MaxSafeInteger, strconv.FormatFloat(f, 'f', -1, 64))
+			return false, store, nil
+		} else if !ok && (typedResponseValue > MaxSafeInteger || typedResponseValue < -MaxSafeInteger) {
+			m.ErrorStr = fmt.Sprintf(SafeIntegerErrFmt, MaxSafeInteger, typedResponseValue)
+			return false, store, nil
+		}
+		// safe integers pass when nothing else is validated
+		status = true
+	}
+
 	if m.Value != nil {
 		status = *m.Value == typedResponseValue
 		if !status {
 			m.ErrorStr = fmt.Sprintf(ValueErrFmt, *m.Value, typedResponseValue)
 		}
+	} else if m.Class != nil {
+		status = typedResponseValue/100 == *m.Class
+		if !status {
+			m.ErrorStr = fmt.Sprintf(StatusClassErrFmt, *m.Class, typedResponseValue)
+		}
 	} else if m.Pattern != nil {
 		resolved, err := (*datastore).ExpandVariable(*m.Pattern)
 		if err != nil {
@@ -77,7 +130,25 @@ func (m *IntegerMatcher) Match(responseValue interface{}, datastore *DataStore)
 		}
 	}
 
-	if status {
+	var oneOfMsg string
+	if m.OneOf != nil && (status || (m.Value == nil && m.Pattern == nil && m.Class == nil)) {
+		status, oneOfMsg, err = m.OneOf.Match(strconv.FormatInt(typedResponseValue, 10), datastore, &store)
+		if err != nil {
+			return false, store, err
+		}
+		m.ErrorStr = oneOfMsg
+	}
+
+	if m.OneOfFile != nil && (status || (m.Value == nil && m.Pattern == nil && m.Class == nil && m.OneOf == nil)) {
+		status, m.ErrorStr, err = matchOneOfFile(*m.OneOfFile, strconv.FormatInt(typedResponseValue, 10), datastore)
+		if err != nil {
+			return false, store, err
+		}
+	}
+
+	if status && m.OneOf != nil {
+		m.ErrorStr = oneOfMsg
+	} else if status {
 		m.ErrorStr = fmt.Sprintf("%d", int64(typedResponseValue))
 	}
 
@@ -0,0 +1,188 @@
+package arp
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	OneOfFileErrFmt  = "Value '%v' is not listed in file: %v"
+	OneOfErrFmt      = "Expected one of %v but got '%v' instead"
+	OneOfLabelErrFmt = "got %v (%v); allowed: %v"
+	UnknownLabel     = "unknown"
+)
+
+var (
+	// lists loaded by 'oneOfFile' matchers are shared across every matcher in a run
+	oneOfFileCache = struct {
+		sync.Mutex
+		Lists map[string]map[string]bool
+	}{Lists: make(map[string]map[string]bool)}
+)
+
+// OneOf validates that a value is one of a list of candidates, optionally storing the zero-based index of the
+// matched candidate in the data store. Labels map candidates to a human readable name shown in the report.
+type OneOf struct {
+	Values       []interface{}
+	StoreIndexAs string
+	Labels       map[string]string
+}
+
+// getOneOf parses the 'oneOf', 'storeIndexAs' and 'labels' keys of a matcher definition, returning nil if 'oneOf'
+// isn't defined. A list of values for 'matches' (e.g. 'matches: [active, pending]') is a short form of 'oneOf'.
+func getOneOf(parentNode interface{}, node map[interface{}]interface{}, matcherType string) (*OneOf, error) {
+	v, ok := node[TEST_KEY_ONE_OF]
+	if list, isList := node[TEST_KEY_MATCHES].([]interface{}); isList {
+		if ok {
+			return nil, errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_MATCHES, matcherType), parentNode))
+		}
+		if len(list) == 0 {
+			return nil, errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_MATCHES, matcherType), parentNode))
+		}
+		v, ok = list, true
+	}
+	if !ok {
+		_, hasIndex := node[TEST_KEY_STORE_INDEX]
+		_, hasLabels := node[TEST_KEY_LABELS]
+		if hasIndex || hasLabels {
+			return nil, errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_ONE_OF, matcherType), parentNode))
+		}
+		return nil, nil
+	}
+
+	values, ok := v.([]interface{})
+	if !ok || len(values) == 0 {
+		return nil, errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_ONE_OF, matcherType), parentNode))
+	}
+
+	oneOf := &OneOf{Values: values}
+	if name, ok := node[TEST_KEY_STORE_INDEX]; ok {
+		oneOf.StoreIndexAs = fmt.Sprintf("%v", name)
+	}
+	if l, ok := node[TEST_KEY_LABELS]; ok {
+		labels, ok := l.(map[interface{}]interface{})
+		if !ok {
+			return nil, errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_LABELS, matcherType), parentNode))
+		}
+		oneOf.Labels = make(map[string]string)
+		for k, v := range labels {
+			oneOf.Labels[varToString(k)] = varToString(v)
+		}
+	}
+	return oneOf, nil
+}
+
+// Match compares the value against the string representation of each candidate, after resolving any variables.
+// When matched, the index of the candidate is stored if configured.
+func (o *OneOf) Match(value string, datastore *DataStore, store *DataStore) (bool, string, error) {
+	var candidates []string
+	for _, v := range o.Values {
+		candidate := varToString(v)
+		if s, ok := v.(string); ok {
+			resolved, err := datastore.ExpandVariable(s)
+			if err != nil {
+				return false, "", fmt.Errorf(BadVarMatcherFmt, s)
+			}
+			candidate = varToString(resolved, s)
+		}
+		candidates = append(candidates, candidate)
+	}
+
+	for i, candidate := range candidates {
+		if candidate != value {
+			continue
+		}
+		if o.StoreIndexAs != "" {
+			if err := store.PutVariable(o.StoreIndexAs, i); err != nil {
+				return false, "", err
+			}
+		}
+		if label, ok := o.Labels[value]; ok {
+			return true, fmt.Sprintf("%v (%v, index %v)", value, label, i), nil
+		}
+		return true, fmt.Sprintf("%v (index %v)", value, i), nil
+	}
+
+	if o.Labels != nil {
+		var allowed []string
+		for _, candidate := range candidates {
+			if label, ok := o.Labels[candidate]; ok {
+				candidate = fmt.Sprintf("%v(%v)", candidate, label)
+			}
+			allowed = append(allowed, candidate)
+		}
+		label, ok := o.Labels[value]
+		if !ok {
+			label = UnknownLabel
+		}
+		return false, fmt.Sprintf(OneOfLabelErrFmt, value, label, strings.Join(allowed, ",")), nil
+	}
+	return false, fmt.Sprintf(OneOfErrFmt, strings.Join(candidates, ", "), value), nil
+}
+
+// loadListFile reads a YAML list or newline separated file into a set of its values. Blank lines and
+// lines starting with '#' are ignored in newline separated files.
+func loadListFile(path string) (map[string]bool, error) {
+	oneOfFileCache.Lock()
+	defer oneOfFileCache.Unlock()
+
+	if list, ok := oneOfFileCache.Lists[path]; ok {
+		return list, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read list file: %v - %v", path, err)
+	}
+
+	list := make(map[string]bool)
+	var yamlList []interface{}
+	if err := yaml.Unmarshal(data, &yamlList); err == nil && yamlList != nil {
+		for _, v := range yamlList {
+			list[varToString(v)] = true
+		}
+	} else {
+		for _, line := range strings.Split(string(data), "\n") {
+			line = strings.TrimSpace(line)
+			if line == "" || strings.HasPrefix(line, "#") {
+				continue
+			}
+			list[line] = true
+		}
+	}
+
+	oneOfFileCache.Lists[path] = list
+	return list, nil
+}
+
+// matchOneOfFile validates that a value is listed within a file. The file path can contain variables and
+// relative paths are resolved against the directory of the test file.
+func matchOneOfFile(pathStr string, value string, datastore *DataStore) (bool, string, error) {
+	resolved, err := datastore.ExpandVariable(pathStr)
+	if err != nil {
+		return false, "", fmt.Errorf(BadVarMatcherFmt, pathStr)
+	}
+
+	path := varToString(resolved, pathStr)
+	if !filepath.IsAbs(path) {
+		if testDir, ok := datastore.Get(DS_TEST_DIR).(string); ok {
+			path = filepath.Join(testDir, path)
+		}
+	}
+
+	list, err := loadListFile(path)
+	if err != nil {
+		return false, "", err
+	}
+
+	if !list[value] {
+		return false, fmt.Sprintf(OneOfFileErrFmt, value, path), nil
+	}
+	return true, "", nil
+}
@@ -0,0 +1,99 @@
+package arp
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestMatchesList(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		response   string
+		status     bool
+		err        string
+	}{
+		{"string allowed", "state: {type: string, matches: [active, pending]}", `{"state": "pending"}`, true, ""},
+		{"string not allowed", "state: {type: string, matches: [active, pending]}", `{"state": "deleted"}`, false,
+			"Expected one of active, pending but got 'deleted' instead"},
+		{"integer allowed", "code: {type: integer, matches: [1, 2, 3]}", `{"code": 3}`, true, ""},
+		{"integer not allowed", "code: {type: integer, matches: [1, 2]}", `{"code": 3}`, false,
+			"Expected one of 1, 2 but got '3' instead"},
+		{"number allowed", "ratio: {type: number, matches: [0.5, 1.5]}", `{"ratio": 1.5}`, true, ""},
+		{"whole number allowed", "ratio: {type: number, matches: [1, 2]}", `{"ratio": 2.0}`, true, ""},
+		{"number not allowed", "ratio: {type: number, matches: [1, 2.5]}", `{"ratio": 1.5}`, false,
+			"Expected one of 1, 2.5 but got '1.5' instead"},
+		{"number oneOf with labels", "ratio: {type: number, oneOf: [1, 2], labels: {1: half, 2: full}}", `{"ratio": 3}`,
+			false, "got 3 (unknown); allowed: 1(half),2(full)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := loadTestMatcher(t, tt.definition, nil)
+			if len(matcher.Warnings) > 0 {
+				t.Errorf("unexpected warnings: %v", matcher.Warnings)
+			}
+			status, errs := matchTestJson(t, matcher, tt.response)
+			if status != tt.status {
+				t.Errorf("expected status %v but got %v (%v)", tt.status, status, errs)
+			}
+			if !strings.Contains(errs, tt.err) {
+				t.Errorf("expected error '%v' but got '%v'", tt.err, errs)
+			}
+		})
+	}
+}
+
+func TestMatchesListStoresIndex(t *testing.T) {
+	matcher := loadTestMatcher(t, "code: {type: number, matches: [1, 2, 3], storeIndexAs: codeIndex}", nil)
+	if status, errs := matchTestJson(t, matcher, `{"code": 2}`); !status {
+		t.Fatalf("expected the value to be allowed: %v", errs)
+	}
+	if v := matcher.DS.Get("codeIndex"); v != 1 {
+		t.Errorf("expected the stored index to be 1 but got %v", v)
+	}
+}
+
+func TestMatchesListMalformed(t *testing.T) {
+	for _, def := range []string{
+		"state: {type: string, matches: []}",
+		"state: {type: string, matches: [a], oneOf: [b]}",
+		"code: {type: integer, matches: []}",
+		"ratio: {type: number, matches: []}",
+	} {
+		parsed := parseTestYaml(t, def)
+		matcher := NewResponseMatcher(nil)
+		if err := matcher.loadObjectFields(parsed, parsed, FieldMatcherPath{}); err == nil {
+			t.Errorf("expected '%v' to be malformed", def)
+		}
+	}
+}
+
+func TestOneOfLabels(t *testing.T) {
+	tests := []struct {
+		name       string
+		definition string
+		response   string
+		status     bool
+		err        string
+	}{
+		{"integer labeled", "status: {type: integer, oneOf: [1, 2], labels: {1: active, 2: closed}}", `{"status": 7}`, false,
+			"got 7 (unknown); allowed: 1(active),2(closed)"},
+		{"string labeled", "tier: {type: string, oneOf: [f, p], labels: {f: free, p: premium}}", `{"tier": "p"}`, true, ""},
+		{"string unknown", "tier: {type: string, oneOf: [f, p], labels: {f: free, p: premium}}", `{"tier": "x"}`, false,
+			"got x (unknown); allowed: f(free),p(premium)"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			matcher := loadTestMatcher(t, tt.definition, nil)
+			if len(matcher.Warnings) > 0 {
+				t.Errorf("unexpected warnings: %v", matcher.Warnings)
+			}
+			status, errs := matchTestJson(t, matcher, tt.response)
+			if status != tt.status || !strings.Contains(errs, tt.err) {
+				t.Errorf("expected status %v with '%v' but got %v with '%v'", tt.status, tt.err, status, errs)
+			}
+		})
+	}
+}
@@ -4,14 +4,96 @@ import (
 	"errors"
 	"fmt"
 	"reflect"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	TEST_KEY_DISCRIMINATOR       = "discriminator"
+	TEST_KEY_DISCRIMINATOR_FIELD = "field"
+	TEST_KEY_DISCRIMINATOR_MAP   = "mapping"
+
+	TEST_KEY_KEY_PATTERN = "keyPattern"
+	TEST_KEY_DEEP        = "deep"
+
+	KeyPatternErrFmt = "Keys not matching pattern '%v': %v"
+
+	DiscriminatorMissingErrFmt  = "Discriminator field '%v' was not found"
+	DiscriminatorUnmappedErrFmt = "Discriminator field '%v' has unmapped value '%v'; expected one of: %v"
 )
 
 type ObjectMatcher struct {
-	Properties map[interface{}]interface{}
-	Sorted     bool
+	Properties    map[interface{}]interface{}
+	Sorted        bool
+	Discriminator *ObjectDiscriminator
+	// every key of the object must match the pattern, including the keys of nested objects when deep
+	KeyPattern *regexp.Regexp
+	Deep       bool
 	FieldMatcherProps
 }
 
+// ObjectDiscriminator validates polymorphic objects by selecting the properties to validate an object against based
+// on the value of one of its fields.
+type ObjectDiscriminator struct {
+	Field    string
+	Mapping  map[string]map[interface{}]interface{}
+	Matchers map[string]*ResponseMatcher
+	Results  []*FieldMatcherResult
+}
+
+func (d *ObjectDiscriminator) Parse(parentNode interface{}, node interface{}) error {
+	discriminatorNode, ok := node.(map[interface{}]interface{})
+	if !ok {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_DISCRIMINATOR, TYPE_OBJ), parentNode))
+	}
+
+	field, fOk := discriminatorNode[TEST_KEY_DISCRIMINATOR_FIELD]
+	mapping, mOk := discriminatorNode[TEST_KEY_DISCRIMINATOR_MAP].(map[interface{}]interface{})
+	if !fOk || !mOk || len(mapping) == 0 {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_DISCRIMINATOR, TYPE_OBJ), parentNode))
+	}
+	d.Field = fmt.Sprintf("%v", field)
+
+	d.Mapping = make(map[string]map[interface{}]interface{})
+	for k, v := range mapping {
+		properties, ok := v.(map[interface{}]interface{})
+		if !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_DISCRIMINATOR_MAP, TEST_KEY_DISCRIMINATOR), parentNode))
+		}
+		d.Mapping[varToString(k)] = properties
+	}
+	return nil
+}
+
+// Validate selects the properties mapped to the value of the discriminator field and matches the object against
+// them. Values stored by the properties are put directly into the data store.
+func (d *ObjectDiscriminator) Validate(object map[string]interface{}, datastore *DataStore) (bool, string, error) {
+	d.Results = nil
+
+	value, err := GetJsonValue(object, d.Field)
+	if err != nil || value == nil {
+		return false, fmt.Sprintf(DiscriminatorMissingErrFmt, d.Field), nil
+	}
+
+	key := varToString(value)
+	matcher, ok := d.Matchers[key]
+	if !ok {
+		var expected []string
+		for k := range d.Mapping {
+			expected = append(expected, k)
+		}
+		sort.Strings(expected)
+		return false, fmt.Sprintf(DiscriminatorUnmappedErrFmt, d.Field, key, strings.Join(expected, ", ")), nil
+	}
+
+	matcher.DS = datastore
+	matcher.NodeCache = NodeCache{Cache: make(map[string]NodeCacheObj)}
+	passed, results, err := matcher.Match(object)
+	d.Results = results
+	return passed, fmt.Sprintf("[%v] %v = %v", TEST_KEY_DISCRIMINATOR, d.Field, key), err
+}
+
 func (m *ObjectMatcher) Parse(parentNode interface{}, node map[interface{}]interface{}) error {
 	if node[TEST_KEY_PROPERTIES] != nil {
 		if properties, ok := node[TEST_KEY_PROPERTIES].(map[interface{}]interface{}); ok {
@@ -21,6 +103,26 @@ func (m *ObjectMatcher) Parse(parentNode interface{}, node map[interface{}]inter
 		}
 	}
 
+	if v, ok := node[TEST_KEY_KEY_PATTERN]; ok {
+		pattern, err := regexp.Compile(fmt.Sprintf("%v", v))
+		if err != nil {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_KEY_PATTERN, TYPE_OBJ), parentNode))
+		}
+		m.KeyPattern = pattern
+		if deep, ok := node[TEST_KEY_DEEP]; ok {
+			if m.Deep, ok = deep.(bool); !ok {
+				return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_DEEP, TYPE_OBJ), parentNode))
+			}
+		}
+	}
+
+	if v, ok := node[TEST_KEY_DISCRIMINATOR]; ok {
+		m.Discriminator = &ObjectDiscriminator{}
+		if err := m.Discriminator.Parse(parentNode, v); err != nil {
+			return err
+		}
+	}
+
 	return m.ParseProps(node)
 }
 
@@ -38,6 +140,23 @@ func (m *ObjectMatcher) Match(responseValue interface{}, datastore *DataStore) (
 	}
 
 	m.ErrorStr = "{}"
+	if m.Discriminator != nil {
+		m.Discriminator.Results = nil
+	}
+
+	if m.KeyPattern != nil {
+		if invalid := invalidKeys(m.KeyPattern, typedResponseValue, "", m.Deep); len(invalid) > 0 {
+			m.ErrorStr = fmt.Sprintf(KeyPatternErrFmt, m.KeyPattern, strings.Join(invalid, ", "))
+			return false, store, nil
+		}
+	}
+
+	if m.Discriminator != nil {
+		var status bool
+		if status, m.ErrorStr, err = m.Discriminator.Validate(typedResponseValue, datastore); err != nil || !status {
+			return false, store, err
+		}
+	}
 
 	if m.DSName != "" {
 		err = store.PutVariable(m.DSName, typedResponseValue)
@@ -45,3 +164,42 @@ func (m *ObjectMatcher) Match(responseValue interface{}, datastore *DataStore) (
 
 	return true, store, err
 }
+
+// invalidKeys returns the sorted paths of the keys within an object that don't match the pattern. When deep, the keys
+// of nested objects, including those within arrays, are checked as well.
+func invalidKeys(pattern *regexp.Regexp, node interface{}, path string, deep bool) []string {
+	var invalid []string
+	switch n := node.(type) {
+	case map[string]interface{}:
+		var keys []string
+		for k := range n {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+
+		for _, k := range keys {
+			keyPath := k
+			if path != "" {
+				keyPath = path + "." + k
+			}
+			if !pattern.MatchString(k) {
+				invalid = append(invalid, keyPath)
+			}
+			if deep {
+				invalid = append(invalid, invalidKeys(pattern, n[k], keyPath, deep)...)
+			}
+		}
+	case []interface{}:
+		for i, e := range n {
+			invalid = append(invalid, invalidKeys(pattern, e, fmt.Sprintf("%v[%v]", path, i), deep)...)
+		}
+	}
+	return invalid
+}
+
+func (m *ObjectMatcher) NestedResults() []*FieldMatcherResult {
+	if m.Discriminator == nil {
+		return nil
+	}
+	return m.Discriminator.Results
+}
@@ -3,11 +3,28 @@ package arp
 import (
 	"errors"
 	"fmt"
+	"net/http"
 	"reflect"
+	"strings"
+	"time"
+)
+
+const (
+	// pattern the value must not match, e.g. to make sure error messages don't leak file paths
+	TEST_KEY_NOT_MATCHES = "notMatches"
 )
 
 type StringMatcher struct {
-	Value *string
+	Value     *string
+	NotValue  *string
+	OneOf     *OneOf
+	OneOfFile *string
+	Format    *string
+	Window    *TimeWindow
+	Bounds    *TimeBounds
+	HashOf    *HashOf
+	// timeout of the HEAD request checking that a URL is reachable. Zero when reachability isn't checked.
+	ReachableTimeout time.Duration
 	FieldMatcherProps
 }
 
@@ -16,10 +33,80 @@ func (m *StringMatcher) Parse(parentNode interface{}, node map[interface{}]inter
 		switch val := v.(type) {
 		case string:
 			m.Value = &val
+		case []interface{}:
+			// a list of allowed values, parsed by getOneOf
 		default:
 			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_MATCHES, TYPE_STR), parentNode))
 		}
 	}
+	if v, ok := node[TEST_KEY_NOT_MATCHES]; ok {
+		val, ok := v.(string)
+		if !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_NOT_MATCHES, TYPE_STR), parentNode))
+		}
+		m.NotValue = &val
+	}
+	oneOf, err := getOneOf(parentNode, node, TYPE_STR)
+	if err != nil {
+		return err
+	}
+	m.OneOf = oneOf
+	m.OneOfFile = getOneOfFile(node)
+	if v, ok := node[TEST_KEY_FORMAT]; ok {
+		format, ok := v.(string)
+		if !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_FORMAT, TYPE_STR), parentNode))
+		}
+		if _, ok := formatValidators[format]; !ok {
+			return errors.New(ObjectPrintf(fmt.Sprintf(UnknownFormatErrFmt, format, TYPE_STR, supportedFormats()), parentNode))
+		}
+		m.Format = &format
+	}
+	before, hasBefore := node[TEST_KEY_BEFORE]
+	after, hasAfter := node[TEST_KEY_AFTER]
+	if hasBefore || hasAfter {
+		// bounds are only supported for HTTP dates, which are parsed by the format validation
+		if m.Format == nil || *m.Format != FORMAT_HTTP_DATE {
+			key := TEST_KEY_BEFORE
+			if !hasBefore {
+				key = TEST_KEY_AFTER
+			}
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, key, TYPE_STR), parentNode))
+		}
+		m.Bounds = &TimeBounds{}
+		if hasBefore {
+			m.Bounds.Before = fmt.Sprintf("%v", before)
+		}
+		if hasAfter {
+			m.Bounds.After = fmt.Sprintf("%v", after)
+		}
+	}
+	if v, ok := node[TEST_KEY_REACHABLE]; ok && v != false {
+		// reachability is only checked for values validated as URLs
+		if v != true || m.Format == nil || *m.Format != FORMAT_URL {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_REACHABLE, TYPE_STR), parentNode))
+		}
+		m.ReachableTimeout = DEFAULT_REACHABLE_TIMEOUT
+		if t, ok := node[TEST_KEY_REACHABLE_TIMEOUT]; ok {
+			timeout, err := time.ParseDuration(fmt.Sprintf("%v", t))
+			if err != nil || timeout <= 0 {
+				return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_REACHABLE_TIMEOUT, TYPE_STR), parentNode))
+			}
+			m.ReachableTimeout = timeout
+		}
+	}
+	if _, ok := node[TEST_KEY_WITHIN_OF]; ok {
+		m.Window = &TimeWindow{}
+		if err := m.Window.Parse(parentNode, node); err != nil {
+			return err
+		}
+	}
+	if _, ok := node[TEST_KEY_HASH_OF]; ok {
+		m.HashOf = &HashOf{}
+		if err := m.HashOf.Parse(parentNode, node); err != nil {
+			return err
+		}
+	}
 
 	return m.ParseProps(node)
 }
@@ -51,6 +138,10 @@ func (m *StringMatcher) Match(responseValue interface{}, datastore *DataStore) (
 				m.ErrorStr = fmt.Sprintf(NotEmptyErrFmt, typedResponseValue)
 			}
 		default:
+			if strings.HasPrefix(resolvedStr, NotPattern) {
+				status = m.matchNotPattern(strings.TrimPrefix(resolvedStr, NotPattern), typedResponseValue)
+				break
+			}
 			status, _ = matchPattern(resolvedStr, []byte(typedResponseValue))
 			if !status {
 				m.ErrorStr = fmt.Sprintf(PatternErrFmt, typedResponseValue, resolvedStr)
@@ -58,15 +149,114 @@ func (m *StringMatcher) Match(responseValue interface{}, datastore *DataStore) (
 		}
 	}
 
-	if status {
+	var oneOfMsg string
+	if m.OneOf != nil && (status || m.Value == nil) {
+		if status, oneOfMsg, err = m.OneOf.Match(typedResponseValue, datastore, &store); err != nil {
+			return false, store, err
+		}
+		m.ErrorStr = oneOfMsg
+	}
+
+	if m.OneOfFile != nil && (status || (m.Value == nil && m.OneOf == nil)) {
+		status, m.ErrorStr, err = matchOneOfFile(*m.OneOfFile, typedResponseValue, datastore)
+		if err != nil {
+			return false, store, err
+		}
+	}
+
+	if m.Format != nil && (status || (m.Value == nil && m.OneOf == nil && m.OneOfFile == nil)) {
+		status = formatValidators[*m.Format](typedResponseValue)
+		if !status {
+			m.ErrorStr = fmt.Sprintf(FormatErrFmt, typedResponseValue, *m.Format)
+			if details, ok := formatDetails[*m.Format]; ok {
+				if err := details(typedResponseValue); err != nil {
+					m.ErrorStr = fmt.Sprintf("%v: %v", m.ErrorStr, err)
+				}
+			}
+		}
+	}
+
+	var reachableMsg string
+	if m.ReachableTimeout > 0 && status {
+		status, reachableMsg = checkReachable(typedResponseValue, m.ReachableTimeout)
+		m.ErrorStr = reachableMsg
+	}
+
+	// HTTP dates are stored as RFC3339 timestamps so that they can be compared by later tests
+	var storedValue interface{} = responseValue
+	if m.Format != nil && *m.Format == FORMAT_HTTP_DATE && status {
+		parsed, _ := http.ParseTime(typedResponseValue)
+		storedValue = parsed.UTC().Format(time.RFC3339)
+		if m.Bounds != nil {
+			if status, m.ErrorStr, err = m.Bounds.Validate(typedResponseValue, parsed, datastore); err != nil {
+				return false, store, err
+			}
+		}
+	}
+
+	if m.Window != nil && (status || (m.Value == nil && m.OneOf == nil && m.OneOfFile == nil && m.Format == nil)) {
+		var windowMsg string
+		if status, windowMsg, err = m.Window.Validate(typedResponseValue, datastore); err != nil {
+			return false, store, err
+		}
+		m.ErrorStr = windowMsg
+	}
+
+	var hashMsg string
+	if m.HashOf != nil && (status || (m.Value == nil && m.OneOf == nil && m.OneOfFile == nil && m.Format == nil && m.Window == nil)) {
+		if status, hashMsg, err = m.HashOf.Validate(typedResponseValue, datastore); err != nil {
+			return false, store, err
+		}
+		m.ErrorStr = hashMsg
+	}
+
+	if m.NotValue != nil && (status || (m.Value == nil && m.OneOf == nil && m.OneOfFile == nil && m.Format == nil && m.Window == nil && m.HashOf == nil)) {
+		resolved, err := (*datastore).ExpandVariable(*m.NotValue)
+		if err != nil {
+			return false, store, fmt.Errorf(BadVarMatcherFmt, *m.NotValue)
+		}
+		status = m.matchNotPattern(varToString(resolved, *m.NotValue), typedResponseValue)
+	}
+
+	if status && m.OneOf != nil {
+		m.ErrorStr = oneOfMsg
+	} else if status && reachableMsg != "" {
+		m.ErrorStr = reachableMsg
+	} else if status && hashMsg != "" {
+		m.ErrorStr = hashMsg
+	} else if status && m.Window == nil {
 		m.ErrorStr = typedResponseValue
 	}
 	if status && m.DSName != "" {
-		err = store.PutVariable(m.DSName, responseValue)
+		err = store.PutVariable(m.DSName, storedValue)
 	}
 	return status, store, err
 }
 
+// matchNotPattern returns whether the value doesn't match the pattern. Invalid patterns fail rather than being
+// treated as not matching.
+func (m *StringMatcher) matchNotPattern(pattern string, value string) bool {
+	matched, err := matchPattern(pattern, []byte(value))
+	if err != nil {
+		m.ErrorStr = fmt.Sprintf(InvalidPatternErrFmt, pattern, err)
+		return false
+	}
+	if matched {
+		m.ErrorStr = fmt.Sprintf(NotPatternErrFmt, value, pattern)
+	}
+	return !matched
+}
+
+func (m *StringMatcher) SetRoot(root interface{}) {
+	if m.HashOf != nil {
+		m.HashOf.SetRoot(root)
+	}
+}
+
 func (m *StringMatcher) SetError(error string) {
+	if m.Value == nil {
+		m.FieldMatcherProps.SetError(error)
+		return
+	}
 	m.ErrorStr = fmt.Sprintf("%v (matching '%v')", error, *m.Value)
 }
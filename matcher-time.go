@@ -0,0 +1,157 @@
+package arp
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	TEST_KEY_WITHIN_OF = "withinOf"
+	TEST_KEY_WINDOW    = "window"
+	TEST_KEY_SKEW      = "skew"
+	TEST_KEY_LAYOUT    = "layout"
+	TEST_KEY_BEFORE    = "before"
+	TEST_KEY_AFTER     = "after"
+
+	WITHIN_OF_NOW = "now"
+
+	TimeParseErrFmt  = "Failed to parse '%v' as a timestamp"
+	TimeWindowErrFmt = "Expected a timestamp within %v of %v but '%v' is %v"
+	TimeBoundErrFmt  = "Expected a date %v %v (%v) but got '%v'"
+)
+
+var (
+	// layouts attempted when a time window doesn't specify one
+	defaultTimeLayouts = []string{time.RFC3339Nano, time.RFC1123Z, time.RFC1123, "2006-01-02 15:04:05", "2006-01-02T15:04:05"}
+)
+
+// TimeWindow validates that a timestamp falls within a window before a reference time. The skew allows for
+// differences between the clocks of arp and the server in either direction.
+type TimeWindow struct {
+	WithinOf string
+	Window   time.Duration
+	Skew     time.Duration
+	Layout   string
+}
+
+func (w *TimeWindow) Parse(parentNode interface{}, node map[interface{}]interface{}) error {
+	w.WithinOf = fmt.Sprintf("%v", node[TEST_KEY_WITHIN_OF])
+
+	window, ok := node[TEST_KEY_WINDOW]
+	if !ok {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_WINDOW, TYPE_STR), parentNode))
+	}
+
+	var err error
+	if w.Window, err = time.ParseDuration(fmt.Sprintf("%v", window)); err != nil {
+		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_WINDOW, TYPE_STR), parentNode))
+	}
+
+	if skew, ok := node[TEST_KEY_SKEW]; ok {
+		if w.Skew, err = time.ParseDuration(fmt.Sprintf("%v", skew)); err != nil {
+			return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_SKEW, TYPE_STR), parentNode))
+		}
+	}
+
+	if layout, ok := node[TEST_KEY_LAYOUT]; ok {
+		w.Layout = fmt.Sprintf("%v", layout)
+	}
+	return nil
+}
+
+// parseTime parses a timestamp with the window's layout or any of the default layouts. Timestamps without a time
+// zone are treated as UTC.
+func (w *TimeWindow) parseTime(value string) (time.Time, error) {
+	layouts := defaultTimeLayouts
+	if w.Layout != "" {
+		layouts = []string{w.Layout}
+	}
+
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t.UTC(), nil
+		}
+	}
+	return time.Time{}, fmt.Errorf(TimeParseErrFmt, value)
+}
+
+// Validate returns whether the timestamp is within the window and a message describing its offset from the
+// reference time.
+func (w *TimeWindow) Validate(value string, datastore *DataStore) (bool, string, error) {
+	actual, err := w.parseTime(value)
+	if err != nil {
+		return false, err.Error(), nil
+	}
+
+	reference := time.Now().UTC()
+	if w.WithinOf != WITHIN_OF_NOW {
+		resolved, err := datastore.ExpandVariable(w.WithinOf)
+		if err != nil {
+			return false, "", fmt.Errorf(BadVarMatcherFmt, w.WithinOf)
+		}
+		if reference, err = w.parseTime(varToString(resolved, w.WithinOf)); err != nil {
+			return false, err.Error(), nil
+		}
+	}
+
+	offset := reference.Sub(actual)
+	offsetStr := fmt.Sprintf("%v before", offset.Round(time.Millisecond))
+	if offset < 0 {
+		offsetStr = fmt.Sprintf("%v after", (-offset).Round(time.Millisecond))
+	}
+
+	if offset > w.Window+w.Skew || offset < -w.Skew {
+		return false, fmt.Sprintf(TimeWindowErrFmt, w.Window, w.WithinOf, value, offsetStr), nil
+	}
+	return true, fmt.Sprintf("%v (%v %v)", value, offsetStr, w.WithinOf), nil
+}
+
+// TimeBounds validates that an HTTP date is before and/or after a reference time. References are either 'now', a
+// data store variable or a timestamp.
+type TimeBounds struct {
+	Before string
+	After  string
+}
+
+// resolveTimeBound resolves the reference time of a bound
+func resolveTimeBound(bound string, datastore *DataStore) (time.Time, error) {
+	if bound == WITHIN_OF_NOW {
+		return time.Now().UTC(), nil
+	}
+
+	resolved, err := datastore.ExpandVariable(bound)
+	if err != nil {
+		return time.Time{}, fmt.Errorf(BadVarMatcherFmt, bound)
+	}
+	value := varToString(resolved, bound)
+	if t, err := http.ParseTime(value); err == nil {
+		return t.UTC(), nil
+	}
+	window := TimeWindow{}
+	return window.parseTime(value)
+}
+
+// Validate returns whether a parsed date is within the bounds, along with a message describing the failed bound
+func (b *TimeBounds) Validate(value string, actual time.Time, datastore *DataStore) (bool, string, error) {
+	for _, bound := range []struct {
+		Name      string
+		Reference string
+	}{{TEST_KEY_BEFORE, b.Before}, {TEST_KEY_AFTER, b.After}} {
+		if bound.Reference == "" {
+			continue
+		}
+
+		reference, err := resolveTimeBound(bound.Reference, datastore)
+		if err != nil {
+			return false, "", err
+		}
+		if (bound.Name == TEST_KEY_BEFORE && !actual.Before(reference)) ||
+			(bound.Name == TEST_KEY_AFTER && !actual.After(reference)) {
+			return false, fmt.Sprintf(TimeBoundErrFmt, bound.Name, bound.Reference, reference.Format(time.RFC3339),
+				value), nil
+		}
+	}
+	return true, "", nil
+}
@@ -7,6 +7,7 @@ import (
 	"sort"
 	"strconv"
 	"strings"
+	"time"
 )
 
 const (
@@ -18,18 +19,33 @@ const (
 	GTE      = "$>="
 	EQ       = "$="
 
+	// prefix of patterns the value must not match
+	NotPattern = "$!"
+
 	FIELD_KEY_PREFIX = "$."
+	NUM_EXPR_DELIM   = ","
 
 	// special keywords used in validation object definitions
-	TEST_KEY_TYPE       = "type"
-	TEST_KEY_PROPERTIES = "properties"
-	TEST_KEY_LENGTH     = "length"
-	TEST_KEY_ITEMS      = "items"
-	TEST_KEY_SORTED     = "sorted"
-	TEST_KEY_STORE      = "storeAs"
-	TEST_KEY_PRIORITY   = "priority"
-	TEST_KEY_MATCHES    = "matches"
-	TEST_KEY_EXISTS     = "exists"
+	TEST_KEY_TYPE        = "type"
+	TEST_KEY_PROPERTIES  = "properties"
+	TEST_KEY_LENGTH      = "length"
+	TEST_KEY_ITEMS       = "items"
+	TEST_KEY_SORTED      = "sorted"
+	TEST_KEY_STORE       = "storeAs"
+	TEST_KEY_PRIORITY    = "priority"
+	TEST_KEY_MATCHES     = "matches"
+	TEST_KEY_EXISTS      = "exists"
+	TEST_KEY_MATCH_COUNT = "matchCount"
+	TEST_KEY_SEQUENCE    = "sequence"
+	TEST_KEY_ONE_OF_FILE = "oneOfFile"
+	TEST_KEY_ONE_OF      = "oneOf"
+	TEST_KEY_STORE_INDEX = "storeIndexAs"
+	TEST_KEY_LABELS      = "labels"
+	TEST_KEY_FORMAT      = "format"
+	TEST_KEY_FIND        = "find"
+	TEST_KEY_AGGREGATE   = "aggregate"
+	TEST_KEY_HOMOGENEOUS = "homogeneous"
+	TEST_KEY_SLICE       = "slice"
 
 	TEST_EXEC_KEY_RETURN_CODE = "returns"
 	TEST_EXEC_KEY_BIN_PATH    = "bin"
@@ -38,6 +54,8 @@ const (
 
 	ValueErrFmt            = "Expected value '%v' did not match the actual value '%v'"
 	PatternErrFmt          = "Failed to match actual value '%v' with expected pattern: '%v'"
+	NotPatternErrFmt       = "Expected value '%v' to NOT match pattern: '%v'"
+	InvalidPatternErrFmt   = "Invalid pattern '%v': %v"
 	NotEmptyErrFmt         = "Expected non-empty value, but got value '%v' instead."
 	ArrayLengthErrFmt      = "Expected array with length %v %v but found length %v instead."
 	ReceivedNullErrFmt     = "Received null value when non-null value was expected"
@@ -109,6 +127,14 @@ func (m *FieldMatcherProps) ValidateExistance(node interface{}) (bool, bool) {
 	return false, true
 }
 
+// FieldMatcher validates a single field of a response. Parse is called once with the matcher's definition when the test
+// is loaded and Match is called with the field's value every time the test is executed, after ValidateExistance
+// reports that the value should be matched. Match returns whether the value passed along with any values to put in
+// the data store, while errors are reserved for problems that prevent validating the value at all. The message
+// returned by Error is shown in the report for both passing and failing matches.
+//
+// Embedding FieldMatcherProps and calling its ParseProps from Parse provides the common 'storeAs', 'priority' and
+// 'exists' behavior.
 type FieldMatcher interface {
 	GetPriority() int
 	Parse(parentNode interface{}, node map[interface{}]interface{}) error
@@ -118,6 +144,18 @@ type FieldMatcher interface {
 	SetError(error string)
 }
 
+// NestedResultMatcher is implemented by matchers that validate nested fields themselves. The nested results are
+// reported alongside the matcher's own result with paths relative to the matcher's field.
+type NestedResultMatcher interface {
+	NestedResults() []*FieldMatcherResult
+}
+
+// RootAwareMatcher is implemented by matchers that compare against other fields of the response. The root of the
+// response being matched is provided before each match.
+type RootAwareMatcher interface {
+	SetRoot(root interface{})
+}
+
 type FieldMatcherKey struct {
 	Name    string
 	RealKey JsonKey
@@ -172,6 +210,8 @@ func (f *FieldMatcherPath) GetDisplayPath() string {
 type FieldMatcherConfig struct {
 	Matcher       FieldMatcher
 	ObjectKeyPath FieldMatcherPath
+	// Prefixed to the results of matchers that don't come from the test's own definitions, such as global assertions
+	Marker string
 }
 
 type FieldMatcherResult struct {
@@ -180,12 +220,16 @@ type FieldMatcherResult struct {
 	Error           string
 	ShowExtendedMsg bool
 	IgnoreResult    bool
+	// Time spent executing the matcher against its field
+	Duration time.Duration
 }
 
 type ResponseMatcher struct {
 	DS        *DataStore
 	Config    []*FieldMatcherConfig
 	NodeCache NodeCache
+	// Problems found in the matcher definitions that do not prevent them from loading (e.g. unknown keys)
+	Warnings []string
 }
 
 type ResponseMatcherResults struct {
@@ -259,6 +303,36 @@ func getDataStoreName(node map[interface{}]interface{}) string {
 	return ""
 }
 
+// loadResponseFields loads matchers for top level response properties (e.g. media type) where each field can
+// either be a full validation definition or a short form value. Nil fields are skipped.
+func (r *ResponseMatcher) loadResponseFields(fields map[string]interface{}) error {
+	for key, field := range fields {
+		if field == nil {
+			continue
+		}
+
+		keyPath := FieldMatcherPath{
+			Keys: []FieldMatcherKey{{Name: key, RealKey: JsonKey{Name: key}}},
+		}
+		if fieldMatcher, mOk := field.(map[interface{}]interface{}); mOk {
+			if err := r.loadField(field, fieldMatcher, keyPath); err != nil {
+				return err
+			}
+		} else if err := r.loadSimplifiedField(field, field, keyPath); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func getOneOfFile(node map[interface{}]interface{}) *string {
+	if v, ok := node[TEST_KEY_ONE_OF_FILE]; ok {
+		path := fmt.Sprintf("%v", v)
+		return &path
+	}
+	return nil
+}
+
 func getMatcherPriority(node map[interface{}]interface{}) int {
 	if v, ok := node[TEST_KEY_PRIORITY]; ok {
 		switch val := v.(type) {
@@ -275,6 +349,61 @@ func getMatcherPriority(node map[interface{}]interface{}) int {
 	return DEFAULT_PRIORITY
 }
 
+var (
+	// keys recognized by all matchers
+	commonMatcherKeys = []string{TEST_KEY_TYPE, TEST_KEY_STORE, TEST_KEY_PRIORITY, TEST_KEY_EXISTS, TEST_KEY_MATCH_COUNT}
+
+	// keys recognized by each matcher type
+	matcherKeys = map[string][]string{
+		TYPE_INT:   {TEST_KEY_MATCHES, TEST_KEY_ONE_OF_FILE, TEST_KEY_ONE_OF, TEST_KEY_STORE_INDEX, TEST_KEY_LABELS, TEST_KEY_SAFE_INTEGER},
+		TYPE_NUM:   {TEST_KEY_MATCHES, TEST_KEY_ONE_OF_FILE, TEST_KEY_ONE_OF, TEST_KEY_STORE_INDEX, TEST_KEY_LABELS, TEST_KEY_INTEGRAL, TEST_KEY_COERCE, TEST_KEY_SAFE_INTEGER},
+		TYPE_STR:   {TEST_KEY_MATCHES, TEST_KEY_NOT_MATCHES, TEST_KEY_ONE_OF_FILE, TEST_KEY_ONE_OF, TEST_KEY_STORE_INDEX, TEST_KEY_LABELS, TEST_KEY_FORMAT, TEST_KEY_WITHIN_OF, TEST_KEY_WINDOW, TEST_KEY_SKEW, TEST_KEY_LAYOUT, TEST_KEY_BEFORE, TEST_KEY_AFTER, TEST_KEY_REACHABLE, TEST_KEY_REACHABLE_TIMEOUT, TEST_KEY_HASH_OF},
+		TYPE_BOOL:  {TEST_KEY_MATCHES},
+		TYPE_ARRAY: {TEST_KEY_LENGTH, TEST_KEY_ITEMS, TEST_KEY_SORTED, TEST_KEY_SEQUENCE, TEST_KEY_ORDERED_BY, TEST_KEY_UNIQUE_BY, TEST_KEY_FIND, TEST_KEY_AGGREGATE, TEST_KEY_HOMOGENEOUS, TEST_KEY_ELEMENT_RANGE, TEST_KEY_CONTAINS, TEST_KEY_SLICE},
+		TYPE_OBJ:   {TEST_KEY_PROPERTIES, TEST_KEY_DISCRIMINATOR, TEST_KEY_KEY_PATTERN, TEST_KEY_DEEP},
+		TYPE_IMAGE: {TEST_KEY_FORMAT, TEST_KEY_WIDTH, TEST_KEY_HEIGHT},
+		TYPE_EXEC:  {TEST_EXEC_KEY_RETURN_CODE, TEST_EXEC_KEY_BIN_PATH, TEST_EXEC_KEY_ARGS, TEST_EXEC_KEY_CMD},
+		// allOf/anyOf definitions don't have a type of their own
+		"": {TEST_KEY_ALL_OF, TEST_KEY_ANY_OF},
+	}
+
+	// matchers registered with RegisterMatcher
+	customMatchers = map[string]func() FieldMatcher{}
+)
+
+// RegisterMatcher makes a custom matcher available to tests as 'type: <typeName>'. The factory is called for every
+// definition using the type. Built-in types can't be replaced, and registration must happen before test files are
+// loaded.
+func RegisterMatcher(typeName string, factory func() FieldMatcher) {
+	customMatchers[typeName] = factory
+}
+
+// unknownMatcherKeys returns any keys in a matcher definition that are not recognized by its matcher type.
+// These are otherwise silently ignored which usually hides a typo.
+func unknownMatcherKeys(typeStr string, node map[interface{}]interface{}) []string {
+	// the keys supported by custom matchers aren't known
+	if _, ok := customMatchers[typeStr]; ok {
+		return nil
+	}
+
+	var unknown []string
+	for k := range node {
+		key := fmt.Sprintf("%v", k)
+		known := false
+		for _, allowed := range append(commonMatcherKeys, matcherKeys[typeStr]...) {
+			if key == allowed {
+				known = true
+				break
+			}
+		}
+		if !known {
+			unknown = append(unknown, key)
+		}
+	}
+	sort.Strings(unknown)
+	return unknown
+}
+
 func handleExistence(node interface{}, exists bool, canBeNull bool) (bool, bool, string) {
 	if node == nil && exists && !canBeNull {
 		return false, false, ReceivedNullErrFmt
@@ -287,7 +416,47 @@ func handleExistence(node interface{}, exists bool, canBeNull bool) (bool, bool,
 	return false, true, ""
 }
 
+// evaluateNumExpr evaluates one or more comma separated numeric expressions (e.g. '$>= 10, $< 20') against a number.
+// All expressions must pass for the result to pass and the message of the first failing expression is returned.
 func evaluateNumExpr(exprStr string, number int64) (bool, bool, string, error) {
+	return evaluateFloatExpr(exprStr, float64(number))
+}
+
+// evaluateFloatExpr evaluates numeric expressions the same as evaluateNumExpr for values that aren't integers, such as
+// averages
+func evaluateFloatExpr(exprStr string, number float64) (bool, bool, string, error) {
+	exprs := strings.Split(exprStr, NUM_EXPR_DELIM)
+	// patterns such as '[0-9]{1,3}' can contain the delimiter too, so only split strings that start with an operator
+	if len(exprs) == 1 || !isNumExpr(strings.TrimSpace(exprs[0])) {
+		return evaluateSingleNumExpr(exprStr, number)
+	}
+
+	for _, e := range exprs {
+		status, evaluated, message, err := evaluateSingleNumExpr(strings.TrimSpace(e), number)
+		if err != nil {
+			return false, true, "", err
+		}
+		if !evaluated {
+			return false, true, "", fmt.Errorf("invalid numeric expression '%v' within: %v", strings.TrimSpace(e), exprStr)
+		}
+		if !status {
+			return false, true, message, nil
+		}
+	}
+
+	return true, true, "", nil
+}
+
+func isNumExpr(exprStr string) bool {
+	for _, op := range []string{GTE, LTE, GT, LT} {
+		if strings.HasPrefix(exprStr, op) {
+			return true
+		}
+	}
+	return false
+}
+
+func evaluateSingleNumExpr(exprStr string, number float64) (bool, bool, string, error) {
 	var err error
 	var status bool
 	var evaluated bool
@@ -296,8 +465,8 @@ func evaluateNumExpr(exprStr string, number int64) (bool, bool, string, error) {
 	for _, op := range []string{GTE, LTE, GT, LT} {
 		if strings.HasPrefix(exprStr, op) {
 			evaluated = true
-			var val int64
-			val, err = strconv.ParseInt(strings.TrimSpace(strings.ReplaceAll(exprStr, op, "")), 10, 32)
+			var val float64
+			val, err = strconv.ParseFloat(strings.TrimSpace(strings.TrimPrefix(exprStr, op)), 64)
 			if err != nil {
 				return false, evaluated, "", err
 			}
@@ -314,14 +483,21 @@ func evaluateNumExpr(exprStr string, number int64) (bool, bool, string, error) {
 
 			if !status {
 				op := strings.TrimPrefix(op, "$")
-				message = fmt.Sprintf(NumExpressionErrFmt, op, val, number)
+				message = fmt.Sprintf(NumExpressionErrFmt, op, formatNumber(val), formatNumber(number))
 			}
+			// shorter operators are prefixes of the longer ones, so stop at the first match
+			break
 		}
 	}
 
 	return status, evaluated, message, err
 }
 
+// formatNumber formats a number without an exponent so that large integers are displayed as they were written
+func formatNumber(number float64) string {
+	return strconv.FormatFloat(number, 'f', -1, 64)
+}
+
 func NewResponseMatcher(ds *DataStore) ResponseMatcher {
 	return ResponseMatcher{
 		DS: ds,
@@ -355,18 +531,78 @@ func (r *ResponseMatcher) AddMatcherConfig(config *FieldMatcherConfig) {
 
 // If the field matcher is defined as an object, we'll parse the data to create our matchers
 func (r *ResponseMatcher) loadField(parentNode interface{}, fieldNode map[interface{}]interface{}, paths FieldMatcherPath) error {
+	if isCompositeMatcher(fieldNode) {
+		return r.loadCompositeField(parentNode, fieldNode, paths)
+	}
+
 	// No 'simplified' version of objects since there is a possibility that our 'type' key used for parsing may collide with a 'type'
 	// field in the data structure that is unrelated to the test definition.
 	// This could be avoided by using some scoped key like '$arp_type' or something. Will need to collect feedback on what people prefer.
+	foundMatcher, typeStr, err := parseFieldMatcher(parentNode, fieldNode)
+	if err != nil {
+		return err
+	}
+	if _, ok := foundMatcher.(*ExecutableMatcher); ok {
+		paths.IsExecutable = true
+	}
+
+	for _, k := range unknownMatcherKeys(typeStr, fieldNode) {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("unknown key '%v' in %v matcher at '%v'", k, typeStr, paths.GetDisplayPath()))
+	}
+
+	if foundMatcher != nil {
+		r.AddMatcherConfig(&FieldMatcherConfig{
+			Matcher:       foundMatcher,
+			ObjectKeyPath: paths,
+		})
+
+		// visit array elements AFTER we have added the array to the config
+		switch val := foundMatcher.(type) {
+		case *ArrayMatcher:
+			if err := r.loadArrayFields(val, parentNode, val.Items, paths); err != nil {
+				return err
+			}
+			if val.Finder != nil {
+				val.Finder.Matcher = NewResponseMatcher(r.DS)
+				if err := val.Finder.Matcher.loadObjectFields(parentNode, val.Finder.Properties, FieldMatcherPath{}); err != nil {
+					return err
+				}
+				r.Warnings = append(r.Warnings, val.Finder.Matcher.Warnings...)
+			}
+		case *ObjectMatcher:
+			last := &paths.Keys[len(paths.Keys)-1]
+			last.RealKey.IsObject = true
+			if err := r.loadObjectFields(parentNode, val.Properties, paths); err != nil {
+				return err
+			}
+			if val.Discriminator != nil {
+				val.Discriminator.Matchers = make(map[string]*ResponseMatcher)
+				for k, properties := range val.Discriminator.Mapping {
+					matcher := NewResponseMatcher(r.DS)
+					if err := matcher.loadObjectFields(parentNode, properties, FieldMatcherPath{}); err != nil {
+						return err
+					}
+					r.Warnings = append(r.Warnings, matcher.Warnings...)
+					val.Discriminator.Matchers[k] = &matcher
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// parseFieldMatcher creates the matcher for a single validation definition based on its 'type' key.
+func parseFieldMatcher(parentNode interface{}, fieldNode map[interface{}]interface{}) (FieldMatcher, string, error) {
 	typeField, ok := fieldNode[TEST_KEY_TYPE]
 	if !ok {
-		return fmt.Errorf(ObjectPrintf(
+		return nil, "", fmt.Errorf(ObjectPrintf(
 			fmt.Sprintf("Failed to parse response validation. Missing field '%v'", TEST_KEY_TYPE), parentNode))
 	}
 
 	typeStr, ok := typeField.(string)
 	if !ok {
-		return fmt.Errorf(ObjectPrintf(
+		return nil, "", fmt.Errorf(ObjectPrintf(
 			fmt.Sprintf("Failed to parse response validation. Field '%v' must be a string", TEST_KEY_TYPE), parentNode))
 	}
 
@@ -375,72 +611,87 @@ func (r *ResponseMatcher) loadField(parentNode interface{}, fieldNode map[interf
 	case TYPE_INT:
 		intMatcher := &IntegerMatcher{}
 		if err := intMatcher.Parse(parentNode, fieldNode); err != nil {
-			return err
+			return nil, "", err
 		}
 		foundMatcher = intMatcher
 	case TYPE_NUM:
 		floatMatcher := &FloatMatcher{}
 		if err := floatMatcher.Parse(parentNode, fieldNode); err != nil {
-			return err
+			return nil, "", err
 		}
 		foundMatcher = floatMatcher
 	case TYPE_STR:
 		strMatcher := &StringMatcher{}
 		if err := strMatcher.Parse(parentNode, fieldNode); err != nil {
-			return err
+			return nil, "", err
 		}
 		foundMatcher = strMatcher
 	case TYPE_BOOL:
 		boolMatcher := &BoolMatcher{}
 		if err := boolMatcher.Parse(parentNode, fieldNode); err != nil {
-			return err
+			return nil, "", err
 		}
 		foundMatcher = boolMatcher
 	case TYPE_ARRAY:
 		arrayMatcher := &ArrayMatcher{}
 		if err := arrayMatcher.Parse(parentNode, fieldNode); err != nil {
-			return err
+			return nil, "", err
 		}
 		foundMatcher = arrayMatcher
 	case TYPE_OBJ:
 		objMatcher := &ObjectMatcher{}
 		if err := objMatcher.Parse(parentNode, fieldNode); err != nil {
-			return err
+			return nil, "", err
 		}
 		foundMatcher = objMatcher
+	case TYPE_IMAGE:
+		imageMatcher := &ImageMatcher{}
+		if err := imageMatcher.Parse(parentNode, fieldNode); err != nil {
+			return nil, "", err
+		}
+		foundMatcher = imageMatcher
 	case TYPE_EXEC:
 		execMatcher := &ExecutableMatcher{}
 		if err := execMatcher.Parse(parentNode, fieldNode); err != nil {
-			return err
+			return nil, "", err
 		}
 		foundMatcher = execMatcher
-		paths.IsExecutable = true
-
 	default:
-		return errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_TYPE, "definition"), fieldNode))
+		factory, ok := customMatchers[typeStr]
+		if !ok {
+			return nil, "", errors.New(ObjectPrintf(fmt.Sprintf(MalformedDefinitionFmt, TEST_KEY_TYPE, "definition"), fieldNode))
+		}
+		foundMatcher = factory()
+		if err := foundMatcher.Parse(parentNode, fieldNode); err != nil {
+			return nil, "", err
+		}
 	}
 
-	if foundMatcher != nil {
-		r.AddMatcherConfig(&FieldMatcherConfig{
-			Matcher:       foundMatcher,
-			ObjectKeyPath: paths,
-		})
+	return foundMatcher, typeStr, nil
+}
 
-		// visit array elements AFTER we have added the array to the config
-		switch val := foundMatcher.(type) {
-		case *ArrayMatcher:
-			if err := r.loadArrayFields(val, parentNode, val.Items, paths); err != nil {
-				return err
-			}
-		case *ObjectMatcher:
-			last := &paths.Keys[len(paths.Keys)-1]
-			last.RealKey.IsObject = true
-			if err := r.loadObjectFields(parentNode, val.Properties, paths); err != nil {
-				return err
-			}
+// loadCompositeField loads an 'allOf' or 'anyOf' definition which validates the same field with multiple matchers.
+func (r *ResponseMatcher) loadCompositeField(parentNode interface{}, fieldNode map[interface{}]interface{}, paths FieldMatcherPath) error {
+	compositeMatcher := &CompositeMatcher{}
+	if err := compositeMatcher.Parse(parentNode, fieldNode); err != nil {
+		return err
+	}
+
+	for _, k := range unknownMatcherKeys("", fieldNode) {
+		r.Warnings = append(r.Warnings, fmt.Sprintf("unknown key '%v' in %v matcher at '%v'", k, compositeMatcher.Key, paths.GetDisplayPath()))
+	}
+	_, definitions, _ := compositeDefinitions(parentNode, fieldNode)
+	for _, definition := range definitions {
+		typeStr, _ := definition[TEST_KEY_TYPE].(string)
+		for _, k := range unknownMatcherKeys(typeStr, definition) {
+			r.Warnings = append(r.Warnings, fmt.Sprintf("unknown key '%v' in %v matcher at '%v'", k, typeStr, paths.GetDisplayPath()))
 		}
 	}
 
+	r.AddMatcherConfig(&FieldMatcherConfig{
+		Matcher:       compositeMatcher,
+		ObjectKeyPath: paths,
+	})
 	return nil
 }
 
@@ -526,6 +777,15 @@ func (r *ResponseMatcher) loadSimplifiedField(parentNode interface{}, fieldNode
 
 func (r *ResponseMatcher) loadArrayFields(m *ArrayMatcher, parentNode interface{}, fields []interface{}, paths FieldMatcherPath) error {
 	for i, arrayNode := range fields {
+		// Items with a match count are validated against every element by the array matcher itself
+		// rather than searching for a single matching element.
+		if counter, err := r.loadArrayItemCounter(m, parentNode, arrayNode); err != nil {
+			return err
+		} else if counter != nil {
+			m.Counters = append(m.Counters, counter)
+			continue
+		}
+
 		var pathStack []FieldMatcherKey
 		pathStack = append(pathStack, paths.Keys...)
 
@@ -561,6 +821,41 @@ func (r *ResponseMatcher) loadArrayFields(m *ArrayMatcher, parentNode interface{
 	return nil
 }
 
+// loadArrayItemCounter creates a counter for an array item definition if the item or its parent array defines
+// a match count expression. Nil is returned if no counting is required.
+func (r *ResponseMatcher) loadArrayItemCounter(m *ArrayMatcher, parentNode interface{}, arrayNode interface{}) (*ArrayItemCounter, error) {
+	expr := m.MatchCount
+	fieldNode, isObj := arrayNode.(map[interface{}]interface{})
+	if isObj {
+		if v, ok := fieldNode[TEST_KEY_MATCH_COUNT]; ok {
+			expr = fmt.Sprintf("%v", v)
+		}
+	}
+
+	if expr == "" || arrayNode == nil {
+		return nil, nil
+	}
+
+	counter := &ArrayItemCounter{
+		Expr:    expr,
+		Matcher: NewResponseMatcher(r.DS),
+	}
+
+	paths := FieldMatcherPath{
+		Keys:   []FieldMatcherKey{{Name: ARRAY_COUNT_ITEM_KEY, RealKey: JsonKey{Name: ARRAY_COUNT_ITEM_KEY}}},
+		Sorted: true,
+	}
+
+	var err error
+	if isObj {
+		err = counter.Matcher.loadField(parentNode, fieldNode, paths)
+	} else {
+		err = counter.Matcher.loadSimplifiedField(parentNode, arrayNode, paths)
+	}
+	r.Warnings = append(r.Warnings, counter.Matcher.Warnings...)
+	return counter, err
+}
+
 func (r *ResponseMatcher) loadObjectFields(parentNode interface{}, fields map[interface{}]interface{}, paths FieldMatcherPath) error {
 
 	for k := range fields {
@@ -810,15 +1105,22 @@ func (r *ResponseMatcher) MatchConfig(matcher *FieldMatcherConfig, response inte
 	var status, passthrough bool
 	var err error
 	var ds DataStore
+	var duration time.Duration
+
+	if rootAware, ok := matcher.Matcher.(RootAwareMatcher); ok {
+		rootAware.SetRoot(response)
+	}
 
 	if status, passthrough = matcher.Matcher.ValidateExistance(node); passthrough {
+		start := time.Now()
 		status, ds, err = matcher.Matcher.Match(node, r.DS)
+		duration = time.Since(start)
 		if err != nil {
 			return ResponseMatcherResults{false, results, false, err}
 		}
 
 		for k := range ds.Store {
-			(*r.DS).Put(k, ds.Store[k])
+			(*r.DS).Capture(k, ds.Store[k])
 		}
 	}
 
@@ -833,8 +1135,22 @@ func (r *ResponseMatcher) MatchConfig(matcher *FieldMatcherConfig, response inte
 		// The only reason an object matcher exists is to add validation for root node existence, and the ability
 		// to save the result as a value.
 		IgnoreResult: isObjMatcher && status,
+		Duration:     duration,
 	})
 
+	if nested, ok := matcher.Matcher.(NestedResultMatcher); ok {
+		for _, n := range nested.NestedResults() {
+			n.ObjectKeyPath = matcher.ObjectKeyPath.GetDisplayPath() + n.ObjectKeyPath
+			results = append(results, n)
+		}
+	}
+
+	if matcher.Marker != "" {
+		for _, r := range results {
+			r.Error = matcher.Marker + r.Error
+		}
+	}
+
 	return ResponseMatcherResults{status, results, false, err}
 }
 
@@ -0,0 +1,226 @@
+package arp
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+
+	"gopkg.in/yaml.v2"
+)
+
+// parseTestYaml parses a YAML object as it is parsed from test files
+func parseTestYaml(t *testing.T, definition string) map[interface{}]interface{} {
+	t.Helper()
+	var def map[interface{}]interface{}
+	if err := yaml.Unmarshal([]byte(definition), &def); err != nil {
+		t.Fatalf("invalid definition: %v", err)
+	}
+	return def
+}
+
+// loadTestMatcher loads the matchers of a YAML payload definition
+func loadTestMatcher(t *testing.T, definition string, ds *DataStore) *ResponseMatcher {
+	t.Helper()
+	def := parseTestYaml(t, definition)
+	if ds == nil {
+		store := NewDataStore()
+		ds = &store
+	}
+	matcher := NewResponseMatcher(ds)
+	if err := matcher.loadObjectFields(def, def, FieldMatcherPath{}); err != nil {
+		t.Fatalf("failed to load definition: %v", err)
+	}
+	return &matcher
+}
+
+// matchTestJson matches a JSON response against a loaded matcher, returning the status and the joined errors of the
+// failing results
+func matchTestJson(t *testing.T, matcher *ResponseMatcher, response string) (bool, string) {
+	t.Helper()
+	var decoded interface{}
+	if err := json.Unmarshal([]byte(response), &decoded); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	status, results, err := matcher.Match(decoded)
+	if err != nil {
+		t.Fatalf("failed to match: %v", err)
+	}
+	var errs []string
+	for _, r := range results {
+		if !r.Status {
+			errs = append(errs, r.ObjectKeyPath+": "+r.Error)
+		}
+	}
+	return status, strings.Join(errs, "\n")
+}
+
+// evenMatcher is the custom matcher from the README, with an optional divisor to test parse errors
+type evenMatcher struct {
+	Divisor int64
+	FieldMatcherProps
+}
+
+func (m *evenMatcher) Parse(parentNode interface{}, node map[interface{}]interface{}) error {
+	m.Divisor = 2
+	if v, ok := node["divisor"]; ok {
+		divisor, ok := v.(int)
+		if !ok || divisor == 0 {
+			return errors.New("invalid divisor")
+		}
+		m.Divisor = int64(divisor)
+	}
+	return m.ParseProps(node)
+}
+
+func (m *evenMatcher) Match(value interface{}, ds *DataStore) (bool, DataStore, error) {
+	store := NewDataStore()
+	n, ok := value.(float64)
+	if !ok || int64(n)%m.Divisor != 0 {
+		m.ErrorStr = fmt.Sprintf("%v is not even", value)
+		return false, store, nil
+	}
+	m.ErrorStr = fmt.Sprintf("%v", value)
+	if m.DSName != "" {
+		return true, store, store.PutVariable(m.DSName, value)
+	}
+	return true, store, nil
+}
+
+func TestRegisterMatcher(t *testing.T) {
+	RegisterMatcher("even", func() FieldMatcher { return &evenMatcher{} })
+	defer delete(customMatchers, "even")
+
+	tests := []struct {
+		name       string
+		definition string
+		response   string
+		passed     bool
+	}{
+		{"passing", "total:\n  type: even", `{"total": 4}`, true},
+		{"failing", "total:\n  type: even", `{"total": 3}`, false},
+		{"custom key", "total:\n  type: even\n  divisor: 3", `{"total": 9}`, true},
+		{"missing field", "total:\n  type: even", `{"other": 1}`, false},
+		{"optional field", "total:\n  type: even\n  exists: false", `{"other": 1}`, true},
+		{"nested", "data:\n  type: array\n  length: 2\n  items:\n    - type: even\n    - type: even", `{"data": [2, 5]}`, false},
+	}
+
+	for _, tt := range tests {
+		ds := NewDataStore()
+		matcher := loadTestMatcher(t, tt.definition, &ds)
+		if len(matcher.Warnings) > 0 {
+			t.Errorf("%v: unexpected warnings %v", tt.name, matcher.Warnings)
+		}
+		if passed, errs := matchTestJson(t, matcher, tt.response); passed != tt.passed {
+			t.Errorf("%v: expected the match to pass: %v but got: %v", tt.name, tt.passed, errs)
+		}
+	}
+
+	// values are stored the same as with built-in matchers
+	ds := NewDataStore()
+	matcher := loadTestMatcher(t, "total:\n  type: even\n  storeAs: evenTotal", &ds)
+	if passed, errs := matchTestJson(t, matcher, `{"total": 8}`); !passed {
+		t.Fatalf("expected the match to pass but got: %v", errs)
+	}
+	if stored := ds.Get("evenTotal"); stored != 8.0 {
+		t.Errorf("expected 8 to be stored but got %v", stored)
+	}
+}
+
+func TestRegisterMatcherErrors(t *testing.T) {
+	RegisterMatcher("even", func() FieldMatcher { return &evenMatcher{} })
+	defer delete(customMatchers, "even")
+	// built-in types are matched before custom ones, so they can't be replaced
+	RegisterMatcher(TYPE_STR, func() FieldMatcher { return &evenMatcher{} })
+	defer delete(customMatchers, TYPE_STR)
+
+	matcher := loadTestMatcher(t, "name:\n  type: string\n  matches: arp", nil)
+	if passed, errs := matchTestJson(t, matcher, `{"name": "arp"}`); !passed {
+		t.Errorf("expected the built-in string matcher to be used but got: %v", errs)
+	}
+
+	for _, definition := range []string{
+		"total:\n  type: odd",
+		"total:\n  type: even\n  divisor: 0",
+	} {
+		def := parseTestYaml(t, definition)
+		malformed := NewResponseMatcher(nil)
+		if err := malformed.loadObjectFields(def, def, FieldMatcherPath{}); err == nil {
+			t.Errorf("expected the definition to be rejected:\n%v", definition)
+		}
+	}
+}
+
+func TestEvaluateNumExprOperators(t *testing.T) {
+	tests := []struct {
+		expr   string
+		number int64
+		status bool
+	}{
+		{"$>= 2", 2, true},
+		{"$>= 2", 1, false},
+		{"$> 2", 2, false},
+		{"$<= 5", 5, true},
+		{"$<= 5", 6, false},
+		{"$< 5", 5, false},
+		{"$> 0, $< 10", 5, true},
+		{"$> 0, $< 10", 10, false},
+		{"$> 1000000", 1000001, true},
+	}
+
+	for _, tt := range tests {
+		status, evaluated, _, err := evaluateNumExpr(tt.expr, tt.number)
+		if err != nil {
+			t.Errorf("failed to evaluate '%v': %v", tt.expr, err)
+			continue
+		}
+		if !evaluated || status != tt.status {
+			t.Errorf("expected '%v' against %v to be %v but got %v", tt.expr, tt.number, tt.status, status)
+		}
+	}
+}
+
+func TestEvaluateFloatExpr(t *testing.T) {
+	tests := []struct {
+		expr   string
+		number float64
+		status bool
+		fails  bool
+	}{
+		{"$>= 2.5", 2.5, true, false},
+		{"$> 2.5", 2.5, false, false},
+		{"$< 0.3", 0.1 + 0.2, false, false},
+		{"$>= 1, $<= 5", 3.2, true, false},
+		{"$>= 1, $<= 5", 5.1, false, false},
+		{"$>= 1, 5", 3, false, true},
+		{"$> abc", 3, false, true},
+	}
+
+	for _, tt := range tests {
+		status, evaluated, _, err := evaluateFloatExpr(tt.expr, tt.number)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("expected '%v' to fail but got %v", tt.expr, status)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("failed to evaluate '%v': %v", tt.expr, err)
+			continue
+		}
+		if !evaluated || status != tt.status {
+			t.Errorf("expected '%v' against %v to be %v but got %v", tt.expr, tt.number, tt.status, status)
+		}
+	}
+}
+
+func TestEvaluateNumExprMessage(t *testing.T) {
+	_, _, message, err := evaluateNumExpr("$< 1000000", 2000000)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if expected := "Expected a result evaluating to: < 1000000 but got 2000000 instead"; message != expected {
+		t.Errorf("expected '%v' but got '%v'", expected, message)
+	}
+}
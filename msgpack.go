@@ -0,0 +1,88 @@
+package arp
+
+import (
+	"bytes"
+	"encoding/base64"
+	"fmt"
+	"reflect"
+	"time"
+
+	"github.com/vmihailenco/msgpack/v5"
+)
+
+// DecodeMsgpack decodes a MessagePack value into the same generic types as JSON (maps with string keys, arrays,
+// strings, float64 numbers, bools and nil) so that it can be validated with the existing matchers. Binary data is
+// decoded into a base64 string and timestamps into RFC 3339 strings.
+func DecodeMsgpack(data []byte) (interface{}, error) {
+	reader := bytes.NewReader(data)
+	dec := msgpack.NewDecoder(reader)
+	dec.SetMapDecoder(decodeMsgpackMap)
+	value, err := dec.DecodeInterface()
+	if err != nil {
+		return nil, err
+	}
+	if reader.Len() > 0 {
+		return nil, fmt.Errorf("unexpected %v trailing bytes after MessagePack value", reader.Len())
+	}
+	return normalizeMsgpack(value), nil
+}
+
+// decodeMsgpackMap decodes a map with its keys converted to strings, as JSON objects only support string keys
+func decodeMsgpackMap(dec *msgpack.Decoder) (interface{}, error) {
+	n, err := dec.DecodeMapLen()
+	if err != nil || n == -1 {
+		return nil, err
+	}
+	m := make(map[string]interface{})
+	for i := 0; i < n; i++ {
+		k, err := dec.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+		v, err := dec.DecodeInterface()
+		if err != nil {
+			return nil, err
+		}
+		m[varToString(normalizeMsgpack(k))] = v
+	}
+	return m, nil
+}
+
+// normalizeMsgpack converts the values decoded by the MessagePack library into their JSON equivalents
+func normalizeMsgpack(value interface{}) interface{} {
+	switch v := value.(type) {
+	case int8, int16, int32, int64:
+		return float64(reflect.ValueOf(v).Int())
+	case uint8, uint16, uint32, uint64:
+		return float64(reflect.ValueOf(v).Uint())
+	case float32:
+		return float64(v)
+	case []byte:
+		return base64.StdEncoding.EncodeToString(v)
+	case time.Time:
+		return v.UTC().Format(time.RFC3339Nano)
+	case []interface{}:
+		for i := range v {
+			v[i] = normalizeMsgpack(v[i])
+		}
+	case map[string]interface{}:
+		for k := range v {
+			v[k] = normalizeMsgpack(v[k])
+		}
+	}
+	return value
+}
+
+// EncodeMsgpack encodes a generic value, such as a test's input converted with YamlToJson, as MessagePack. Map keys
+// are sorted so that the encoding is stable, and whole numbers, such as those decoded from JSON, are sent as integers.
+func EncodeMsgpack(value interface{}) ([]byte, error) {
+	var buf bytes.Buffer
+	enc := msgpack.NewEncoder(&buf)
+	enc.SetSortMapKeys(true)
+	enc.UseCompactInts(true)
+	enc.UseCompactFloats(true)
+	if err := enc.Encode(value); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,162 @@
+package arp
+
+import (
+	"math"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestMsgpackRoundTrip(t *testing.T) {
+	array := func(n int) []interface{} { return make([]interface{}, n) }
+	object := func(n int) map[string]interface{} {
+		m := make(map[string]interface{}, n)
+		for i := 0; i < n; i++ {
+			m[strings.Repeat("k", i+1)] = nil
+		}
+		return m
+	}
+
+	tests := []struct {
+		name     string
+		value    interface{}
+		typeByte byte
+		expected interface{}
+	}{
+		{"nil", nil, 0xc0, nil},
+		{"false", false, 0xc2, false},
+		{"true", true, 0xc3, true},
+
+		{"positive fixint min", 0, 0x00, 0.0},
+		{"positive fixint max", 127, 0x7f, 127.0},
+		{"negative fixint max", -1, 0xff, -1.0},
+		{"negative fixint min", -32, 0xe0, -32.0},
+		{"int8 max", -33, 0xd0, -33.0},
+		{"int8 min", math.MinInt8, 0xd0, float64(math.MinInt8)},
+		{"int16 max", math.MinInt8 - 1, 0xd1, float64(math.MinInt8 - 1)},
+		{"int16 min", math.MinInt16, 0xd1, float64(math.MinInt16)},
+		{"int32 max", math.MinInt16 - 1, 0xd2, float64(math.MinInt16 - 1)},
+		{"int32 min", math.MinInt32, 0xd2, float64(math.MinInt32)},
+		{"int64 max", int64(math.MinInt32) - 1, 0xd3, float64(math.MinInt32 - 1)},
+		{"int64 min", int64(math.MinInt64), 0xd3, float64(math.MinInt64)},
+		{"uint8 min", 128, 0xcc, 128.0},
+		{"uint8 max", math.MaxUint8, 0xcc, float64(math.MaxUint8)},
+		{"uint16 min", math.MaxUint8 + 1, 0xcd, float64(math.MaxUint8 + 1)},
+		{"uint16 max", math.MaxUint16, 0xcd, float64(math.MaxUint16)},
+		{"uint32 min", math.MaxUint16 + 1, 0xce, float64(math.MaxUint16 + 1)},
+		{"uint32 max", int64(math.MaxUint32), 0xce, float64(math.MaxUint32)},
+		{"uint64 min", int64(math.MaxUint32) + 1, 0xcf, float64(math.MaxUint32 + 1)},
+		{"uint64 max", uint64(math.MaxUint64), 0xcf, float64(math.MaxUint64)},
+
+		{"whole float64", 2.0, 0x02, 2.0},
+		{"float64", 1.5, 0xcb, 1.5},
+		{"negative float64", -0.1, 0xcb, -0.1},
+
+		{"empty fixstr", "", 0xa0, ""},
+		{"fixstr max", strings.Repeat("a", 31), 0xbf, strings.Repeat("a", 31)},
+		{"str8 min", strings.Repeat("a", 32), 0xd9, strings.Repeat("a", 32)},
+		{"str8 max", strings.Repeat("a", math.MaxUint8), 0xd9, strings.Repeat("a", math.MaxUint8)},
+		{"str16 min", strings.Repeat("a", math.MaxUint8+1), 0xda, strings.Repeat("a", math.MaxUint8+1)},
+		{"str16 max", strings.Repeat("a", math.MaxUint16), 0xda, strings.Repeat("a", math.MaxUint16)},
+		{"str32 min", strings.Repeat("a", math.MaxUint16+1), 0xdb, strings.Repeat("a", math.MaxUint16+1)},
+
+		{"empty fixarray", array(0), 0x90, array(0)},
+		{"fixarray max", array(15), 0x9f, array(15)},
+		{"array16 min", array(16), 0xdc, array(16)},
+		{"array16 max", array(math.MaxUint16), 0xdc, array(math.MaxUint16)},
+		{"array32 min", array(math.MaxUint16 + 1), 0xdd, array(math.MaxUint16 + 1)},
+
+		{"empty fixmap", object(0), 0x80, object(0)},
+		{"fixmap max", object(15), 0x8f, object(15)},
+		{"map16 min", object(16), 0xde, object(16)},
+
+		{"nested", map[string]interface{}{
+			"id":   1,
+			"tags": []interface{}{"a", true, nil},
+			"meta": map[string]interface{}{"score": 0.5},
+		}, 0x83, map[string]interface{}{
+			"id":   1.0,
+			"tags": []interface{}{"a", true, nil},
+			"meta": map[string]interface{}{"score": 0.5},
+		}},
+	}
+
+	for _, tt := range tests {
+		encoded, err := EncodeMsgpack(tt.value)
+		if err != nil {
+			t.Errorf("%v: failed to encode: %v", tt.name, err)
+			continue
+		}
+		if encoded[0] != tt.typeByte {
+			t.Errorf("%v: expected type byte 0x%02x but got 0x%02x", tt.name, tt.typeByte, encoded[0])
+		}
+		decoded, err := DecodeMsgpack(encoded)
+		if err != nil {
+			t.Errorf("%v: failed to decode: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, tt.expected) {
+			t.Errorf("%v: round trip returned %v", tt.name, ToJsonStr(decoded))
+		}
+	}
+}
+
+func TestDecodeMsgpack(t *testing.T) {
+	tests := []struct {
+		name     string
+		data     []byte
+		expected interface{}
+	}{
+		{"float32", []byte{0xca, 0x3f, 0xc0, 0x00, 0x00}, 1.5},
+		{"uint8", []byte{0xcc, 0xff}, 255.0},
+		{"uint16", []byte{0xcd, 0xff, 0xff}, 65535.0},
+		{"uint32", []byte{0xce, 0xff, 0xff, 0xff, 0xff}, 4294967295.0},
+		{"uint64", []byte{0xcf, 0, 0, 0, 1, 0, 0, 0, 0}, 4294967296.0},
+		{"int8", []byte{0xd0, 0x80}, -128.0},
+		{"int16", []byte{0xd1, 0x80, 0x00}, -32768.0},
+		{"int32", []byte{0xd2, 0xff, 0xff, 0xff, 0xfe}, -2.0},
+		{"int64", []byte{0xd3, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xff, 0xfd}, -3.0},
+		{"str8", []byte{0xd9, 0x02, 'h', 'i'}, "hi"},
+		{"bin8", []byte{0xc4, 0x03, 'a', 'r', 'p'}, "YXJw"},
+		{"bin16", []byte{0xc5, 0x00, 0x01, 0xff}, "/w=="},
+		{"map16", []byte{0xde, 0x00, 0x01, 0xa1, 'a', 0x01}, map[string]interface{}{"a": 1.0}},
+		{"non-string map keys", []byte{0x81, 0x07, 0xc3}, map[string]interface{}{"7": true}},
+		{"timestamp32", []byte{0xd6, 0xff, 0x00, 0x00, 0x00, 0x3c}, "1970-01-01T00:01:00Z"},
+		{"timestamp64", []byte{0xd7, 0xff, 0x00, 0x00, 0x00, 0x04, 0x00, 0x00, 0x00, 0x01}, "1970-01-01T00:00:01.000000001Z"},
+		{"timestamp96", []byte{0xc7, 0x0c, 0xff, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0, 0x3c}, "1970-01-01T00:01:00Z"},
+	}
+
+	for _, tt := range tests {
+		decoded, err := DecodeMsgpack(tt.data)
+		if err != nil {
+			t.Errorf("%v: failed to decode: %v", tt.name, err)
+			continue
+		}
+		if !reflect.DeepEqual(decoded, tt.expected) {
+			t.Errorf("%v: expected %v but got %v", tt.name, ToJsonStr(tt.expected), ToJsonStr(decoded))
+		}
+	}
+}
+
+func TestDecodeMsgpackMalformed(t *testing.T) {
+	tests := []struct {
+		name string
+		data []byte
+	}{
+		{"empty", []byte{}},
+		{"reserved type byte", []byte{0xc1}},
+		{"truncated string", []byte{0xa3, 'a'}},
+		{"truncated float64", []byte{0xcb, 0x00}},
+		{"trailing bytes", []byte{0xc0, 0xc0}},
+		{"array length beyond data", []byte{0xdd, 0xff, 0xff, 0xff, 0xff}},
+		{"map length beyond data", []byte{0xdf, 0xff, 0xff, 0xff, 0xff}},
+		{"unsupported extension", []byte{0xd4, 0x01, 0x00}},
+		{"invalid timestamp length", []byte{0xd5, 0xff, 0x00, 0x00}},
+	}
+
+	for _, tt := range tests {
+		if decoded, err := DecodeMsgpack(tt.data); err == nil {
+			t.Errorf("%v: expected an error but got %v", tt.name, decoded)
+		}
+	}
+}
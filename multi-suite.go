@@ -1,9 +1,13 @@
 package arp
 
 import (
+	"context"
 	"fmt"
 	"os"
+	"os/exec"
 	"path/filepath"
+	"runtime/debug"
+	"sort"
 	"strings"
 	"sync"
 	"time"
@@ -12,6 +16,7 @@ import (
 type MultiTestSuite struct {
 	Suites  map[string]*TestSuite
 	Verbose bool
+	Context context.Context
 }
 
 type MultiSuiteResult struct {
@@ -19,6 +24,19 @@ type MultiSuiteResult struct {
 	Error       error
 	TestResults SuiteResult
 	TestFile    string
+	// host the suite was executed against when running the same tests against multiple hosts
+	Host string
+	// row of the dataset the suite was executed with, starting at 1. Zero when no dataset is used.
+	Row int
+}
+
+// Label identifies the suite's results in reports, prefixing the test file with the host and dataset row it was
+// executed with when running the tests more than once.
+func (r MultiSuiteResult) Label() string {
+	if run := RunLabel(r.Host, r.Row); run != "" {
+		return fmt.Sprintf("[%v] %v", run, r.TestFile)
+	}
+	return r.TestFile
 }
 
 type MultiSuiteWorker struct {
@@ -27,30 +45,124 @@ type MultiSuiteWorker struct {
 	TestFile string
 }
 
-func NewMultiSuiteTest(testDir string, fixtures string) (*MultiTestSuite, error) {
+func NewMultiSuiteTest(testDir string, fixtures string, opts SuiteOptions) (*MultiTestSuite, error) {
 	multiSuite := &MultiTestSuite{
 		Suites:  map[string]*TestSuite{},
 		Verbose: true,
 	}
-	err := multiSuite.LoadTests(testDir, fixtures)
+	err := multiSuite.LoadTests(testDir, fixtures, opts)
 	return multiSuite, err
 }
 
-func (t *MultiTestSuite) LoadTests(testDir string, fixtures string) error {
-	err := filepath.Walk(testDir, func(path string, info os.FileInfo, err error) error {
-		if strings.HasSuffix(path, ".yaml") {
-			suite, err := NewTestSuite(path, fixtures)
-			if err != nil {
-				return err
+// NewMultiSuiteTestFromFiles creates a multi suite from an explicit list of test files, such as those returned
+// by ExpandGlobs.
+func NewMultiSuiteTestFromFiles(files []string, fixtures string, opts SuiteOptions) (*MultiTestSuite, error) {
+	multiSuite := &MultiTestSuite{
+		Suites:  map[string]*TestSuite{},
+		Verbose: true,
+	}
+	for _, file := range files {
+		if err := multiSuite.loadFile(file, fixtures, opts); err != nil {
+			return multiSuite, err
+		}
+	}
+	return multiSuite, nil
+}
+
+// ExpandGlobs expands a comma separated list of file paths and glob patterns (e.g. tests/smoke/*.yaml) into the
+// sorted list of unique yaml files they match. URLs of remote test files are included as they are. An error is returned
+// if a pattern doesn't match any test files.
+func ExpandGlobs(patterns string) ([]string, error) {
+	var files []string
+	seen := map[string]bool{}
+	for _, pattern := range strings.Split(patterns, ",") {
+		pattern = strings.TrimSpace(pattern)
+		if pattern == "" {
+			continue
+		}
+		if IsRemoteFile(pattern) {
+			if !seen[pattern] {
+				seen[pattern] = true
+				files = append(files, pattern)
+			}
+			continue
+		}
+		matches, err := filepath.Glob(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid glob pattern '%v': %v", pattern, err)
+		}
+		matched := false
+		for _, match := range matches {
+			if !strings.HasSuffix(match, ".yaml") || IsSiblingFixtures(match) {
+				continue
 			}
-			if len(suite.Tests) == 0 {
-				return nil
+			matched = true
+			if !seen[match] {
+				seen[match] = true
+				files = append(files, match)
 			}
+		}
+		// a typo in a pattern would otherwise silently skip the tests it was meant to run
+		if !matched {
+			return nil, fmt.Errorf("glob pattern '%v' doesn't match any test files", pattern)
+		}
+	}
+	sort.Strings(files)
+	return files, nil
+}
+
+// ChangedFiles returns the absolute paths of the files within the git repository containing dir that differ from
+// ref, including uncommitted changes and untracked files that aren't ignored.
+func ChangedFiles(dir string, ref string) (map[string]bool, error) {
+	top, err := exec.Command("git", "-C", dir, "rev-parse", "--show-toplevel").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to find git repository: %v", err)
+	}
+	out, err := exec.Command("git", "-C", dir, "diff", "--name-only", ref, "--").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to diff against '%v': %v", ref, err)
+	}
+	// new test files aren't part of the diff until they're added
+	untracked, err := exec.Command("git", "-C", dir, "ls-files", "--others", "--exclude-standard", "--full-name").Output()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list untracked files: %v", err)
+	}
 
-			if suite != nil {
-				t.Suites[path] = suite
+	root := strings.TrimSpace(string(top))
+	changed := map[string]bool{}
+	for _, name := range strings.Split(string(out)+"\n"+string(untracked), "\n") {
+		if name = strings.TrimSpace(name); name != "" {
+			changed[filepath.Join(root, name)] = true
+		}
+	}
+	return changed, nil
+}
+
+// FilterFiles removes every suite whose test file isn't within the set of absolute paths
+func (t *MultiTestSuite) FilterFiles(files map[string]bool) {
+	for path := range t.Suites {
+		abs, err := filepath.Abs(path)
+		if err == nil {
+			// git reports paths with symlinks resolved
+			if resolved, rErr := filepath.EvalSymlinks(abs); rErr == nil {
+				abs = resolved
 			}
-			return nil
+		}
+		if err != nil || !files[abs] {
+			delete(t.Suites, path)
+		}
+	}
+}
+
+func (t *MultiTestSuite) LoadTests(testDir string, fixtures string, opts SuiteOptions) error {
+	// remote test roots can't be listed, so they must point to a single test file
+	if IsRemoteFile(testDir) {
+		return t.loadFile(testDir, fixtures, opts)
+	}
+
+	err := filepath.Walk(testDir, func(path string, info os.FileInfo, err error) error {
+		if strings.HasSuffix(path, ".yaml") && !IsSiblingFixtures(path) {
+			return t.loadFile(path, fixtures, opts)
 		}
 
 		return nil
@@ -59,6 +171,35 @@ func (t *MultiTestSuite) LoadTests(testDir string, fixtures string) error {
 	return err
 }
 
+func (t *MultiTestSuite) loadFile(path string, fixtures string, opts SuiteOptions) error {
+	suite, err := NewTestSuite(path, fixtures, opts)
+	if err != nil {
+		return err
+	}
+	if len(suite.Tests) == 0 {
+		return nil
+	}
+
+	if suite != nil {
+		t.Suites[path] = suite
+	}
+	return nil
+}
+
+// executeSuite executes the tests of a single suite, converting any panic into a failed result so that the worker
+// can move on to the next suite.
+func executeSuite(m MultiSuiteWorker) (r MultiSuiteResult) {
+	r.TestFile = m.TestFile
+	defer func() {
+		if p := recover(); p != nil {
+			r.Passed = false
+			r.Error = fmt.Errorf(PanicFmt, p, debug.Stack())
+		}
+	}()
+	r.Passed, r.TestResults, r.Error = m.Suite.ExecuteTests(m.TestTags)
+	return r
+}
+
 func (t *MultiTestSuite) ExecuteTests(threads int, testTags []string) (bool, []MultiSuiteResult, time.Duration, error) {
 	startTime := time.Now()
 
@@ -86,15 +227,10 @@ func (t *MultiTestSuite) ExecuteTests(threads int, testTags []string) (bool, []M
 				if t.Verbose {
 					fmt.Printf("> In Progress: %v\n", m.TestFile)
 				}
-				status, result, err := m.Suite.ExecuteTests(m.TestTags)
-				r := MultiSuiteResult{
-					Passed:      status,
-					Error:       err,
-					TestFile:    m.TestFile,
-					TestResults: result,
+				if m.Suite.Context == nil {
+					m.Suite.Context = t.Context
 				}
-
-				workerResults <- r
+				workerResults <- executeSuite(m)
 			}
 		}()
 	}
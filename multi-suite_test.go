@@ -0,0 +1,112 @@
+package arp
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestExpandGlobs(t *testing.T) {
+	dir := t.TempDir()
+	for _, name := range []string{"a.yaml", "b.yaml", "a" + SiblingFixturesSuffix, "notes.txt"} {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte{}, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	a := filepath.Join(dir, "a.yaml")
+	b := filepath.Join(dir, "b.yaml")
+
+	tests := []struct {
+		name     string
+		patterns string
+		expected []string
+		fails    bool
+	}{
+		{"glob", filepath.Join(dir, "*.yaml"), []string{a, b}, false},
+		{"files", b + ", " + a, []string{a, b}, false},
+		{"duplicates", a + "," + filepath.Join(dir, "*.yaml"), []string{a, b}, false},
+		{"remote file", "https://example.com/tests.yaml," + a, []string{a, "https://example.com/tests.yaml"}, false},
+		{"empty patterns", " , ", nil, false},
+		{"no matches", filepath.Join(dir, "missing", "*.yaml"), nil, true},
+		{"only non test files", filepath.Join(dir, "*.txt"), nil, true},
+		{"one pattern without matches", a + "," + filepath.Join(dir, "c.yaml"), nil, true},
+		{"invalid pattern", filepath.Join(dir, "[.yaml"), nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			files, err := ExpandGlobs(tt.patterns)
+			if tt.fails {
+				if err == nil {
+					t.Errorf("expected an error but got %v", files)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(files, tt.expected) {
+				t.Errorf("expected %v but got %v", strings.Join(tt.expected, ","), strings.Join(files, ","))
+			}
+		})
+	}
+}
+
+func TestChangedFiles(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git is unavailable")
+	}
+	root, err := filepath.EvalSymlinks(t.TempDir())
+	if err != nil {
+		t.Fatal(err)
+	}
+	git := func(args ...string) {
+		t.Helper()
+		args = append([]string{"-C", root, "-c", "user.name=arp", "-c", "user.email=arp@example.com"}, args...)
+		if out, err := exec.Command("git", args...).CombinedOutput(); err != nil {
+			t.Fatalf("git %v: %v\n%s", args, err, out)
+		}
+	}
+	write := func(name string, contents string) {
+		t.Helper()
+		path := filepath.Join(root, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(contents), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	git("init", "-q")
+	write(".gitignore", "ignored.yaml\n")
+	write("tests/unchanged.yaml", "tests: []\n")
+	write("tests/committed.yaml", "tests: []\n")
+	write("tests/modified.yaml", "tests: []\n")
+	git("add", "-A")
+	git("commit", "-q", "-m", "base")
+	git("tag", "base")
+
+	write("tests/committed.yaml", "tests:\n  - name: committed\n")
+	git("commit", "-q", "-am", "change")
+	write("tests/modified.yaml", "tests:\n  - name: modified\n")
+	write("tests/untracked.yaml", "tests: []\n")
+	write("tests/ignored.yaml", "tests: []\n")
+
+	changed, err := ChangedFiles(filepath.Join(root, "tests"), "base")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := map[string]bool{
+		filepath.Join(root, "tests", "committed.yaml"): true,
+		filepath.Join(root, "tests", "modified.yaml"):  true,
+		filepath.Join(root, "tests", "untracked.yaml"): true,
+	}
+	if !reflect.DeepEqual(changed, expected) {
+		t.Errorf("expected %v but got %v", expected, changed)
+	}
+}
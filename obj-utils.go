@@ -314,7 +314,7 @@ func GetJsonValue(src map[string]interface{}, jsonPath string) (interface{}, err
 				// should catch non integer and negative value
 				return "", fmt.Errorf(BadIndexDSFmt, jsonPath)
 			}
-			if idx > uint64(len(v)) {
+			if idx >= uint64(len(v)) {
 				return "", fmt.Errorf(IndexExceedsDSFmt, jsonPath)
 			}
 
@@ -0,0 +1,33 @@
+package arp
+
+import (
+	"testing"
+)
+
+func TestGetJsonValueIndex(t *testing.T) {
+	src := map[string]interface{}{"items": []interface{}{"a", "b"}}
+
+	tests := []struct {
+		path     string
+		expected interface{}
+		fails    bool
+	}{
+		{"items[0]", "a", false},
+		{"items[1]", "b", false},
+		{"items[2]", nil, true},
+		{"items[-1]", nil, true},
+	}
+
+	for _, tt := range tests {
+		value, err := GetJsonValue(src, tt.path)
+		if tt.fails {
+			if err == nil {
+				t.Errorf("expected '%v' to fail but got %v", tt.path, value)
+			}
+			continue
+		}
+		if err != nil || value != tt.expected {
+			t.Errorf("expected '%v' to be %v but got %v (%v)", tt.path, tt.expected, value, err)
+		}
+	}
+}
@@ -0,0 +1,330 @@
+package arp
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	CFG_RESPONSE_EXAMPLE = "openApiExample"
+
+	EXAMPLE_MATCH_EXACT     = "exact"
+	EXAMPLE_MATCH_STRUCTURE = "structure"
+
+	ExamplePath           = "response.Example"
+	ExampleMatchedFmt     = "Response matches example '%v'"
+	ExampleRemovedErrMsg  = "Field from example is missing"
+	ExampleAddedErrMsg    = "Field is not part of example"
+	ExampleNotFoundErrFmt = "no example named '%v' for status %v of %v in spec: %v. Available examples: %v"
+	ExampleNameErrFmt     = "an example name is required for status %v of %v in spec: %v. Available examples: %v"
+)
+
+var (
+	// specs loaded by 'openApiExample' validations are shared across every test in a run
+	openApiSpecCache = struct {
+		sync.Mutex
+		Specs map[string]map[string]interface{}
+	}{Specs: make(map[string]map[string]interface{})}
+)
+
+// TestCaseExampleCfg validates a response against a named response example of an operation in an OpenAPI spec.
+// The operation is found by its operationId or by its path and method.
+type TestCaseExampleCfg struct {
+	Spec        string `yaml:"spec"`
+	OperationId string `yaml:"operationId"`
+	Path        string `yaml:"path"`
+	Method      string `yaml:"method"`
+	// name of the example, which can be omitted when the response defines a single example
+	Name string `yaml:"name"`
+	// status code of the documented response, defaulting to the status of the actual response
+	Code interface{} `yaml:"code"`
+	// whether the response must equal the example or only share its structure
+	Match string `yaml:"match"`
+}
+
+// specToJson converts a parsed YAML spec into JSON types. Unlike YamlToJson, non-string keys such as unquoted status
+// codes are allowed.
+func specToJson(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[interface{}]interface{}:
+		obj := make(map[string]interface{})
+		for k, v := range n {
+			obj[fmt.Sprintf("%v", k)] = specToJson(v)
+		}
+		return obj
+	case []interface{}:
+		for i, v := range n {
+			n[i] = specToJson(v)
+		}
+	}
+	return node
+}
+
+// loadOpenApiSpec reads a JSON or YAML OpenAPI spec
+func loadOpenApiSpec(path string) (map[string]interface{}, error) {
+	openApiSpecCache.Lock()
+	defer openApiSpecCache.Unlock()
+
+	if spec, ok := openApiSpecCache.Specs[path]; ok {
+		return spec, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read OpenAPI spec: %v - %v", path, err)
+	}
+
+	var raw interface{}
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %v - %v", path, err)
+	}
+	spec, ok := specToJson(raw).(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("failed to parse OpenAPI spec: %v - expected an object", path)
+	}
+
+	openApiSpecCache.Specs[path] = spec
+	return spec, nil
+}
+
+// resolveSpecRef follows a local '$ref' (e.g. '#/components/examples/User') until a node without one is found
+func resolveSpecRef(spec map[string]interface{}, node interface{}) (interface{}, error) {
+	for i := 0; i < 32; i++ {
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return node, nil
+		}
+		ref, ok := obj["$ref"].(string)
+		if !ok {
+			return node, nil
+		}
+		if !strings.HasPrefix(ref, "#/") {
+			return nil, fmt.Errorf("only local references are supported: %v", ref)
+		}
+
+		var cur interface{} = spec
+		for _, part := range strings.Split(strings.TrimPrefix(ref, "#/"), "/") {
+			part = strings.NewReplacer("~1", "/", "~0", "~").Replace(part)
+			curObj, ok := cur.(map[string]interface{})
+			if !ok {
+				return nil, fmt.Errorf("invalid reference: %v", ref)
+			}
+			if cur, ok = curObj[part]; !ok {
+				return nil, fmt.Errorf("invalid reference: %v", ref)
+			}
+		}
+		node = cur
+	}
+	return nil, fmt.Errorf("too many nested references")
+}
+
+// findOperation looks up an operation by its operationId, or by its path and method
+func (e *TestCaseExampleCfg) findOperation(spec map[string]interface{}) (map[string]interface{}, string, error) {
+	paths, _ := spec["paths"].(map[string]interface{})
+	if e.OperationId != "" {
+		for p, item := range paths {
+			methods, _ := item.(map[string]interface{})
+			for m, op := range methods {
+				if operation, ok := op.(map[string]interface{}); ok && operation["operationId"] == e.OperationId {
+					return operation, fmt.Sprintf("%v %v", strings.ToUpper(m), p), nil
+				}
+			}
+		}
+		return nil, "", fmt.Errorf("no operation with operationId '%v'", e.OperationId)
+	}
+
+	method := strings.ToLower(e.Method)
+	if method == "" {
+		method = "get"
+	}
+	name := fmt.Sprintf("%v %v", strings.ToUpper(method), e.Path)
+	methods, _ := paths[e.Path].(map[string]interface{})
+	operation, ok := methods[method].(map[string]interface{})
+	if !ok {
+		return nil, "", fmt.Errorf("no operation for %v", name)
+	}
+	return operation, name, nil
+}
+
+// findResponse returns the documented response for a status code, falling back to its class (e.g. 2XX) and then the
+// default response
+func findResponse(spec map[string]interface{}, operation map[string]interface{}, code string) (map[string]interface{}, error) {
+	responses, _ := operation["responses"].(map[string]interface{})
+	candidates := []string{code}
+	if len(code) == 3 {
+		candidates = append(candidates, code[:1]+"XX", code[:1]+"xx")
+	}
+	candidates = append(candidates, "default")
+	for _, c := range candidates {
+		if r, ok := responses[c]; ok {
+			resolved, err := resolveSpecRef(spec, r)
+			if err != nil {
+				return nil, err
+			}
+			if obj, ok := resolved.(map[string]interface{}); ok {
+				return obj, nil
+			}
+		}
+	}
+	return nil, fmt.Errorf("no response documented for status %v", code)
+}
+
+// responseExamples collects the named examples of a documented response. OpenAPI 3 'examples' keep their names and
+// a single 'example' is named after its media type, as are the examples of Swagger 2 responses.
+func responseExamples(spec map[string]interface{}, response map[string]interface{}) (map[string]interface{}, error) {
+	examples := make(map[string]interface{})
+	content, _ := response["content"].(map[string]interface{})
+	for mediaType, m := range content {
+		media, _ := m.(map[string]interface{})
+		if example, ok := media["example"]; ok {
+			examples[mediaType] = example
+		}
+		named, _ := media["examples"].(map[string]interface{})
+		for name, ex := range named {
+			resolved, err := resolveSpecRef(spec, ex)
+			if err != nil {
+				return nil, err
+			}
+			if obj, ok := resolved.(map[string]interface{}); ok {
+				examples[name] = obj["value"]
+			}
+		}
+	}
+
+	// swagger 2 examples are keyed by mime type
+	swagger, _ := response["examples"].(map[string]interface{})
+	for mimeType, ex := range swagger {
+		examples[mimeType] = ex
+	}
+	return examples, nil
+}
+
+// findExample loads the spec and returns the name and value of the example to compare the response against
+func (t *TestCase) findExample(statusCode int) (string, interface{}, error) {
+	e := t.Config.Response.Example
+
+	resolved, err := t.GlobalDataStore.ExpandVariable(e.Spec)
+	if err != nil {
+		return "", nil, fmt.Errorf(BadVarMatcherFmt, e.Spec)
+	}
+	path := varToString(resolved, e.Spec)
+	if !filepath.IsAbs(path) {
+		if testDir, ok := t.GlobalDataStore.Get(DS_TEST_DIR).(string); ok {
+			path = filepath.Join(testDir, path)
+		}
+	}
+
+	spec, err := loadOpenApiSpec(path)
+	if err != nil {
+		return "", nil, err
+	}
+
+	operation, operationName, err := e.findOperation(spec)
+	if err != nil {
+		return "", nil, fmt.Errorf("%v in spec: %v", err, path)
+	}
+
+	code := fmt.Sprintf("%v", statusCode)
+	if e.Code != nil {
+		code = fmt.Sprintf("%v", e.Code)
+	}
+	response, err := findResponse(spec, operation, code)
+	if err != nil {
+		return "", nil, fmt.Errorf("%v of %v in spec: %v", err, operationName, path)
+	}
+
+	examples, err := responseExamples(spec, response)
+	if err != nil {
+		return "", nil, fmt.Errorf("%v in spec: %v", err, path)
+	}
+
+	var names []string
+	for name := range examples {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	name := e.Name
+	if name == "" && len(names) == 1 {
+		name = names[0]
+	}
+	if name == "" && len(names) > 1 {
+		return "", nil, fmt.Errorf(ExampleNameErrFmt, code, operationName, path, strings.Join(names, ", "))
+	}
+	example, ok := examples[name]
+	if !ok {
+		return "", nil, fmt.Errorf(ExampleNotFoundErrFmt, name, code, operationName, path, strings.Join(names, ", "))
+	}
+	return name, example, nil
+}
+
+// normalizeJson round trips a value through JSON so that numbers from YAML and JSON compare equally
+func normalizeJson(value interface{}) (interface{}, error) {
+	b, err := json.Marshal(value)
+	if err != nil {
+		return nil, err
+	}
+	var normalized interface{}
+	err = json.Unmarshal(b, &normalized)
+	return normalized, err
+}
+
+// validateExample compares a response against an example from an OpenAPI spec, either requiring it to be equal or
+// only to share its structure (keys and value types). Ignored paths are removed from both before they are compared
+// and added to the set of removed paths.
+func (t *TestCase) validateExample(statusCode int, response interface{}, ignored map[string]bool) []*FieldMatcherResult {
+	name, example, err := t.findExample(statusCode)
+	if err != nil {
+		return []*FieldMatcherResult{validationError(ExamplePath, err)}
+	}
+
+	expected, err := normalizeJson(example)
+	if err != nil {
+		return []*FieldMatcherResult{validationError(ExamplePath, err)}
+	}
+	actual, err := normalizeJson(response)
+	if err != nil {
+		return []*FieldMatcherResult{validationError(ExamplePath, err)}
+	}
+	expected = t.IgnoredPaths.Prune(expected, make(map[string]bool))
+	actual = t.IgnoredPaths.Prune(actual, ignored)
+
+	var results []*FieldMatcherResult
+	if t.Config.Response.Example.Match == EXAMPLE_MATCH_STRUCTURE {
+		contracts := &ContractStore{}
+		contracts.compare(contracts.shape(expected, ""), contracts.shape(actual, ""), "", &results)
+		for _, r := range results {
+			r.ObjectKeyPath = strings.TrimSuffix(fmt.Sprintf("%v.%v", ExamplePath,
+				strings.TrimPrefix(r.ObjectKeyPath, CONTRACT_PATH_PREFIX)), ".")
+			switch r.Error {
+			case ContractRemovedErrMsg:
+				r.Error = ExampleRemovedErrMsg
+			case ContractAddedErrMsg:
+				r.Error = ExampleAddedErrMsg
+			}
+			r.Error = fmt.Sprintf("[%v] %v", name, r.Error)
+		}
+	} else if msg, equal := assertDiff(expected, actual, ""); !equal {
+		results = append(results, &FieldMatcherResult{
+			ObjectKeyPath:   ExamplePath,
+			Error:           fmt.Sprintf("[%v] %v", name, msg),
+			ShowExtendedMsg: len(msg) >= 64,
+		})
+	}
+
+	if len(results) == 0 {
+		results = append(results, &FieldMatcherResult{
+			ObjectKeyPath: ExamplePath,
+			Error:         fmt.Sprintf(ExampleMatchedFmt, name),
+			Status:        true,
+		})
+	}
+	return results
+}
@@ -0,0 +1,80 @@
+package arp
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+)
+
+const (
+	REDACTED_VALUE = "***"
+)
+
+var (
+	// Key patterns that are masked by default when printing reports and data store dumps
+	DefaultRedactPatterns = []string{"authorization", "*token*", "*password*"}
+)
+
+// Redactor masks the values of any object keys that match one of its patterns. Patterns support
+// shell style wildcards (e.g. *token*) and are matched case-insensitively.
+type Redactor struct {
+	Patterns []string
+}
+
+func NewRedactor(patternList string) Redactor {
+	r := Redactor{}
+	for _, p := range strings.Split(patternList, ",") {
+		if p = strings.TrimSpace(p); p != "" {
+			r.Patterns = append(r.Patterns, strings.ToLower(p))
+		}
+	}
+	return r
+}
+
+func (r *Redactor) IsSensitive(key string) bool {
+	key = strings.ToLower(key)
+	for _, p := range r.Patterns {
+		if matched, _ := path.Match(p, key); matched {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns a JSON representation of the input object where the values of all sensitive keys
+// have been masked. The input object is left untouched.
+func (r *Redactor) Redact(obj interface{}) interface{} {
+	if len(r.Patterns) == 0 {
+		return obj
+	}
+
+	b, err := json.Marshal(obj)
+	if err != nil {
+		return obj
+	}
+
+	var node interface{}
+	if err := json.Unmarshal(b, &node); err != nil {
+		return obj
+	}
+
+	return r.redactNode(node)
+}
+
+func (r *Redactor) redactNode(node interface{}) interface{} {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		for k := range n {
+			if r.IsSensitive(k) {
+				n[k] = REDACTED_VALUE
+			} else {
+				n[k] = r.redactNode(n[k])
+			}
+		}
+	case []interface{}:
+		for i, e := range n {
+			n[i] = r.redactNode(e)
+		}
+	}
+	return node
+}
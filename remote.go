@@ -0,0 +1,133 @@
+package arp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	neturl "net/url"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	DEFAULT_REMOTE_TIMEOUT = 30 * time.Second
+)
+
+var (
+	// test files fetched from URLs are only requested once per run
+	remoteFileCache = struct {
+		sync.Mutex
+		Files map[string][]byte
+	}{Files: make(map[string][]byte)}
+
+	// media types that can't contain a test file, such as the login page of a protected host
+	rejectedRemoteMediaTypes = []string{"text/html", "application/xhtml+xml"}
+)
+
+// RemoteOptions configures how test files hosted at http(s) URLs are fetched
+type RemoteOptions struct {
+	// headers sent with every request, e.g. for authentication
+	Headers http.Header
+	// maximum duration of each request. Defaults to DEFAULT_REMOTE_TIMEOUT.
+	Timeout time.Duration
+	// allow fetching test files over plain http. Test files can run commands locally, so anyone able to tamper with
+	// an unencrypted connection could run commands on the machine executing the tests.
+	AllowInsecure bool
+}
+
+// IsRemoteFile returns whether a test file path is an http(s) URL rather than a local path
+func IsRemoteFile(path string) bool {
+	lower := strings.ToLower(path)
+	return strings.HasPrefix(lower, "http://") || strings.HasPrefix(lower, "https://")
+}
+
+// checkRemoteScheme fails for URLs that aren't fetched over https, unless insecure URLs are allowed
+func checkRemoteScheme(url *neturl.URL, opts RemoteOptions) error {
+	if opts.AllowInsecure || strings.EqualFold(url.Scheme, "https") {
+		return nil
+	}
+	return fmt.Errorf("refusing to fetch %v over insecure %v, test files must be fetched over https", url, url.Scheme)
+}
+
+// ParseRemoteHeaders parses a list of 'Name: value' pairs into the headers sent when fetching remote test files
+func ParseRemoteHeaders(list []string) (http.Header, error) {
+	headers := http.Header{}
+	for _, h := range list {
+		pair := strings.SplitN(h, ":", 2)
+		if len(pair) < 2 || strings.TrimSpace(pair[0]) == "" {
+			return nil, fmt.Errorf("invalid remote header '%v': expected 'Name: value'", h)
+		}
+		headers.Add(strings.TrimSpace(pair[0]), strings.TrimSpace(pair[1]))
+	}
+	return headers, nil
+}
+
+// FetchRemoteFile downloads a test file from a URL. The contents of each URL are cached for the rest of the run so
+// that every suite loading it, and any reload, sees the same file. Responses without a 2xx status or with an HTML
+// content type are treated as errors.
+func FetchRemoteFile(ctx context.Context, url string, opts RemoteOptions) ([]byte, error) {
+	remoteFileCache.Lock()
+	defer remoteFileCache.Unlock()
+
+	if data, ok := remoteFileCache.Files[url]; ok {
+		return data, nil
+	}
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	timeout := opts.Timeout
+	if timeout <= 0 {
+		timeout = DEFAULT_REMOTE_TIMEOUT
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("invalid url: %v", err)
+	}
+	if err := checkRemoteScheme(request.URL, opts); err != nil {
+		return nil, err
+	}
+	for k, v := range opts.Headers {
+		request.Header[k] = v
+	}
+
+	// redirects are held to the same scheme requirement so that an https URL can't be downgraded
+	client := &http.Client{
+		CheckRedirect: func(redirect *http.Request, via []*http.Request) error {
+			if len(via) >= 10 {
+				return errors.New("stopped after 10 redirects")
+			}
+			return checkRemoteScheme(redirect.URL, opts)
+		},
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch: %v", err)
+	}
+	defer response.Body.Close()
+
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected status %v", response.Status)
+	}
+	if mediaType, _, mErr := mime.ParseMediaType(response.Header.Get(HEADER_CONTENT_TYPE)); mErr == nil {
+		for _, rejected := range rejectedRemoteMediaTypes {
+			if mediaType == rejected {
+				return nil, fmt.Errorf("unexpected content type %v", mediaType)
+			}
+		}
+	}
+
+	data, err := io.ReadAll(response.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read: %v", err)
+	}
+	remoteFileCache.Files[url] = data
+	return data, nil
+}
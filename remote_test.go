@@ -0,0 +1,72 @@
+package arp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	neturl "net/url"
+	"strings"
+	"testing"
+)
+
+func TestCheckRemoteScheme(t *testing.T) {
+	tests := []struct {
+		url      string
+		insecure bool
+		allowed  bool
+	}{
+		{"https://tests.example.com/users.yaml", false, true},
+		{"HTTPS://tests.example.com/users.yaml", false, true},
+		{"http://tests.example.com/users.yaml", false, false},
+		{"http://tests.example.com/users.yaml", true, true},
+	}
+
+	for _, tt := range tests {
+		url, _ := neturl.Parse(tt.url)
+		err := checkRemoteScheme(url, RemoteOptions{AllowInsecure: tt.insecure})
+		if allowed := err == nil; allowed != tt.allowed {
+			t.Errorf("expected %v to be allowed: %v (insecure: %v)", tt.url, tt.allowed, tt.insecure)
+		}
+	}
+}
+
+func TestFetchRemoteFileInsecure(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tests: []\n"))
+	}))
+	defer server.Close()
+
+	if _, err := FetchRemoteFile(nil, server.URL+"/refused.yaml", RemoteOptions{}); err == nil ||
+		!strings.Contains(err.Error(), "https") {
+		t.Errorf("expected plain http to be refused but got %v", err)
+	}
+
+	data, err := FetchRemoteFile(nil, server.URL+"/allowed.yaml", RemoteOptions{AllowInsecure: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "tests: []\n" {
+		t.Errorf("unexpected contents '%v'", string(data))
+	}
+}
+
+func TestFetchRemoteFileRedirectDowngrade(t *testing.T) {
+	insecure := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("tests: []\n"))
+	}))
+	defer insecure.Close()
+
+	secure := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.Redirect(w, r, insecure.URL+"/tampered.yaml", http.StatusFound)
+	}))
+	defer secure.Close()
+
+	// trust the certificate of the test server for the duration of the test
+	transport := http.DefaultTransport
+	http.DefaultTransport = secure.Client().Transport
+	defer func() { http.DefaultTransport = transport }()
+
+	if _, err := FetchRemoteFile(nil, secure.URL+"/users.yaml", RemoteOptions{}); err == nil ||
+		!strings.Contains(err.Error(), "https") {
+		t.Errorf("expected the redirect to plain http to be refused but got %v", err)
+	}
+}
@@ -0,0 +1,103 @@
+package arp
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+const (
+	CFG_REPEAT = "repeat"
+
+	RepeatSummaryFmt = "Passed %v of %v attempts over %v"
+	RepeatAttemptFmt = "Attempt %v failed: %v"
+)
+
+// RepeatCount returns the number of times the test is executed, preferring the test's own 'repeat' value
+func (t *TestCase) RepeatCount() int {
+	if t.Config.Repeat > 0 {
+		return t.Config.Repeat
+	}
+	return t.Repeat
+}
+
+// attemptErrors summarizes why a repeated attempt failed using its execution error or failing validations
+func attemptErrors(result *TestResult, err error) string {
+	if err != nil {
+		return err.Error()
+	}
+	var errs []string
+	for _, f := range result.Fields {
+		if !f.Status {
+			errs = append(errs, fmt.Sprintf("%v: %v", f.ObjectKeyPath, f.Error))
+		}
+	}
+	return strings.Join(errs, "; ")
+}
+
+// executeDetached executes an attempt against a detached child of the test's data store so that the values it stores
+// are discarded once it completes. The store is swapped in place since the test's matchers hold a pointer to it.
+func (t *TestCase) executeDetached(result *TestResult, respParser ResponseParserHandler, respValidator ResponseValidatorHandler) (*TestResult, error) {
+	ds := t.GlobalDataStore
+	if ds == nil {
+		return t.executeAttempt(result, respParser, respValidator)
+	}
+	kept := *ds
+	*ds = NewDetachedDataStore(&kept)
+	defer func() { *ds = kept }()
+	return t.executeAttempt(result, respParser, respValidator)
+}
+
+// executeRepeated executes the test several times to detect intermittent failures. The result of the last attempt
+// is reported along with the number of attempts that passed and the errors of those that failed, and only passes if
+// every attempt passed. Execution errors fail the attempt rather than the run, unless the run was cancelled. Only the
+// values stored by the last attempt are kept in the data store.
+func (t *TestCase) executeRepeated(result *TestResult, respParser ResponseParserHandler, respValidator ResponseValidatorHandler) (*TestResult, error) {
+	start := result.StartTime
+	count := t.RepeatCount()
+	passed := 0
+	var failures []*FieldMatcherResult
+
+	for i := 1; i <= count; i++ {
+		if i > 1 {
+			if err := t.ctx().Err(); err != nil {
+				return result, fmt.Errorf(TimedOutFmt, err)
+			}
+			result = &TestResult{
+				TestCase:  *t,
+				StartTime: time.Now().UTC(),
+			}
+		}
+
+		var err error
+		if i < count {
+			result, err = t.executeDetached(result, respParser, respValidator)
+		} else {
+			result, err = t.executeAttempt(result, respParser, respValidator)
+		}
+		if err != nil && t.ctx().Err() != nil {
+			return result, err
+		}
+		if err == nil && result.Passed {
+			passed++
+			continue
+		}
+
+		failures = append(failures, &FieldMatcherResult{
+			ObjectKeyPath:   fmt.Sprintf("test.%v[%v]", CFG_REPEAT, i),
+			Error:           fmt.Sprintf(RepeatAttemptFmt, i, attemptErrors(result, err)),
+			Status:          false,
+			ShowExtendedMsg: true,
+		})
+	}
+
+	result.StartTime = start
+	result.Passed = passed == count
+	result.Fields = append(result.Fields, &FieldMatcherResult{
+		ObjectKeyPath: fmt.Sprintf("test.%v", CFG_REPEAT),
+		Error:         fmt.Sprintf(RepeatSummaryFmt, passed, count, time.Since(start).Round(time.Millisecond)),
+		Status:        result.Passed,
+	})
+	result.Fields = append(result.Fields, failures...)
+	return result, nil
+}
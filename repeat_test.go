@@ -0,0 +1,78 @@
+package arp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+)
+
+func TestRepeatKeepsLastAttemptValues(t *testing.T) {
+	var attempts int32
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		attempt := atomic.AddInt32(&attempts, 1)
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		// only the last attempt fails, so only the earlier ones store their values
+		if attempt == 3 {
+			w.Write([]byte(`{"name": 3, "seq": "late"}`))
+			return
+		}
+		fmt.Fprintf(w, `{"name": "arp", "seq": %v}`, attempt)
+	}))
+	defer server.Close()
+
+	file := filepath.Join(t.TempDir(), "tests.yaml")
+	tests := `
+tests:
+  - name: Repeated
+    route: "@{host}/seq"
+    method: GET
+    repeat: 3
+    response:
+      code: 200
+      capture:
+        latest: $.seq
+      payload:
+        name:
+          type: string
+          matches: $any
+          storeAs: global:name
+        seq:
+          type: integer
+          matches: $any
+          storeAs: seq
+`
+	if err := os.WriteFile(file, []byte(tests), 0600); err != nil {
+		t.Fatal(err)
+	}
+	suite, err := NewTestSuite(file, "", SuiteOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite.GlobalDataStore.Put(DS_HOST, server.URL)
+	if _, _, err := suite.ExecuteTests(nil); err != nil {
+		t.Fatal(err)
+	}
+
+	ds := suite.GlobalDataStore
+	if ds.Parent != nil {
+		t.Fatalf("expected the suite's store to be restored after the earlier attempts")
+	}
+	if v := ds.Get("latest"); v != "late" {
+		t.Errorf("expected the value captured by the last attempt but got %v", v)
+	}
+	if c := ds.Captures["latest"]; c.Value != "late" {
+		t.Errorf("expected the capture to be from the last attempt but got %v", c)
+	}
+	for _, key := range []string{"seq", "name"} {
+		if v, ok := ds.Store[key]; ok {
+			t.Errorf("expected '%v' stored by an earlier attempt to be discarded but got %v", key, v)
+		}
+		if c, ok := ds.Captures[key]; ok {
+			t.Errorf("expected the capture of '%v' by an earlier attempt to be discarded but got %v", key, c)
+		}
+	}
+}
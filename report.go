@@ -8,6 +8,8 @@ import (
 	"sort"
 	"strings"
 	"time"
+
+	"gopkg.in/yaml.v2"
 )
 
 const (
@@ -24,6 +26,20 @@ type ReportOptions struct {
 	ErrorsOnly         bool
 	TestsPath          string
 	Colors             Colorizer
+	// Masks sensitive values within headers, inputs, and responses
+	Redactor Redactor
+	// Print how long each matcher took to execute
+	MatcherTimings bool
+	// Print headers, inputs and responses as single line JSON
+	CompactJSON bool
+	// Number of spaces to indent nested JSON values by. Defaults to 1.
+	IndentSize int
+	// Print the distribution of request durations across the run, optionally grouped by method or route
+	TimingSummary bool
+	TimingGroup   string
+	// Hosts and dataset rows the tests were executed with, summarized separately at the end of the report
+	Hosts   []string
+	Dataset []DatasetRow
 	// Any failures while report is printed are suppresed and and indication
 	// is provided that the result data may be incomplete
 	InProgress bool
@@ -83,6 +99,21 @@ func (c *Colorizer) colorizeStr(input string, color string) string {
 	return fmt.Sprintf("%v%v%v", color, input, "\033[0m")
 }
 
+// FormatJSON marshals an object for printing in the report as either compact or indented JSON
+func (opts *ReportOptions) FormatJSON(obj interface{}) string {
+	if opts.CompactJSON {
+		data, _ := json.Marshal(obj)
+		return string(data)
+	}
+
+	indent := opts.IndentSize
+	if indent <= 0 {
+		indent = 1
+	}
+	data, _ := json.MarshalIndent(obj, IndentStr(2), IndentStr(indent))
+	return string(data)
+}
+
 func IndentStr(level int) string {
 	indents := ""
 	for i := 0; i < level; i++ {
@@ -193,7 +224,7 @@ func PrintSingleTestReport(opts ReportOptions, test *TestResult) {
 	details := test.TestCase
 	routeStr := fmt.Sprintf("[%v] %v", opts.Colors.BrightCyan(details.Config.Method), opts.Colors.BrightWhite(details.Config.Route))
 	statusStyle := ""
-	if test.TestCase.Config.Skip {
+	if test.TestCase.Config.Skip || test.TestCase.SkipTestOnEnvironment() {
 		statusStyle = "skipped"
 	}
 	if opts.InProgress {
@@ -260,6 +291,10 @@ func PrintSingleTestReport(opts ReportOptions, test *TestResult) {
 				shortStr = opts.Colors.BrightYellow("Pending next websocket message...")
 			}
 
+			if opts.MatcherTimings {
+				shortStr += opts.Colors.BrightGrey(fmt.Sprintf(" (%v)", f.Duration))
+			}
+
 			PrintIndentedLn(2, "[%v] %v: %v\n", getSuccessString(opts.Colors, f.Status, style),
 				fieldStr, shortStr)
 		}
@@ -269,30 +304,38 @@ func PrintSingleTestReport(opts ReportOptions, test *TestResult) {
 	if showExtendedReport {
 		PrintIndentedLn(2, "Route: %v\n", test.ResolvedRoute)
 		PrintIndentedLn(2, "Status Code: %v\n", test.StatusCode)
+		if test.RequestId != "" {
+			PrintIndentedLn(2, "Request ID: %v\n", test.RequestId)
+		}
 
 		if len(test.TestCase.Config.Headers) > 0 || opts.AlwaysPrintHeaders {
-			requestHeadersJson, _ := json.MarshalIndent(test.RequestHeaders, IndentStr(2), " ")
-			PrintIndentedLn(2, "Request Headers: %v\n", string(requestHeadersJson))
+			PrintIndentedLn(2, "Request Headers: %v\n", opts.FormatJSON(opts.Redactor.Redact(test.RequestHeaders)))
 		}
 
-		if len(test.TestCase.ResponseHeaderMatcher.Config) > 0 || opts.AlwaysPrintHeaders {
+		_, headerMatcher, _ := test.TestCase.GetResponseMatchers(test.StatusCode)
+		if len(headerMatcher.Config) > 0 || opts.AlwaysPrintHeaders {
 			// only print headers long output if the test case is validating any of them
-			headerJson, _ := json.MarshalIndent(test.ResponseHeaders, IndentStr(2), " ")
-			PrintIndentedLn(2, "Response Headers: %v\n", string(headerJson))
+			PrintIndentedLn(2, "Response Headers: %v\n", opts.FormatJSON(opts.Redactor.Redact(test.ResponseHeaders)))
 		}
 
+		// prefer the input that was sent since patched inputs differ from their definition
 		input := YamlToJson(test.TestCase.Config.Input)
-		inputJson, _ := json.MarshalIndent(input, IndentStr(2), " ")
-		PrintIndentedLn(2, "Input: %v\n", string(inputJson))
+		if test.ResolvedInput != nil {
+			input = YamlToJson(test.ResolvedInput)
+		}
+		PrintIndentedLn(2, "Input: %v\n", opts.FormatJSON(opts.Redactor.Redact(input)))
 
-		data, _ := json.MarshalIndent(test.Response, IndentStr(2), " ")
-		responsePage := PageText(string(data), MaxResponseLines)
+		responsePage := PageText(opts.FormatJSON(opts.Redactor.Redact(test.Response)), MaxResponseLines)
 		PrintIndentedLn(2, "Response: %v\n\n", responsePage)
 
 		PrintIndentedLn(2, "Extended Output:\n")
 		for _, f := range test.Fields {
 			if f.ShowExtendedMsg {
-				PrintIndentedLn(3, fmt.Sprintf("%v", f.ObjectKeyPath))
+				if opts.MatcherTimings {
+					PrintIndentedLn(3, fmt.Sprintf("%v (%v)", f.ObjectKeyPath, f.Duration))
+				} else {
+					PrintIndentedLn(3, fmt.Sprintf("%v", f.ObjectKeyPath))
+				}
 				PrintIndentedLn(5, fmt.Sprintf("%v:\n", f.Error))
 			}
 		}
@@ -313,7 +356,7 @@ func PrintReport(opts ReportOptions, passed bool, testingDuration time.Duration,
 
 		if !opts.Micro {
 			PrintIndentedLn(0, "[%v] %v\n", getSuccessString(opts.Colors, r.Passed, ""),
-				opts.Colors.Underline(opts.Colors.BrightWhite(r.TestFile)))
+				opts.Colors.Underline(opts.Colors.BrightWhite(r.Label())))
 			PrintIndentedLn(1, "Suite Duration: %v\n", r.TestResults.Duration)
 			PrintIndentedLn(1, "Passed: %v, Failed: %v, Total:%v\n", r.TestResults.Passed,
 				r.TestResults.Failed, r.TestResults.Total)
@@ -339,6 +382,53 @@ func PrintReport(opts ReportOptions, passed bool, testingDuration time.Duration,
 	PrintIndentedLn(0, "[%v] %v\n", getSuccessString(opts.Colors, passed, ""), opts.Colors.BrightWhite(path))
 	PrintIndentedLn(0, "%-6[2]d:Total Tests\n%-6[3]d:Passed\n%-6[4]d:Failed\n", globalPassed+globalFailed, globalPassed, globalFailed)
 	PrintIndentedLn(0, "\nTotal Execution Time: %v (CPU Time: %v)\n", testingDuration, globalTestDuration)
+	if len(opts.Hosts) > 0 {
+		PrintHostSummary(opts, results)
+	}
+	if len(opts.Dataset) > 0 {
+		PrintDatasetSummary(opts, results)
+	}
+	if opts.TimingSummary {
+		PrintTimingSummary(opts, results)
+	}
 	fmt.Printf("%v\n", separator(opts.Colors))
 
 }
+
+func explainValue(value interface{}, err error) interface{} {
+	if err != nil {
+		return fmt.Sprintf("<failed to resolve: %v>", err)
+	}
+	return value
+}
+
+// PrintTestExplanation prints what a test would send once all of its variables and inline commands are resolved.
+// Anything that fails to resolve is printed with its error in place of the value.
+func PrintTestExplanation(opts ReportOptions, test *TestCase) {
+	fmt.Printf("%v %v - %v\n", opts.Colors.BrightWhite(">>"), opts.Colors.BrightWhite(test.Config.Name), test.Config.Description)
+
+	explanation := yaml.MapSlice{}
+	if test.IsRPC {
+		addr, err := test.GetTestRpcAddr()
+		explanation = append(explanation,
+			yaml.MapItem{Key: "address", Value: explainValue(addr, err)},
+			yaml.MapItem{Key: "procedure", Value: test.Config.RPC.Procedure})
+	} else {
+		route, err := test.GetTestRoute()
+		explanation = append(explanation,
+			yaml.MapItem{Key: "method", Value: test.Config.Method},
+			yaml.MapItem{Key: "route", Value: explainValue(route, err)})
+	}
+
+	headers, err := test.GetTestHeaders(nil)
+	explanation = append(explanation, yaml.MapItem{Key: "headers", Value: explainValue(opts.Redactor.Redact(YamlToJson(headers)), err)})
+
+	input, err := test.GetResolvedTestInput()
+	explanation = append(explanation, yaml.MapItem{Key: "input", Value: explainValue(opts.Redactor.Redact(YamlToJson(input)), err)})
+
+	out, _ := PrintYamlObj(explanation)
+	for _, line := range strings.Split(strings.TrimSuffix(out, "\n"), "\n") {
+		PrintIndentedLn(1, "%v\n", line)
+	}
+	fmt.Printf("\n")
+}
@@ -0,0 +1,74 @@
+package arp
+
+import (
+	"fmt"
+	"net/http"
+)
+
+const (
+	CFG_RESPONSE_REQUEST_COUNT = "requestCount"
+
+	RequestCountPath = "response.RequestCount"
+)
+
+// requestCounter counts the requests sent through a transport, including those following redirects
+type requestCounter struct {
+	http.RoundTripper
+	Count int
+}
+
+func (c *requestCounter) RoundTrip(request *http.Request) (*http.Response, error) {
+	c.Count++
+	return c.RoundTripper.RoundTrip(request)
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport so that clients using the counter can
+// still release them
+func (c *requestCounter) CloseIdleConnections() {
+	if closer, ok := c.RoundTripper.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+// loadRequestCountMatcher loads the validation of the number of requests made by the test. Strings are treated as
+// integer expressions (e.g. '$<= 2') rather than strings.
+func (t *TestCase) loadRequestCountMatcher() error {
+	count := t.Config.Response.RequestCount
+	if count == nil {
+		return nil
+	}
+	if t.Config.Websocket {
+		return fmt.Errorf("'%v' is not supported for websocket tests: %v", CFG_RESPONSE_REQUEST_COUNT, t.Config.Name)
+	}
+
+	if s, ok := count.(string); ok {
+		count = map[interface{}]interface{}{
+			TEST_KEY_TYPE:    TYPE_INT,
+			TEST_KEY_MATCHES: s,
+		}
+	}
+	return t.RequestCountMatcher.loadResponseFields(map[string]interface{}{
+		CFG_RESPONSE_REQUEST_COUNT: count,
+	})
+}
+
+// checkRequestCount validates the number of requests made by an executed test, including every attempt when
+// polling, failing the result if it doesn't match.
+func (t *TestCase) checkRequestCount(result *TestResult) {
+	if len(t.RequestCountMatcher.Config) == 0 {
+		return
+	}
+
+	passed, results, err := t.RequestCountMatcher.Match(map[string]interface{}{
+		CFG_RESPONSE_REQUEST_COUNT: result.RequestCount,
+	})
+	for _, r := range results {
+		r.ObjectKeyPath = RequestCountPath
+		result.Fields = append(result.Fields, r)
+	}
+	if err != nil {
+		passed = false
+		result.Fields = append(result.Fields, validationError(RequestCountPath, err))
+	}
+	result.Passed = result.Passed && passed
+}
@@ -0,0 +1,87 @@
+package arp
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRequestCount(t *testing.T) {
+	polls := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/redirect":
+			http.Redirect(w, r, "/ok", http.StatusFound)
+			return
+		case "/poll":
+			polls++
+		}
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		fmt.Fprintf(w, `{"ready": %v}`, polls >= 3)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		path   string
+		count  string
+		passed bool
+	}{
+		{"single request", "/ok", "1", true},
+		{"wrong count", "/ok", "2", false},
+		{"redirect", "/redirect", "2", true},
+		{"redirect counted", "/redirect", "1", false},
+		{"expression", "/redirect", `"$<= 2"`, true},
+		{"failing expression", "/redirect", `"$> 2"`, false},
+		{"matcher", "/ok", "\n        type: integer\n        matches: \"$>= 1\"", true},
+		{"polling attempts", "/poll", "3", true},
+		{"polling attempts exceeded", "/poll", `"$< 3"`, false},
+	}
+
+	for _, tt := range tests {
+		polls = 0
+		poll := ""
+		if tt.path == "/poll" {
+			poll = "pollUntil:\n      interval: 1ms\n      timeout: 5s"
+		}
+		result := runTestFile(t, `
+tests:
+  - name: Request count
+    route: "@{host}`+tt.path+`"
+    method: GET
+    `+poll+`
+    response:
+      code: 200
+      requestCount: `+tt.count+`
+      payload:
+        ready: `+fmt.Sprint(tt.path == "/poll")+`
+`, server.URL, SuiteOptions{})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("%v: expected 1 result but got %v", tt.name, len(result.Results))
+		}
+		r := result.Results[0]
+		failed := failedFields(r)
+		if r.Passed != tt.passed {
+			t.Errorf("%v: expected the test to pass: %v but got:\n%v", tt.name, tt.passed, failed)
+		}
+		if !tt.passed && !strings.Contains(failed, RequestCountPath) {
+			t.Errorf("%v: expected %v to fail but got:\n%v", tt.name, RequestCountPath, failed)
+		}
+	}
+}
+
+func TestRequestCountWebsocket(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "tests.yaml")
+	def := "tests:\n  - name: Websocket\n    route: ws://localhost\n    websocket: true\n    response:\n      requestCount: 1\n"
+	if err := os.WriteFile(file, []byte(def), 0600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := NewTestSuite(file, "", SuiteOptions{}); err == nil {
+		t.Errorf("expected requestCount to be rejected for websocket tests")
+	}
+}
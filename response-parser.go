@@ -24,7 +24,8 @@ func (rh *ResponseParserHandler) LoadDefaults() {
 	(*rh) = make(map[string]ResponseParser)
 
 	rh.Register("json", &JSONParser{})
-	rh.Register("binary", &BinaryParser{})
+	rh.Register(CFG_RESPONSE_TYPE_BIN, &BinaryParser{})
+	rh.Register(CFG_RESPONSE_TYPE_NDJSON, &NDJSONParser{})
 }
 
 func (rh *ResponseParserHandler) Handle(test *TestCase, response *http.Response) (map[string]interface{}, interface{}, error) {
@@ -35,6 +36,23 @@ func (rh *ResponseParserHandler) Handle(test *TestCase, response *http.Response)
 		return nil, nil, fmt.Errorf("No response parser defined for type \"%v\"", responseType)
 	}
 
+	// streams are read within the limits defined by each test
+	if responseType == CFG_RESPONSE_TYPE_NDJSON {
+		parser = NewNDJSONParser(test)
+	} else if _, ok := parser.(*BinaryParser); ok && responseType == CFG_RESPONSE_TYPE_BIN {
+		binParser, err := NewBinaryParser(test)
+		if err != nil {
+			return nil, nil, err
+		}
+		js, raw, err := binParser.Parse(response)
+		if err != nil {
+			return nil, nil, err
+		}
+		return js, raw, test.storeSavedPath(js)
+	} else if responseType == CFG_RESPONSE_TYPE_JSON && test.AssumesJson() {
+		parser = &JSONParser{AssumeJson: true}
+	}
+
 	js, raw, err := parser.Parse(response)
 	if err == InvalidContentType {
 		// binary parser should always be available as a fallback option for unsupported/unexpected
@@ -0,0 +1,113 @@
+package arp
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+)
+
+const (
+	CFG_RESPONSE_RESPONSES = "responses"
+
+	StatusResponseKeyErrFmt = "Invalid status code '%v' in 'response.%v' of %v: expected a code (e.g. 404) or class (e.g. 4xx)"
+)
+
+// TestCaseStatusResponseCfg defines the payload and header validations of responses with a specific status code or
+// class, for endpoints that return different shapes for success and errors
+type TestCaseStatusResponseCfg struct {
+	Payload map[interface{}]interface{} `yaml:"payload"`
+	Headers map[interface{}]interface{} `yaml:"headers"`
+}
+
+// StatusResponse holds the matchers of a 'responses' block along with the status code or class it applies to
+type StatusResponse struct {
+	Code  *int64
+	Class *int64
+	// the status code or class as written in the test definition
+	Key                   string
+	Config                TestCaseStatusResponseCfg
+	ResponseMatcher       ResponseMatcher
+	ResponseHeaderMatcher ResponseMatcher
+}
+
+// parseStatusResponseKey parses the key of a 'responses' block as either an exact status code or a status class
+func parseStatusResponseKey(key interface{}) (code *int64, class *int64, ok bool) {
+	switch k := key.(type) {
+	case int:
+		c := int64(k)
+		return &c, nil, true
+	case string:
+		if cl, clOk := parseStatusClass(k); clOk {
+			return nil, &cl, true
+		}
+		if c, err := strconv.ParseInt(k, 10, 64); err == nil {
+			return &c, nil, true
+		}
+	}
+	return nil, nil, false
+}
+
+// loadStatusResponses creates the payload and header matchers of every block defined under 'response.responses'
+func (t *TestCase) loadStatusResponses() error {
+	t.StatusResponses = nil
+	for key, cfg := range t.Config.Response.Responses {
+		code, class, ok := parseStatusResponseKey(key)
+		if !ok {
+			return fmt.Errorf(StatusResponseKeyErrFmt, key, CFG_RESPONSE_RESPONSES, t.Config.Name)
+		}
+
+		sr := &StatusResponse{
+			Code:                  code,
+			Class:                 class,
+			Key:                   fmt.Sprintf("%v", key),
+			ResponseMatcher:       NewResponseMatcher(t.GlobalDataStore),
+			ResponseHeaderMatcher: NewResponseMatcher(t.GlobalDataStore),
+		}
+		if cfg != nil {
+			sr.Config = *cfg
+		}
+
+		if payload := sr.Config.Payload; payload != nil {
+			if err := sr.ResponseMatcher.loadObjectFields(payload, payload, FieldMatcherPath{}); err != nil {
+				return err
+			}
+		}
+		if headers := canonicalHeaderKeys(sr.Config.Headers); headers != nil {
+			if err := sr.ResponseHeaderMatcher.loadObjectFields(headers, headers, FieldMatcherPath{}); err != nil {
+				return err
+			}
+		}
+		t.StatusResponses = append(t.StatusResponses, sr)
+	}
+
+	// keep the order stable so that warnings and selection don't depend on map iteration
+	sort.Slice(t.StatusResponses, func(i, j int) bool {
+		return t.StatusResponses[i].Key < t.StatusResponses[j].Key
+	})
+	return nil
+}
+
+// GetStatusResponse returns the 'responses' block matching a status code. Exact codes take precedence over classes.
+// Nil is returned when no block matches, in which case the top level payload and headers are validated.
+func (t *TestCase) GetStatusResponse(statusCode int) *StatusResponse {
+	var classMatch *StatusResponse
+	for _, sr := range t.StatusResponses {
+		if sr.Code != nil && *sr.Code == int64(statusCode) {
+			return sr
+		}
+		if sr.Class != nil && *sr.Class == int64(statusCode/100) && classMatch == nil {
+			classMatch = sr
+		}
+	}
+	return classMatch
+}
+
+// GetResponseMatchers returns the payload and header matchers along with the payload definition used to validate a
+// response with the given status code.
+func (t *TestCase) GetResponseMatchers(statusCode int) (*ResponseMatcher, *ResponseMatcher,
+	map[interface{}]interface{}) {
+	if sr := t.GetStatusResponse(statusCode); sr != nil {
+		return &sr.ResponseMatcher, &sr.ResponseHeaderMatcher, sr.Config.Payload
+	}
+	return &t.ResponseMatcher, &t.ResponseHeaderMatcher, t.Config.Response.Payload
+}
@@ -1,5 +1,10 @@
 package arp
 
+import (
+	"fmt"
+	"strings"
+)
+
 type ResponseValidator interface {
 	Validate(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error)
 }
@@ -16,7 +21,17 @@ func (rvh *ResponseValidatorHandler) LoadDefaults() {
 }
 
 func (rvh *ResponseValidatorHandler) Handle(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error) {
+	passed, results, err := rvh.validate(test, result)
+	// the format of every HTTP response is validated, whichever validator handles its body
+	if !test.Config.Websocket && !test.IsRPC {
+		fPassed, fResults := validateResponseFormat(test, result)
+		passed = passed && fPassed
+		results = append(fResults, results...)
+	}
+	return passed, results, err
+}
 
+func (rvh *ResponseValidatorHandler) validate(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error) {
 	// if a custom validator is given, use it
 	if validator, exists := (*rvh)[test.Config.Response.Type]; exists {
 		return validator.Validate(test, result)
@@ -31,3 +46,115 @@ func (rvh *ResponseValidatorHandler) Handle(test *TestCase, result *TestResult)
 		return test.ResponseMatcher.Match(result.Response)
 	}
 }
+
+// validateResponseFormat validates the content type, transfer encoding, caching, cookies and security headers of an
+// HTTP response, along with its forbidden values. These don't depend on how the body is parsed, so they're validated
+// the same for every response type.
+func validateResponseFormat(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult) {
+	passed := true
+	var results []*FieldMatcherResult
+
+	// Validate content type
+	if len(test.ContentTypeMatcher.Config) > 0 {
+		cPassed, cResult, cErr := test.ContentTypeMatcher.Match(map[string]interface{}{
+			CFG_RESPONSE_MEDIA_TYPE: result.MediaType,
+			CFG_RESPONSE_CHARSET:    result.Charset,
+		})
+		for _, cR := range cResult {
+			if strings.HasSuffix(cR.ObjectKeyPath, CFG_RESPONSE_MEDIA_TYPE) {
+				cR.ObjectKeyPath = MediaTypePath
+			} else {
+				cR.ObjectKeyPath = CharsetPath
+			}
+			results = append(results, cR)
+		}
+		if cErr != nil {
+			cPassed = false
+			results = append(results, validationError(MediaTypePath, cErr))
+		}
+		passed = passed && cPassed
+	}
+
+	// Validate transfer encoding. The content length is omitted when the server didn't provide one so that it
+	// can be validated with 'exists: false'
+	if len(test.TransferMatcher.Config) > 0 {
+		transfer := map[string]interface{}{
+			CFG_RESPONSE_CHUNKED: result.Chunked,
+		}
+		if result.ContentLength >= 0 {
+			transfer[CFG_RESPONSE_CONTENT_LENGTH] = result.ContentLength
+		}
+		transfer[CFG_RESPONSE_LENGTH_MATCHES] = result.ContentLength < 0 || result.ContentLength == result.BodySize
+		tPassed, tResult, tErr := test.TransferMatcher.Match(transfer)
+		for _, tR := range tResult {
+			switch {
+			case strings.HasSuffix(tR.ObjectKeyPath, CFG_RESPONSE_CHUNKED):
+				tR.ObjectKeyPath = ChunkedPath
+			case strings.HasSuffix(tR.ObjectKeyPath, CFG_RESPONSE_LENGTH_MATCHES):
+				tR.ObjectKeyPath = LengthMatchesPath
+				if !tR.Status && result.ContentLength != result.BodySize {
+					tR.Error = fmt.Sprintf(LengthMismatchFmt, result.ContentLength, result.BodySize)
+				}
+			default:
+				tR.ObjectKeyPath = ContentLengthPath
+			}
+			results = append(results, tR)
+		}
+		if tErr != nil {
+			tPassed = false
+			results = append(results, validationError(ChunkedPath, tErr))
+		}
+		passed = passed && tPassed
+	}
+
+	// Validate whether the response was served from a cache
+	if len(test.CacheMatcher.Config) > 0 {
+		cPassed, cResult, cErr := test.CacheMatcher.Match(result.Cache)
+		for _, cR := range cResult {
+			cR.ObjectKeyPath = CachePath + cR.ObjectKeyPath
+			results = append(results, cR)
+		}
+		if cErr != nil {
+			cPassed = false
+			results = append(results, validationError(CachePath, cErr))
+		}
+		passed = passed && cPassed
+	}
+
+	// Validate the attributes of the cookies set by the response
+	if len(test.CookiesMatcher.Config) > 0 {
+		cPassed, cResult, cErr := test.CookiesMatcher.Match(result.Cookies)
+		for _, cR := range cResult {
+			cR.ObjectKeyPath = CookiesPath + cR.ObjectKeyPath
+			results = append(results, cR)
+		}
+		if cErr != nil {
+			cPassed = false
+			results = append(results, validationError(CookiesPath, cErr))
+		}
+		passed = passed && cPassed
+	}
+
+	securityPassed, securityResults := test.validateSecurityHeaders(result.ResponseHeaders)
+	results = append(results, securityResults...)
+	passed = passed && securityPassed
+
+	// Validate no value in the response matches a forbidden pattern
+	if len(test.Config.Response.Forbidden) > 0 {
+		for _, r := range test.validateForbidden(result.Response) {
+			results = append(results, r)
+			passed = passed && r.Status
+		}
+	}
+
+	if result.CharsetError != "" {
+		results = append(results, &FieldMatcherResult{
+			ObjectKeyPath: CharsetPath,
+			Error:         result.CharsetError,
+			Status:        false,
+		})
+		passed = false
+	}
+
+	return passed, results
+}
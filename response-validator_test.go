@@ -0,0 +1,118 @@
+package arp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestValidateResponseFormat(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/json":
+			w.Header().Set(HEADER_CONTENT_TYPE, "application/json; charset=UTF-8")
+			w.Write([]byte(`{"id": 1}`))
+		case "/stream":
+			w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+			w.Write([]byte(`{"id": `))
+			w.(http.Flusher).Flush()
+			w.Write([]byte(`1}`))
+		case "/short":
+			// declares more bytes than are sent before closing the connection
+			conn, buf, _ := w.(http.Hijacker).Hijack()
+			buf.WriteString("HTTP/1.1 200 OK\r\nContent-Type: application/json\r\nContent-Length: 100\r\n\r\n{}")
+			buf.Flush()
+			conn.Close()
+		case "/invalid-utf8":
+			w.Header().Set(HEADER_CONTENT_TYPE, "application/json; charset=utf-8")
+			w.Write([]byte("{\"name\": \"\xff\"}"))
+		case "/html":
+			w.Header().Set(HEADER_CONTENT_TYPE, "text/html; charset=utf-8")
+			w.Write([]byte(`<html><body><p title="123-45-6789">arp</p></body></html>`))
+		case "/binary":
+			w.Header().Set(HEADER_CONTENT_TYPE, "application/octet-stream")
+			w.Write([]byte{0, 1, 2, 3})
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		path     string
+		response string
+		failed   string
+	}{
+		{"media type and charset", "/json", "mediaType: application/json\ncharset: utf-8", ""},
+		{"wrong media type", "/json", "mediaType: text/plain", MediaTypePath},
+		{"wrong charset", "/json", "charset: iso-8859-1", CharsetPath},
+		{"valid charset", "/json", "charset: utf-8\nvalidateCharset: true", ""},
+		{"invalid charset", "/invalid-utf8", "validateCharset: true", CharsetPath},
+		{"content length", "/json", "chunked: false\ncontentLength: 9", ""},
+		{"wrong content length", "/json", "contentLength: 10", ContentLengthPath},
+		{"chunked", "/stream", "chunked: true\ncontentLength:\n  type: integer\n  exists: false", ""},
+		{"not chunked", "/json", "chunked: true", ChunkedPath},
+		{"content length matches", "/json", "contentLengthMatches: true", ""},
+		{"chunked content length matches", "/stream", "contentLengthMatches: true", ""},
+		{"content length mismatch", "/short", "contentLengthMatches: true", LengthMatchesPath},
+		{"html media type", "/html", "type: html\nmediaType: text/html", ""},
+		{"wrong html media type", "/html", "type: html\nmediaType: application/json", MediaTypePath},
+		{"html cache", "/html", "type: html\ncache:\n  fromCache: false", ""},
+		{"wrong html cache", "/html", "type: html\ncache:\n  fromCache: true", CachePath},
+		{"missing html cookie", "/html", "type: html\ncookies:\n  session:\n    type: object\n    properties:\n      Value: abc",
+			CookiesPath},
+		{"missing html security headers", "/html", "type: html\nsecurityHeaders: true", SecurityHeadersPath},
+		{"html without forbidden values", "/html", "type: html\nforbidden:\n  - secret", ""},
+		{"html forbidden value", "/html", "type: html\nforbidden:\n  - '\\d{3}-\\d{2}-\\d{4}'", ".attributes.title"},
+		{"binary media type", "/binary", "type: binary\nmediaType: application/octet-stream", ""},
+		{"wrong binary media type", "/binary", "type: binary\nmediaType: text/plain", MediaTypePath},
+		{"binary content length", "/binary", "type: binary\ncontentLength: 4", ""},
+		{"wrong binary content length", "/binary", "type: binary\ncontentLength: 5", ContentLengthPath},
+	}
+
+	for _, tt := range tests {
+		result := runTestFile(t, `
+tests:
+  - name: Format
+    route: "@{host}`+tt.path+`"
+    method: GET
+    response:
+      code: 200
+      `+strings.ReplaceAll(tt.response, "\n", "\n      ")+`
+`, server.URL, SuiteOptions{})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("%v: expected 1 result but got %v", tt.name, len(result.Results))
+		}
+		r := result.Results[0]
+		failed := failedFields(r)
+		if tt.failed == "" && !r.Passed {
+			t.Errorf("%v: expected the test to pass but got:\n%v", tt.name, failed)
+		}
+		if tt.failed != "" && (r.Passed || !strings.Contains(failed, tt.failed)) {
+			t.Errorf("%v: expected %v to fail but got:\n%v", tt.name, tt.failed, failed)
+		}
+	}
+}
+
+func TestValidateResponseFormatUnsupported(t *testing.T) {
+	tests := []string{
+		"websocket: true\n    response:\n      mediaType: application/json",
+		"websocket: true\n    response:\n      chunked: false",
+		"rpc:\n      protocol: http\n      address: \"@{host}\"\n      procedure: Users.Get\n    response:\n      charset: utf-8",
+		"rpc:\n      protocol: http\n      address: \"@{host}\"\n      procedure: Users.Get\n    response:\n      contentLength: 10",
+	}
+
+	for _, tt := range tests {
+		file := filepath.Join(t.TempDir(), "tests.yaml")
+		def := "tests:\n  - name: Unsupported\n    route: \"@{host}\"\n    " + tt + "\n"
+		if err := os.WriteFile(file, []byte(def), 0600); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := NewTestSuite(file, "", SuiteOptions{}); err == nil {
+			t.Errorf("expected the test to be rejected:\n%v", def)
+		}
+	}
+}
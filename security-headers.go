@@ -0,0 +1,183 @@
+package arp
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"strings"
+)
+
+const (
+	CFG_RESPONSE_SECURITY_HEADERS = "securityHeaders"
+
+	// keys of the object form of 'securityHeaders'
+	SECURITY_KEY_PROFILE = "profile"
+	SECURITY_KEY_HEADERS = "headers"
+
+	SECURITY_PROFILE_BASELINE = "baseline"
+	SECURITY_PROFILE_STRICT   = "strict"
+
+	SecurityHeaderMarker = "[security] "
+
+	SecurityHeadersPath       = "response.SecurityHeaders"
+	SecurityHeadersMissingFmt = "Missing %v of %v required headers: %v"
+	SecurityHeadersPresentFmt = "All %v required headers are present"
+)
+
+var (
+	// patterns the values of the headers required by each profile must match
+	securityProfiles = map[string]map[string]string{
+		SECURITY_PROFILE_BASELINE: {
+			"Strict-Transport-Security": `max-age=\d+`,
+			"X-Content-Type-Options":    `^(?i)nosniff$`,
+			"X-Frame-Options":           `^(?i)(DENY|SAMEORIGIN)$`,
+			"Content-Security-Policy":   NotEmpty,
+		},
+		SECURITY_PROFILE_STRICT: {
+			"Strict-Transport-Security": `(?i)max-age=\d+.*includeSubDomains`,
+			"X-Content-Type-Options":    `^(?i)nosniff$`,
+			"X-Frame-Options":           `^(?i)(DENY|SAMEORIGIN)$`,
+			"Content-Security-Policy":   NotEmpty,
+			"Referrer-Policy":           `^(?i)(no-referrer|same-origin|strict-origin|strict-origin-when-cross-origin)$`,
+			"Permissions-Policy":        NotEmpty,
+		},
+	}
+)
+
+func supportedSecurityProfiles() string {
+	var profiles []string
+	for p := range securityProfiles {
+		profiles = append(profiles, p)
+	}
+	sort.Strings(profiles)
+	return strings.Join(profiles, ", ")
+}
+
+// securityHeaderDefinitions resolves the 'securityHeaders' option of a test into the names of the required headers
+// and matcher definitions for their values. The option is either 'true' for the baseline profile, the name of a
+// profile, or an object with a profile and headers whose patterns replace those of the profile. Headers set to 'false'
+// are not required.
+func securityHeaderDefinitions(cfg interface{}) ([]string, map[interface{}]interface{}, error) {
+	profile := SECURITY_PROFILE_BASELINE
+	var overrides map[interface{}]interface{}
+
+	switch v := cfg.(type) {
+	case nil:
+		return nil, nil, nil
+	case bool:
+		if !v {
+			return nil, nil, nil
+		}
+	case string:
+		profile = v
+	case map[interface{}]interface{}:
+		if p, ok := v[SECURITY_KEY_PROFILE]; ok {
+			profile = fmt.Sprintf("%v", p)
+		}
+		if h, ok := v[SECURITY_KEY_HEADERS]; ok {
+			if overrides, ok = h.(map[interface{}]interface{}); !ok {
+				return nil, nil, fmt.Errorf("'%v.%v' must be a map of header names to patterns", CFG_RESPONSE_SECURITY_HEADERS,
+					SECURITY_KEY_HEADERS)
+			}
+		}
+	default:
+		return nil, nil, fmt.Errorf("'%v' must be true, a profile name or an object", CFG_RESPONSE_SECURITY_HEADERS)
+	}
+
+	required, ok := securityProfiles[profile]
+	if !ok {
+		return nil, nil, fmt.Errorf("unknown '%v' profile '%v'. Supported profiles are: %v", CFG_RESPONSE_SECURITY_HEADERS,
+			profile, supportedSecurityProfiles())
+	}
+
+	patterns := make(map[string]interface{})
+	for name, pattern := range required {
+		patterns[name] = pattern
+	}
+	// response headers are keyed by their canonical names, so overrides are too
+	for k, v := range overrides {
+		name := http.CanonicalHeaderKey(fmt.Sprintf("%v", k))
+		if v == false {
+			delete(patterns, name)
+			continue
+		}
+		patterns[name] = fmt.Sprintf("%v", v)
+	}
+
+	var names []string
+	defs := make(map[interface{}]interface{})
+	for name, pattern := range patterns {
+		names = append(names, name)
+		defs[name] = map[interface{}]interface{}{
+			TEST_KEY_TYPE:    TYPE_STR,
+			TEST_KEY_MATCHES: pattern,
+		}
+	}
+	sort.Strings(names)
+	return names, defs, nil
+}
+
+// loadSecurityHeaders loads a matcher for the value of each security header required by the test's 'securityHeaders'
+// option. They run alongside any of the test's own matchers for the same headers.
+func (t *TestCase) loadSecurityHeaders() error {
+	names, defs, err := securityHeaderDefinitions(t.Config.Response.SecurityHeaders)
+	if err != nil {
+		return fmt.Errorf("%v for %v", err, t.Config.Name)
+	}
+
+	t.SecurityHeaders = names
+	t.SecurityHeaderMatchers = make(map[string]*ResponseMatcher)
+	for _, name := range names {
+		def := map[interface{}]interface{}{name: defs[name]}
+		matcher := NewResponseMatcher(t.GlobalDataStore)
+		if err := matcher.loadObjectFields(def, def, FieldMatcherPath{}); err != nil {
+			return fmt.Errorf("invalid '%v' for %v: %v", CFG_RESPONSE_SECURITY_HEADERS, t.Config.Name, err)
+		}
+		for _, c := range matcher.Config {
+			c.Marker = SecurityHeaderMarker
+		}
+		t.SecurityHeaderMatchers[name] = &matcher
+	}
+	return nil
+}
+
+// validateSecurityHeaders validates the first value of each required security header the response set and reports
+// which of them are missing
+func (t *TestCase) validateSecurityHeaders(headers map[string]interface{}) (bool, []*FieldMatcherResult) {
+	if len(t.SecurityHeaders) == 0 {
+		return true, nil
+	}
+
+	status := true
+	var results []*FieldMatcherResult
+	var missing []string
+	for _, name := range t.SecurityHeaders {
+		values, ok := headers[name].([]interface{})
+		if !ok || len(values) == 0 {
+			missing = append(missing, name)
+			continue
+		}
+
+		passed, headerResults, err := t.SecurityHeaderMatchers[name].Match(map[string]interface{}{name: values[0]})
+		for _, r := range headerResults {
+			r.ObjectKeyPath = HeadersPath + r.ObjectKeyPath
+			results = append(results, r)
+		}
+		if err != nil {
+			passed = false
+			results = append(results, validationError(HeadersPath, err))
+		}
+		status = status && passed
+	}
+
+	summary := &FieldMatcherResult{
+		ObjectKeyPath: SecurityHeadersPath,
+		Error:         SecurityHeaderMarker + fmt.Sprintf(SecurityHeadersPresentFmt, len(t.SecurityHeaders)),
+		Status:        len(missing) == 0,
+	}
+	if len(missing) > 0 {
+		summary.Error = SecurityHeaderMarker + fmt.Sprintf(SecurityHeadersMissingFmt, len(missing), len(t.SecurityHeaders),
+			strings.Join(missing, ", "))
+	}
+	return status && summary.Status, append(results, summary)
+}
@@ -0,0 +1,94 @@
+package arp
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+const (
+	CFG_SLA = "sla"
+
+	SLAExceededFmt = "Duration %v exceeded the %v SLA of tag '%v'"
+	SLAMetFmt      = "Duration %v is within the %v SLA of tag '%v'"
+)
+
+// ParseSLAs parses a comma separated list of tag=duration pairs (e.g. smoke=500ms,search=2s) into the maximum
+// durations allowed for tests with each tag.
+func ParseSLAs(list string) (map[string]time.Duration, error) {
+	slas := make(map[string]time.Duration)
+	for _, pair := range strings.Split(list, ",") {
+		if strings.TrimSpace(pair) == "" {
+			continue
+		}
+		kv := strings.SplitN(pair, "=", 2)
+		if len(kv) < 2 {
+			return nil, fmt.Errorf("invalid SLA '%v': expected tag=duration", pair)
+		}
+		duration, err := time.ParseDuration(strings.TrimSpace(kv[1]))
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLA duration for tag '%v': %v", kv[0], err)
+		}
+		slas[strings.TrimSpace(kv[0])] = duration
+	}
+	return slas, nil
+}
+
+// LoadSLAFile reads a YAML file mapping tags to the maximum duration allowed for tests with that tag.
+func LoadSLAFile(path string) (map[string]time.Duration, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read SLA file: %v", err)
+	}
+
+	var raw map[string]string
+	if err := yaml.Unmarshal(data, &raw); err != nil {
+		return nil, fmt.Errorf("failed to parse SLA file: %v - %v", path, err)
+	}
+
+	slas := make(map[string]time.Duration)
+	for tag, v := range raw {
+		duration, err := time.ParseDuration(v)
+		if err != nil {
+			return nil, fmt.Errorf("invalid SLA duration for tag '%v': %v", tag, err)
+		}
+		slas[tag] = duration
+	}
+	return slas, nil
+}
+
+// checkSLAs validates the duration of an executed test against the SLA of each of its tags, failing the result if
+// any of them are exceeded.
+func (t *TestCase) checkSLAs(result *TestResult) {
+	if len(t.SLAs) == 0 {
+		return
+	}
+
+	var tags []string
+	for tag := range t.Tags {
+		if _, ok := t.SLAs[tag]; ok {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+
+	delta := time.Since(result.StartTime).Round(time.Millisecond)
+	for _, tag := range tags {
+		sla := t.SLAs[tag]
+		status := delta <= sla
+		msg := SLAMetFmt
+		if !status {
+			msg = SLAExceededFmt
+		}
+		result.Fields = append(result.Fields, &FieldMatcherResult{
+			ObjectKeyPath: fmt.Sprintf("test.%v.%v", CFG_SLA, tag),
+			Error:         fmt.Sprintf(msg, delta, sla, tag),
+			Status:        status,
+		})
+		result.Passed = result.Passed && status
+	}
+}
@@ -1,11 +1,15 @@
 package arp
 
 import (
+	"bytes"
+	"context"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
+	"runtime/debug"
 	"strings"
+	"sync"
 	"time"
 
 	"gopkg.in/yaml.v2"
@@ -17,12 +21,75 @@ const (
 	PrevTestFailMsg    = "Test skipped due to a previous unrecoverable test execution error"
 	TestFailMsgTrailer = ": Remaining tests within suite will automatically fail"
 	IndexExceedsDSFmt  = "Index for data store value exceeds its max length: %v"
+	TimedOutFmt        = "Test run timed out: %v"
+	PanicFmt           = "Test execution panicked: %v\n%s"
 	StatusCodePath     = "response.StatusCode"
 	HeadersPath        = "response.Header"
+	PayloadPath        = "response.Payload"
+	MediaTypePath      = "response.MediaType"
+	CharsetPath        = "response.Charset"
+	ChunkedPath        = "response.Chunked"
+	ContentLengthPath  = "response.ContentLength"
+	LengthMatchesPath  = "response.ContentLengthMatches"
+	LengthMismatchFmt  = "Declared Content-Length of %v bytes but the body contained %v bytes"
+
+	// suffix of the fixtures file loaded automatically for the test file of the same name, e.g. users.fixtures.yaml
+	// for users.yaml
+	SiblingFixturesSuffix = ".fixtures.yaml"
+)
+
+var (
+	stdinFixtures     []byte
+	stdinFixturesErr  error
+	stdinFixturesOnce sync.Once
 )
 
 type TestSuiteCfg struct {
-	Tests []TestCaseCfg `yaml:"tests"`
+	DefaultHeaders map[interface{}]interface{} `yaml:"defaultHeaders"`
+	Tests          []TestCaseCfg               `yaml:"tests"`
+	// header names mapped to the patterns their values match when a response is served from a cache
+	CacheRules map[string]string `yaml:"cacheRules"`
+	// validations every response in the file must pass in addition to those of its test
+	GlobalAssertions *GlobalAssertionsCfg `yaml:"globalAssertions"`
+	// endpoint to poll until the service under test is ready before executing any tests
+	WaitFor *WaitForCfg `yaml:"waitFor"`
+}
+
+// SuiteOptions configures how a test suite is initialized and executed
+type SuiteOptions struct {
+	// Skip seeding the data store with the system's environment variables
+	NoEnv bool
+	// Only seed the data store with environment variables starting with this prefix
+	EnvPrefix string
+	// Treat a test file without any tests as an error
+	RequireTests bool
+	// Print warnings about problems in test definitions such as unknown matcher keys
+	Lint bool
+	// Treat any warnings about test definitions as errors
+	Strict bool
+	// Only load the test with this exact name
+	TestName string
+	// Name of an environment variable containing the fixtures YAML. Takes precedence over a fixtures path.
+	FixturesEnv string
+	// Name of a header to send a unique request ID in with every HTTP request that doesn't already set it.
+	// Disabled when empty.
+	RequestIdHeader string
+	// Maximum durations allowed for tests with each tag
+	SLAs map[string]time.Duration
+	// Environment the tests are executed against. Tests limited to other environments are skipped.
+	Environment string
+	// Number of times to execute each test that doesn't define its own 'repeat'
+	Repeat int
+	// Validations every response of the run must pass, merged with those of each test file
+	GlobalAssertions *GlobalAssertionsCfg
+	// Parse responses as JSON even when their content type isn't JSON or text
+	AssumeJson bool
+	// How test files hosted at http(s) URLs are fetched
+	Remote RemoteOptions
+	// Name of a test to print the raw HTTP requests and responses of
+	Trace string
+	// Masks the values of sensitive headers in traces
+	TraceRedactor Redactor
 }
 
 type TestSuite struct {
@@ -30,6 +97,10 @@ type TestSuite struct {
 	Tests           []*TestCase
 	GlobalDataStore DataStore
 	Verbose         bool
+	Options         SuiteOptions
+	Warnings        []string
+	Context         context.Context
+	WaitFor         *WaitForCfg
 }
 
 type SuiteResult struct {
@@ -38,12 +109,15 @@ type SuiteResult struct {
 	Failed   int
 	Total    int
 	Duration time.Duration
+	// values stored from test responses during the run
+	Captures map[string]CapturedValue
 }
 
-func NewTestSuite(testFile string, fixtures string) (*TestSuite, error) {
+func NewTestSuite(testFile string, fixtures string, opts SuiteOptions) (*TestSuite, error) {
 	suite := &TestSuite{
 		GlobalDataStore: NewDataStore(),
 		File:            testFile,
+		Options:         opts,
 	}
 
 	err := suite.InitializeDataStore(fixtures)
@@ -58,6 +132,10 @@ func NewTestSuite(testFile string, fixtures string) (*TestSuite, error) {
 		return suite, fmt.Errorf("failed to initialize test suite: %v", err)
 	}
 
+	if opts.RequireTests && len(suite.Tests) == 0 {
+		return suite, fmt.Errorf("no tests found in test file: %v", testFile)
+	}
+
 	return suite, nil
 }
 
@@ -67,30 +145,112 @@ func (t *TestSuite) ReloadFile(fixtures string) (bool, error) {
 }
 
 func (t *TestSuite) InitializeDataStore(fixtures string) error {
-	f, err := t.LoadFixtures(fixtures)
+	sources, err := t.fixtureSources(fixtures)
 	if err != nil {
 		return err
 	}
 
-	for k := range f {
-		t.GlobalDataStore.Put(k, f[k])
+	// sibling fixtures are loaded after the global ones and will shadow any global keys of the same name
+	for _, source := range sources {
+		f, err := t.LoadFixtures(source)
+		if err != nil {
+			return err
+		}
+
+		for k := range f {
+			t.GlobalDataStore.Put(k, f[k])
+		}
+	}
+
+	if t.Options.NoEnv {
+		return nil
 	}
 
+	// environment variables are added after the fixtures and will shadow any fixture keys of the same name
 	for _, env := range os.Environ() {
 		pair := strings.SplitN(env, "=", 2)
+		if !strings.HasPrefix(pair[0], t.Options.EnvPrefix) {
+			continue
+		}
 		t.GlobalDataStore.Put(pair[0], pair[1])
 	}
 
 	return nil
 }
 
-func (t *TestSuite) LoadFixtures(fixtures string) (map[string]interface{}, error) {
-	var config map[interface{}]interface{}
+// SiblingFixturesPath returns the path of the fixtures file belonging to a test file, e.g. users.fixtures.yaml for
+// users.yaml, or an empty string if it doesn't exist
+func SiblingFixturesPath(testFile string) string {
+	if testFile == "-" || IsSiblingFixtures(testFile) || IsRemoteFile(testFile) {
+		return ""
+	}
+
+	path := strings.TrimSuffix(testFile, filepath.Ext(testFile)) + SiblingFixturesSuffix
+	if info, err := os.Stat(path); err != nil || info.IsDir() {
+		return ""
+	}
+	return path
+}
+
+// IsSiblingFixtures returns whether a file is the fixtures file of a test file rather than a test file itself
+func IsSiblingFixtures(path string) bool {
+	return strings.HasSuffix(path, SiblingFixturesSuffix)
+}
+
+// fixtureSources returns readers for the global fixtures followed by the sibling fixtures of the test file, skipping
+// any that aren't provided
+func (t *TestSuite) fixtureSources(fixtures string) ([]io.Reader, error) {
+	var sources []io.Reader
+
+	global, err := t.FixturesReader(fixtures)
+	if err != nil {
+		return nil, err
+	}
+	if global != nil {
+		sources = append(sources, global)
+	}
+
+	if sibling := SiblingFixturesPath(t.File); sibling != "" {
+		data, err := os.ReadFile(sibling)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read fixtures file: %v - %v", sibling, err)
+		}
+		sources = append(sources, bytes.NewReader(data))
+	}
+	return sources, nil
+}
+
+// FixturesReader returns a reader for the fixtures data. Fixtures can be provided through an environment variable
+// (see SuiteOptions.FixturesEnv), through stdin with a path of '-', or as a file path. A nil reader is returned
+// if no fixtures are provided.
+func (t *TestSuite) FixturesReader(fixtures string) (io.Reader, error) {
+	if t.Options.FixturesEnv != "" {
+		data, ok := os.LookupEnv(t.Options.FixturesEnv)
+		if !ok {
+			return nil, fmt.Errorf("fixtures environment variable is not set: %v", t.Options.FixturesEnv)
+		}
+		return strings.NewReader(data), nil
+	}
 
 	if fixtures == "" {
 		return nil, nil
 	}
 
+	if fixtures == "-" {
+		if t.File == "-" {
+			return nil, fmt.Errorf("fixtures and test file cannot both be read from stdin")
+		}
+
+		// stdin can only be read once, so share its contents with every suite
+		stdinFixturesOnce.Do(func() {
+			stdinFixtures, stdinFixturesErr = io.ReadAll(os.Stdin)
+		})
+		if stdinFixturesErr != nil {
+			return nil, fmt.Errorf("failed to read fixtures from stdin: %v", stdinFixturesErr)
+		}
+		return bytes.NewReader(stdinFixtures), nil
+	}
+
 	fileInfo, err := os.Stat(fixtures)
 	if err != nil {
 		return nil, fmt.Errorf("failed to stat fixture file: %v - %v", fixtures, err)
@@ -105,9 +265,28 @@ func (t *TestSuite) LoadFixtures(fixtures string) (map[string]interface{}, error
 		return nil, fmt.Errorf("failed to read fixtures file: %v - %v", fixtures, err)
 	}
 
+	return bytes.NewReader(data), nil
+}
+
+func (t *TestSuite) LoadFixtures(source io.Reader) (map[string]interface{}, error) {
+	var config map[interface{}]interface{}
+
+	if source == nil {
+		return nil, nil
+	}
+
+	data, err := io.ReadAll(source)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read fixtures: %v", err)
+	}
+
 	err = yaml.Unmarshal(data, &config)
 	if err != nil {
-		return nil, fmt.Errorf("failed to unmarshal fixture file: %v - %v", fixtures, err)
+		return nil, fmt.Errorf("failed to unmarshal fixtures: %v", err)
+	}
+
+	if config == nil {
+		return nil, nil
 	}
 
 	return YamlToJson(config).(map[string]interface{}), nil
@@ -116,27 +295,36 @@ func (t *TestSuite) LoadFixtures(fixtures string) (map[string]interface{}, error
 func (t *TestSuite) Close() {
 	for _, test := range t.Tests {
 		test.CloseWebsocket()
+		test.RemoveTempFiles()
 	}
 }
 
 func (t *TestSuite) LoadTests(fixtures string) (bool, error) {
 	var readers []io.Reader
 
-	if fixtures != "" {
-		fix, err := os.Open(fixtures)
-		if err != nil {
-			return false, fmt.Errorf("failed to open fixture file: %v - %v", fixtures, err)
-		}
-
-		readers = append(readers, fix)
+	sources, err := t.fixtureSources(fixtures)
+	if err != nil {
+		return false, err
+	}
+	for _, fix := range sources {
+		// fixtures from an environment variable may not end with a newline
+		readers = append(readers, fix, strings.NewReader("\n"))
 	}
 
-	var tests *os.File
-	var err error
+	var tests io.Reader
 	if t.File == "-" {
 		tests = os.Stdin
+	} else if IsRemoteFile(t.File) {
+		var data []byte
+		if data, err = FetchRemoteFile(t.Context, t.File, t.Options.Remote); err == nil {
+			tests = bytes.NewReader(data)
+		}
 	} else {
-		tests, err = os.Open(t.File)
+		var file *os.File
+		if file, err = os.Open(t.File); err == nil {
+			defer file.Close()
+			tests = file
+		}
 	}
 	if err != nil {
 		return false, fmt.Errorf("failed to open test file: %v - %v", t.File, err)
@@ -151,8 +339,13 @@ func (t *TestSuite) LoadTests(fixtures string) (bool, error) {
 	if err != nil {
 		return false, fmt.Errorf("failed to load test file: %v - %v", t.File, err)
 	}
-	fp, _ := filepath.Abs(filepath.Dir(t.File))
-	t.GlobalDataStore.Put("TEST_DIR", fp)
+	// files referenced by remote test files are resolved from the working directory
+	testDir := filepath.Dir(t.File)
+	if IsRemoteFile(t.File) {
+		testDir = "."
+	}
+	fp, _ := filepath.Abs(testDir)
+	t.GlobalDataStore.Put(DS_TEST_DIR, fp)
 
 	var testSuiteCfg TestSuiteCfg
 
@@ -161,22 +354,103 @@ func (t *TestSuite) LoadTests(fixtures string) (bool, error) {
 		return false, fmt.Errorf("failed to load test file: %v - %v", t.File, err)
 	}
 
+	cacheRules, err := ParseCacheRules(testSuiteCfg.CacheRules)
+	if err != nil {
+		return false, fmt.Errorf("failed to load test file: %v - %v", t.File, err)
+	}
+
+	if wait := testSuiteCfg.WaitFor; wait != nil {
+		if wait.Url == "" {
+			return false, fmt.Errorf("failed to load test file: %v - '%v' requires a url", t.File, CFG_WAIT_FOR)
+		}
+		if _, _, err := wait.GetDurations(); err != nil {
+			return false, fmt.Errorf("failed to load test file: %v - %v", t.File, err)
+		}
+	}
+	t.WaitFor = testSuiteCfg.WaitFor
+
+	globalAssertions := MergeGlobalAssertions(t.Options.GlobalAssertions, testSuiteCfg.GlobalAssertions)
+	if _, err := globalAssertions.GetMaxDuration(); err != nil {
+		return false, fmt.Errorf("failed to load test file: %v - %v", t.File, err)
+	}
+
+	t.Warnings = nil
 	for _, test := range testSuiteCfg.Tests {
 		tCase := TestCase{
-			GlobalDataStore: &t.GlobalDataStore,
+			GlobalDataStore:  &t.GlobalDataStore,
+			RequestIdHeader:  t.Options.RequestIdHeader,
+			SLAs:             t.Options.SLAs,
+			Environment:      t.Options.Environment,
+			CacheRules:       cacheRules,
+			Repeat:           t.Options.Repeat,
+			GlobalAssertions: globalAssertions,
+			AssumeJson:       t.Options.AssumeJson,
+			Trace:            t.Options.Trace != "" && t.Options.Trace == test.Name,
+			TraceRedactor:    t.Options.TraceRedactor,
 		}
+		test.Headers = mergeHeaders(testSuiteCfg.DefaultHeaders, test.Headers)
 
 		err = tCase.LoadConfig(&test)
 		if err != nil {
 			return false, fmt.Errorf("failed to load test file: %v - %v", t.File, err)
 		}
 
+		for _, w := range tCase.Warnings {
+			t.Warnings = append(t.Warnings, fmt.Sprintf("%v: %v", t.File, w))
+		}
 		t.Tests = append(t.Tests, &tCase)
 	}
 
+	if t.Options.TestName != "" {
+		if err := t.selectTest(t.Options.TestName); err != nil {
+			return false, err
+		}
+	}
+
+	if t.Options.Lint {
+		for _, w := range t.Warnings {
+			fmt.Printf("Warning: %v\n", w)
+		}
+	}
+
+	if t.Options.Strict && len(t.Warnings) > 0 {
+		return false, fmt.Errorf("test file contains %v problem(s):\n%v", len(t.Warnings), strings.Join(t.Warnings, "\n"))
+	}
+
 	return true, nil
 }
 
+// selectTest narrows the suite's tests down to the single test with the given name
+func (t *TestSuite) selectTest(name string) error {
+	var selected []*TestCase
+	for _, test := range t.Tests {
+		if test.Config.Name == name {
+			selected = append(selected, test)
+		}
+	}
+
+	if len(selected) == 0 {
+		return fmt.Errorf("no test named %q found in test file: %v", name, t.File)
+	} else if len(selected) > 1 {
+		return fmt.Errorf("test name %q is ambiguous, %v tests share it in test file: %v", name, len(selected), t.File)
+	}
+
+	t.Tests = selected
+	return nil
+}
+
+// executeTest executes a single test, converting any panic into a failed result so that the remaining tests can
+// still run and report.
+func executeTest(test *TestCase, testTags []string) (passed bool, result *TestResult, err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			passed, err = false, nil
+			result = test.GetStubbedFailResult(fmt.Sprintf(PanicFmt, r, debug.Stack()))
+		}
+	}()
+	return test.Execute(testTags)
+}
+
 func (t *TestSuite) ExecuteTests(testTags []string) (bool, SuiteResult, error) {
 	defer t.Close()
 
@@ -187,6 +461,8 @@ func (t *TestSuite) ExecuteTests(testTags []string) (bool, SuiteResult, error) {
 		Total:   len(t.Tests),
 	}
 
+	// none of the tests can pass if the service never became ready
+	waitErr := t.waitForService()
 	var criticalError error
 
 	for _, test := range t.Tests {
@@ -197,11 +473,16 @@ func (t *TestSuite) ExecuteTests(testTags []string) (bool, SuiteResult, error) {
 		if t.Verbose {
 			fmt.Printf(">> In Progress: %v\n", test.Config.Name)
 		}
+		test.Context = t.Context
+		t.GlobalDataStore.CaptureSource = test.Config.Name
 
 		var passed bool
 		var results *TestResult
-		if criticalError == nil {
-			passed, results, criticalError = test.Execute(testTags)
+		if waitErr != nil {
+			passed = false
+			results = test.GetStubbedFailResult(waitErr.Error())
+		} else if criticalError == nil {
+			passed, results, criticalError = executeTest(test, testTags)
 			if criticalError != nil {
 				results = test.GetStubbedFailResult(criticalError.Error() + TestFailMsgTrailer)
 			}
@@ -228,6 +509,10 @@ func (t *TestSuite) ExecuteTests(testTags []string) (bool, SuiteResult, error) {
 		suiteResults.Duration += results.EndTime.Sub(results.StartTime)
 		suiteResults.Results = append(suiteResults.Results, results)
 	}
+	suiteResults.Captures = t.GlobalDataStore.Captures
 
+	if waitErr != nil {
+		return false, suiteResults, waitErr
+	}
 	return !anyFailed, suiteResults, criticalError
 }
@@ -0,0 +1,101 @@
+package arp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// runTestFile executes the tests of a YAML test file with 'host' set to the given URL, returning the results
+func runTestFile(t *testing.T, tests string, host string, opts SuiteOptions) SuiteResult {
+	t.Helper()
+	file := filepath.Join(t.TempDir(), "tests.yaml")
+	if err := os.WriteFile(file, []byte(tests), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	suite, err := NewTestSuite(file, "", opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	suite.GlobalDataStore.Put(DS_HOST, host)
+	_, result, err := suite.ExecuteTests(nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return result
+}
+
+// failedFields returns the messages of the failing validations of a result
+func failedFields(result *TestResult) string {
+	var failed []string
+	for _, f := range result.Fields {
+		if !f.Status {
+			failed = append(failed, f.ObjectKeyPath+": "+f.Error)
+		}
+	}
+	return strings.Join(failed, "\n")
+}
+
+// panicExt panics while parsing any response
+type panicExt struct{}
+
+func (e *panicExt) Parse(response *http.Response) (map[string]interface{}, interface{}, error) {
+	panic("parser failure")
+}
+
+func (e *panicExt) Validate(test *TestCase, result *TestResult) (bool, []*FieldMatcherResult, error) {
+	return false, nil, nil
+}
+
+func TestExecuteTestsRecoversPanics(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	registered := AvailableExtensions
+	defer func() { AvailableExtensions = registered }()
+	RegisterExtension("panic", &panicExt{})
+
+	result := runTestFile(t, `
+tests:
+  - name: Panics
+    route: "@{host}"
+    method: GET
+    response:
+      type: panic
+  - name: Runs
+    route: "@{host}"
+    method: GET
+    response:
+      code: 200
+      payload:
+        id: 1
+`, server.URL, SuiteOptions{})
+
+	if len(result.Results) != 2 {
+		t.Fatalf("expected 2 results but got %v", len(result.Results))
+	}
+	if r := result.Results[0]; r.Passed || !strings.Contains(failedFields(r), "Test execution panicked: parser failure") {
+		t.Errorf("expected the panic to be reported as a failure but got:\n%v", failedFields(r))
+	}
+	if r := result.Results[1]; !r.Passed {
+		t.Errorf("expected the test after the panic to run:\n%v", failedFields(r))
+	}
+}
+
+func TestExecuteSuiteRecoversPanics(t *testing.T) {
+	// a worker without a suite panics before any of its tests are executed
+	r := executeSuite(MultiSuiteWorker{TestFile: "missing.yaml"})
+	if r.Passed || r.TestFile != "missing.yaml" {
+		t.Errorf("expected a failed result for the test file but got %+v", r)
+	}
+	if r.Error == nil || !strings.HasPrefix(r.Error.Error(), "Test execution panicked: ") {
+		t.Errorf("expected the panic to be reported but got %v", r.Error)
+	}
+}
@@ -0,0 +1,91 @@
+package arp
+
+import (
+	"bytes"
+	"crypto/md5"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"math/rand"
+	"strings"
+	"sync"
+	"text/template"
+	"time"
+
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	// rand.Rand isn't safe for concurrent use and templates are rendered by every suite worker
+	templateRand   = rand.New(rand.NewSource(time.Now().UnixNano()))
+	templateRandMu sync.Mutex
+
+	// Functions made available to input templates
+	TemplateFuncs = template.FuncMap{
+		// random integer in the range [min, max)
+		"random": func(min int, max int) int {
+			if max <= min {
+				return min
+			}
+			return min + templateIntn(max-min)
+		},
+		// random alphanumeric string of the given length
+		"randomString": func(length int) string {
+			const chars = "abcdefghijklmnopqrstuvwxyzABCDEFGHIJKLMNOPQRSTUVWXYZ0123456789"
+			b := make([]byte, length)
+			for i := range b {
+				b[i] = chars[templateIntn(len(chars))]
+			}
+			return string(b)
+		},
+		"sha256": func(input interface{}) string {
+			sum := sha256.Sum256([]byte(varToString(input)))
+			return hex.EncodeToString(sum[:])
+		},
+		"md5": func(input interface{}) string {
+			sum := md5.Sum([]byte(varToString(input)))
+			return hex.EncodeToString(sum[:])
+		},
+		"base64": func(input interface{}) string {
+			return base64.StdEncoding.EncodeToString([]byte(varToString(input)))
+		},
+		"json":  ToJsonStr,
+		"lower": strings.ToLower,
+		"upper": strings.ToUpper,
+		"now": func() string {
+			return time.Now().UTC().Format(time.RFC3339)
+		},
+		// execute an inline command, e.g. {{ cmd "date -u -R" }}
+		"cmd": func(command string) (interface{}, error) {
+			return ExecuteCommand(CMD_PREFIX + command + CMD_SUFFIX)
+		},
+	}
+)
+
+func templateIntn(n int) int {
+	templateRandMu.Lock()
+	defer templateRandMu.Unlock()
+	return templateRand.Intn(n)
+}
+
+// RenderInputTemplate executes a text/template using the data store as its context and parses the result
+// as YAML (which also accepts JSON).
+func RenderInputTemplate(name string, input string, ds *DataStore) (interface{}, error) {
+	tmpl, err := template.New(name).Funcs(TemplateFuncs).Option("missingkey=error").Parse(input)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse input template: %v", err)
+	}
+
+	var rendered bytes.Buffer
+	if err := tmpl.Execute(&rendered, ds.Variables()); err != nil {
+		return nil, fmt.Errorf("failed to execute input template: %v", err)
+	}
+
+	var node interface{}
+	if err := yaml.Unmarshal(rendered.Bytes(), &node); err != nil {
+		return nil, fmt.Errorf("failed to parse rendered input template: %v\n%v", err, rendered.String())
+	}
+
+	return node, nil
+}
@@ -0,0 +1,91 @@
+package arp
+
+import (
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+)
+
+func TestRenderInputTemplate(t *testing.T) {
+	ds := NewDataStore()
+	ds.Put("names", []interface{}{"alice", "bob"})
+	ds.Put("isAdmin", true)
+
+	rendered, err := RenderInputTemplate("Users", "users:\n{{- range .names }}\n  - {{ . }}\n{{- end }}\n{{- if .isAdmin }}\nrole: admin\n{{- end }}\ntoken: {{ randomString 8 }}\n", &ds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	input := YamlToJson(rendered).(map[string]interface{})
+	if fmt.Sprint(input["users"]) != "[alice bob]" || input["role"] != "admin" || len(fmt.Sprint(input["token"])) != 8 {
+		t.Errorf("unexpected rendered input: %v", ToJsonStr(input))
+	}
+
+	for _, tmpl := range []string{"{{ .missing }}", "{{ if }}"} {
+		if _, err := RenderInputTemplate("Invalid", tmpl, &ds); err == nil {
+			t.Errorf("expected '%v' to fail", tmpl)
+		}
+	}
+}
+
+func TestRenderInputTemplateConcurrently(t *testing.T) {
+	ds := NewDataStore()
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 100; j++ {
+				if _, err := RenderInputTemplate("Random", "id: {{ random 1 100 }}\nname: {{ randomString 16 }}\n", &ds); err != nil {
+					t.Error(err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+}
+
+func TestTemplatedInput(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		w.Write(body)
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name    string
+		input   string
+		payload string
+		passed  bool
+	}{
+		{"templated", "template: true\n      body: |\n        {{- if .host }}\n        host: {{ .host }}\n        {{- end }}", "host: \"^http://\"", true},
+		{"template with other keys", "template: true\n      body: \"id: 1\"\n      name: test", "template: true\n        name: test", true},
+		{"template disabled", "template: false\n      body: \"id: 1\"", "body: \"id: 1\"", true},
+	}
+
+	for _, tt := range tests {
+		result := runTestFile(t, `
+tests:
+  - name: Templated input
+    route: "@{host}/users"
+    method: POST
+    input:
+      `+tt.input+`
+    response:
+      code: 200
+      payload:
+        `+tt.payload+`
+`, server.URL, SuiteOptions{})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("%v: expected 1 result but got %v", tt.name, len(result.Results))
+		}
+		if r := result.Results[0]; r.Passed != tt.passed {
+			t.Errorf("%v: expected the test to pass: %v but got:\n%v", tt.name, tt.passed, failedFields(r))
+		}
+	}
+}
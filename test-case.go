@@ -2,11 +2,13 @@ package arp
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"mime/multipart"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -17,9 +19,22 @@ import (
 
 const (
 	// Test Config keys
-	CFG_SKIP          = "skip"
-	CFG_TAGS          = "tags"
-	CFG_RESPONSE_CODE = "code"
+	CFG_SKIP                    = "skip"
+	CFG_TAGS                    = "tags"
+	CFG_ENVIRONMENTS            = "environments"
+	CFG_RESPONSE_CODE           = "code"
+	CFG_RESPONSE_MEDIA_TYPE     = "mediaType"
+	CFG_RESPONSE_CHARSET        = "charset"
+	CFG_RESPONSE_CHUNKED        = "chunked"
+	CFG_RESPONSE_CONTENT_LENGTH = "contentLength"
+	CFG_RESPONSE_LENGTH_MATCHES = "contentLengthMatches"
+	CFG_POLL_UNTIL              = "pollUntil"
+
+	DEFAULT_POLL_INTERVAL = time.Second
+	DEFAULT_POLL_TIMEOUT  = 30 * time.Second
+
+	PollPassedFmt = "Validation passed after %v attempt(s) over %v"
+	PollFailedFmt = "Validation did not pass after %v attempt(s) over %v"
 
 	CFG_RESPONSE_TYPE_BIN  = "binary"
 	CFG_RESPONSE_TYPE_JSON = "json"
@@ -37,6 +52,23 @@ const (
 
 	//DataStore Vars
 	DS_WS_CLIENT = "ws"
+	DS_HOST      = "host"
+	DS_TEST_DIR  = "TEST_DIR"
+	// the response of the most recently executed test
+	DS_PREV_RESPONSE = "PREV_RESPONSE"
+	// a copy of the resolved input of the test being executed
+	DS_REQUEST_INPUT = "REQUEST_INPUT"
+	// query parameters of the route requested by the test
+	DS_REQUEST_QUERY = "REQUEST_QUERY"
+	// status and headers of the handshake response of the websocket client
+	DS_WS_HANDSHAKE = "wsHandshake"
+
+	// Input keys for building a request body from an earlier response
+	INPUT_KEY_FROM  = "from"
+	INPUT_KEY_PATCH = "patch"
+	// Input keys for rendering a request body from a text/template
+	INPUT_KEY_TEMPLATE = "template"
+	INPUT_KEY_BODY     = "body"
 )
 
 type TestCaseRpcCfg struct {
@@ -45,6 +77,16 @@ type TestCaseRpcCfg struct {
 	Procedure string `yaml:"procedure"`
 }
 
+type TestCaseRequestCfg struct {
+	// encoding of the request body, either json (default) or msgpack
+	Encoding string `yaml:"encoding"`
+}
+
+type TestCasePollCfg struct {
+	Interval string `yaml:"interval"`
+	Timeout  string `yaml:"timeout"`
+}
+
 type TestCaseResponseCfg struct {
 	// status code could end up being either a number or an object defining a validation definition
 	StatusCode interface{}                 `yaml:"code"`
@@ -52,22 +94,72 @@ type TestCaseResponseCfg struct {
 	FilePath   string                      `yaml:"filePath"`
 	Payload    map[interface{}]interface{} `yaml:"payload"`
 	Headers    map[interface{}]interface{} `yaml:"headers"`
+	// variable to store the path a binary response was saved to in
+	StoreAs string `yaml:"storeAs"`
+	// media type and charset could also be either a string or an object defining a validation definition
+	MediaType       interface{} `yaml:"mediaType"`
+	Charset         interface{} `yaml:"charset"`
+	ValidateCharset bool        `yaml:"validateCharset"`
+	Chunked         interface{} `yaml:"chunked"`
+	ContentLength   interface{} `yaml:"contentLength"`
+	// whether the declared content length matches the number of bytes in the body. Always true for responses
+	// without a declared length, such as chunked responses.
+	ContentLengthMatches interface{} `yaml:"contentLengthMatches"`
+	// fail when the response contains fields or array elements that aren't declared in the payload
+	Exact bool `yaml:"exact"`
+	// JSON paths of volatile values excluded from 'exact' and 'openApiExample' comparisons
+	Ignore []string `yaml:"ignore"`
+	// parse the response as JSON even when its content type isn't JSON or text, overriding the option of the run
+	AssumeJson *bool `yaml:"assumeJson"`
+	// patterns that no string value anywhere in the response may match
+	Forbidden []string `yaml:"forbidden"`
+	// compare the response against an example response from an OpenAPI spec
+	Example *TestCaseExampleCfg `yaml:"openApiExample"`
+	// whether the response was served from a cache and the values of common cache headers
+	Cache map[interface{}]interface{} `yaml:"cache"`
+	// attributes of the cookies set by the response, keyed by cookie name
+	Cookies map[interface{}]interface{} `yaml:"cookies"`
+	// security headers the response must set: true for the baseline profile, the name of a profile, or an object
+	// with a profile and header patterns
+	SecurityHeaders interface{} `yaml:"securityHeaders"`
+	// field level errors the response must contain, in the '{errors: [{field, message}]}' shape unless configured
+	ValidationError interface{} `yaml:"expectValidationError"`
+	// limits for reading streamed responses, such as ndjson
+	MaxLines    int    `yaml:"maxLines"`
+	ReadTimeout string `yaml:"readTimeout"`
+	// number of requests the test is expected to make, including every attempt when polling and any redirects
+	RequestCount interface{} `yaml:"requestCount"`
+	// JSON paths of the response to store under each name, whether or not its validations pass
+	Capture map[string]string `yaml:"capture"`
+	// payload and header validations keyed by status code (e.g. 404) or class (e.g. 4xx), used in place of the
+	// top level ones when the response status matches
+	Responses map[interface{}]*TestCaseStatusResponseCfg `yaml:"responses"`
 }
 
 type TestCaseCfg struct {
-	Name        string                      `yaml:"name"`
-	Description string                      `yaml:"description"`
-	ExitOnRun   bool                        `yaml:"exit"`
-	Skip        bool                        `yaml:"skip"`
-	Input       map[interface{}]interface{} `yaml:"input"`
-	FormInput   bool                        `yaml:"formInput"`
-	Tags        []string                    `yaml:"tags"`
-	Headers     map[interface{}]interface{} `yaml:"headers"`
-	Route       string                      `yaml:"route"`
-	Method      string                      `yaml:"method"`
-	RPC         TestCaseRpcCfg              `yaml:"rpc"`
-	Websocket   bool                        `yaml:"websocket"`
-	Response    TestCaseResponseCfg         `yaml:"response"`
+	Name         string                      `yaml:"name"`
+	Description  string                      `yaml:"description"`
+	ExitOnRun    bool                        `yaml:"exit"`
+	Skip         bool                        `yaml:"skip"`
+	Input        map[interface{}]interface{} `yaml:"input"`
+	FormInput    bool                        `yaml:"formInput"`
+	Request      TestCaseRequestCfg          `yaml:"request"`
+	Tags         []string                    `yaml:"tags"`
+	Environments []string                    `yaml:"environments"`
+	Headers      map[interface{}]interface{} `yaml:"headers"`
+	Route        string                      `yaml:"route"`
+	Host         string                      `yaml:"host"`
+	Proxy        string                      `yaml:"proxy"`
+	HostHeader   string                      `yaml:"hostHeader"`
+	Method       string                      `yaml:"method"`
+	RPC          TestCaseRpcCfg              `yaml:"rpc"`
+	Websocket    bool                        `yaml:"websocket"`
+	Isolated     bool                        `yaml:"isolated"`
+	PollUntil    *TestCasePollCfg            `yaml:"pollUntil"`
+	Repeat       int                         `yaml:"repeat"`
+	AssertEquals *TestCaseAssertCfg          `yaml:"assertEquals"`
+	Response     TestCaseResponseCfg         `yaml:"response"`
+	After        *TestCaseAfterCfg           `yaml:"after"`
 }
 
 type TestCase struct {
@@ -75,9 +167,50 @@ type TestCase struct {
 	IsRPC                 bool
 	ResponseHeaderMatcher ResponseMatcher
 	StatusCodeMatcher     ResponseMatcher
+	ContentTypeMatcher    ResponseMatcher
+	TransferMatcher       ResponseMatcher
+	CacheMatcher          ResponseMatcher
+	CookiesMatcher        ResponseMatcher
+	RequestCountMatcher   ResponseMatcher
 	ResponseMatcher       ResponseMatcher
 	GlobalDataStore       *DataStore
 	Tags                  map[string]bool
+	Warnings              []string
+	// matchers for websocket messages defining their own expectations, keyed by the message index
+	WebsocketMatchers map[int]*ResponseMatcher
+	StatusResponses   []*StatusResponse
+	Context           context.Context
+	PollInterval      time.Duration
+	PollTimeout       time.Duration
+	ReadTimeout       time.Duration
+	RequestIdHeader   string
+	// header rules used to detect whether a response was served from a cache
+	CacheRules []CacheRule
+	// maximum durations allowed for tests with each tag
+	SLAs map[string]time.Duration
+	// environment the tests are executed against, compared with the environments each test is enabled for
+	Environment string
+	// number of times to execute tests that don't define their own 'repeat'
+	Repeat int
+	// validations of the run and test file that every response must pass
+	GlobalAssertions  *GlobalAssertionsCfg
+	GlobalMaxDuration time.Duration
+	// parse responses as JSON regardless of their content type unless a test sets its own 'assumeJson'
+	AssumeJson bool
+	// maximum duration of the test's 'after' command
+	AfterTimeout time.Duration
+	// paths of the response excluded from exact and example comparisons
+	IgnoredPaths IgnoredPaths
+	// names of the security headers the response must set and the matchers for their values
+	SecurityHeaders        []string
+	SecurityHeaderMatchers map[string]*ResponseMatcher
+	// validation errors the response must contain
+	ValidationErrors []*ValidationErrorExpectation
+	// print the raw HTTP requests and responses of the test, masking sensitive headers with the redactor
+	Trace         bool
+	TraceRedactor Redactor
+	// temporary files created for the test, removed once its suite has been executed
+	TempFiles []string
 }
 
 type TestResult struct {
@@ -89,9 +222,22 @@ type TestResult struct {
 	ResponseHeaders map[string]interface{}
 	RequestHeaders  http.Header
 	ResolvedRoute   string
+	ResolvedInput   interface{}
 	StatusCode      int
+	MediaType       string
+	Charset         string
+	CharsetError    string
+	RequestId       string
+	Chunked         bool
+	ContentLength   int64
+	BodySize        int64
+	Cache           map[string]interface{}
+	Cookies         map[string]interface{}
 	StartTime       time.Time
 	EndTime         time.Time
+	// results of the per message websocket expectations
+	MessageFields []*FieldMatcherResult
+	RequestCount  int
 }
 
 type InputReader struct {
@@ -123,18 +269,58 @@ func (t *TestCase) HasTag(tagList string) bool {
 	return false
 }
 
+// resolveTags generates the mapping of the test's tags, resolving any variables within them (e.g. 'env-@{deployEnv}').
+// Tags with variables that can't be resolved yet, such as those provided with '-var' after the test is loaded, are
+// kept as they are until the test is executed.
+func (t *TestCase) resolveTags() {
+	t.Tags = make(map[string]bool)
+	for _, tag := range t.Config.Tags {
+		if t.GlobalDataStore != nil {
+			if resolved, err := t.GlobalDataStore.ExpandVariable(tag); err == nil {
+				tag = varToString(resolved, tag)
+			}
+		}
+		t.Tags[tag] = true
+	}
+}
+
 func (t *TestCase) LoadConfig(test *TestCaseCfg) error {
+	// isolated tests read the suite's variables but keep any they store to themselves
+	if test.Isolated && t.GlobalDataStore != nil && t.GlobalDataStore.Parent == nil {
+		child := NewChildDataStore(t.GlobalDataStore)
+		t.GlobalDataStore = &child
+	}
+
 	t.ResponseMatcher = NewResponseMatcher(t.GlobalDataStore)
 	t.ResponseHeaderMatcher = NewResponseMatcher(t.GlobalDataStore)
 	t.StatusCodeMatcher = NewResponseMatcher(t.GlobalDataStore)
+	t.ContentTypeMatcher = NewResponseMatcher(t.GlobalDataStore)
+	t.TransferMatcher = NewResponseMatcher(t.GlobalDataStore)
+	t.CacheMatcher = NewResponseMatcher(t.GlobalDataStore)
+	t.CookiesMatcher = NewResponseMatcher(t.GlobalDataStore)
+	t.RequestCountMatcher = NewResponseMatcher(t.GlobalDataStore)
 	t.Config = *test
 
-	switch t.Config.Response.Type {
-	case CFG_RESPONSE_TYPE_JSON, CFG_RESPONSE_TYPE_BIN, CFG_RESPONSE_TYPE_HTML:
-	case "":
+	if t.CacheRules == nil {
+		t.CacheRules, _ = ParseCacheRules(nil)
+	}
+
+	if t.Config.Response.Type == "" {
 		t.Config.Response.Type = CFG_RESPONSE_TYPE_JSON
-	default:
-		return fmt.Errorf("Invalid 'response.type' specified for %v: %v", t.Config.Name, t.Config.Response.Type)
+	} else if !IsKnownResponseType(t.Config.Response.Type) {
+		return fmt.Errorf("Invalid 'response.type' specified for %v: %v. Expected one of: %v", t.Config.Name,
+			t.Config.Response.Type, strings.Join(KnownResponseTypes(), ", "))
+	}
+
+	if t.Config.Response.StoreAs != "" && t.Config.Response.Type != CFG_RESPONSE_TYPE_BIN {
+		return fmt.Errorf("'response.storeAs' is only supported for '%v' responses: %v", CFG_RESPONSE_TYPE_BIN, t.Config.Name)
+	}
+
+	if t.Config.Response.ReadTimeout != "" {
+		var err error
+		if t.ReadTimeout, err = time.ParseDuration(t.Config.Response.ReadTimeout); err != nil {
+			return fmt.Errorf("Invalid 'response.readTimeout' specified for %v: %v", t.Config.Name, err)
+		}
 	}
 
 	if t.Config.RPC.Address != "" && t.Config.RPC.Procedure != "" && t.Config.RPC.Protocol != "" {
@@ -147,15 +333,19 @@ func (t *TestCase) LoadConfig(test *TestCaseCfg) error {
 		t.Config.Method = "WS"
 	}
 
+	if t.Config.AssertEquals != nil {
+		if t.Config.Response.Payload != nil {
+			return fmt.Errorf("response payload validations are not supported for %v tests: %v", CFG_ASSERT_EQUALS, t.Config.Name)
+		}
+		t.Config.Method = ASSERT_METHOD
+	}
+
 	if t.Config.Method == "" || t.Config.Response.Type == CFG_RESPONSE_TYPE_HTML {
 		t.Config.Method = "GET"
 	}
 
 	// generate a mapping for tags to improve look up times
-	t.Tags = make(map[string]bool)
-	for _, tag := range t.Config.Tags {
-		t.Tags[tag] = true
-	}
+	t.resolveTags()
 
 	// Start loading our matchers
 	sc := t.Config.Response.StatusCode
@@ -168,14 +358,76 @@ func (t *TestCase) LoadConfig(test *TestCaseCfg) error {
 			if err := t.StatusCodeMatcher.loadField(sc, statusMatcher, keyPath); err != nil {
 				return err
 			}
+		} else if scStr, sOk := sc.(string); sOk {
+			// string status codes are treated as integer expressions or classes (e.g. 2xx) rather than strings
+			statusMatcher := map[interface{}]interface{}{
+				TEST_KEY_TYPE:    TYPE_INT,
+				TEST_KEY_MATCHES: scStr,
+			}
+			if err := t.StatusCodeMatcher.loadField(sc, statusMatcher, keyPath); err != nil {
+				return err
+			}
 		} else {
-
 			if err := t.StatusCodeMatcher.loadSimplifiedField(sc, sc, keyPath); err != nil {
 				return err
 			}
 		}
 	}
 
+	if err := t.ContentTypeMatcher.loadResponseFields(map[string]interface{}{
+		CFG_RESPONSE_MEDIA_TYPE: t.Config.Response.MediaType,
+		CFG_RESPONSE_CHARSET:    t.Config.Response.Charset,
+	}); err != nil {
+		return err
+	}
+
+	if err := t.TransferMatcher.loadResponseFields(map[string]interface{}{
+		CFG_RESPONSE_CHUNKED:        t.Config.Response.Chunked,
+		CFG_RESPONSE_CONTENT_LENGTH: t.Config.Response.ContentLength,
+		CFG_RESPONSE_LENGTH_MATCHES: t.Config.Response.ContentLengthMatches,
+	}); err != nil {
+		return err
+	}
+	if (t.Config.Websocket || t.IsRPC) && (len(t.ContentTypeMatcher.Config) > 0 || len(t.TransferMatcher.Config) > 0) {
+		return fmt.Errorf("content type and transfer encoding validations are not supported for websocket or RPC tests: %v",
+			t.Config.Name)
+	}
+
+	if example := t.Config.Response.Example; example != nil {
+		if example.Spec == "" || (example.OperationId == "" && example.Path == "") {
+			return fmt.Errorf("Invalid '%v' specified for %v: expected a spec and either an operationId or path",
+				CFG_RESPONSE_EXAMPLE, t.Config.Name)
+		}
+		if example.Match != "" && example.Match != EXAMPLE_MATCH_EXACT && example.Match != EXAMPLE_MATCH_STRUCTURE {
+			return fmt.Errorf("Invalid '%v' match specified for %v: %v. Expected one of: %v, %v", CFG_RESPONSE_EXAMPLE,
+				t.Config.Name, example.Match, EXAMPLE_MATCH_EXACT, EXAMPLE_MATCH_STRUCTURE)
+		}
+	}
+
+	if len(t.Config.Response.Ignore) > 0 {
+		if !t.Config.Response.Exact && t.Config.Response.Example == nil {
+			return fmt.Errorf("'%v' requires 'exact' or '%v' for %v", CFG_RESPONSE_IGNORE, CFG_RESPONSE_EXAMPLE, t.Config.Name)
+		}
+		var err error
+		if t.IgnoredPaths, err = ParseIgnoredPaths(t.Config.Response.Ignore); err != nil {
+			return fmt.Errorf("%v for %v", err, t.Config.Name)
+		}
+	}
+
+	cache := t.Config.Response.Cache
+	if cache != nil {
+		if err := t.CacheMatcher.loadObjectFields(cache, cache, FieldMatcherPath{}); err != nil {
+			return err
+		}
+	}
+
+	cookies := t.Config.Response.Cookies
+	if cookies != nil {
+		if err := t.CookiesMatcher.loadObjectFields(cookies, cookies, FieldMatcherPath{}); err != nil {
+			return err
+		}
+	}
+
 	payload := t.Config.Response.Payload
 	if payload != nil {
 		if err := t.ResponseMatcher.loadObjectFields(payload, payload, FieldMatcherPath{}); err != nil {
@@ -183,7 +435,7 @@ func (t *TestCase) LoadConfig(test *TestCaseCfg) error {
 		}
 	}
 
-	respHeaders := t.Config.Response.Headers
+	respHeaders := canonicalHeaderKeys(t.Config.Response.Headers)
 	if respHeaders != nil {
 		if err := t.ResponseHeaderMatcher.
 			loadObjectFields(respHeaders, respHeaders, FieldMatcherPath{}); err != nil {
@@ -191,15 +443,191 @@ func (t *TestCase) LoadConfig(test *TestCaseCfg) error {
 		}
 	}
 
+	if err := t.loadStatusResponses(); err != nil {
+		return err
+	}
+
+	if t.Config.Websocket {
+		if err := t.loadWebsocketMatchers(); err != nil {
+			return err
+		}
+	}
+
+	if err := t.loadPollConfig(); err != nil {
+		return err
+	}
+
+	if err := t.loadRequestCountMatcher(); err != nil {
+		return err
+	}
+
+	if err := t.loadRequestEncoding(); err != nil {
+		return err
+	}
+
+	if err := t.loadAfterHook(); err != nil {
+		return err
+	}
+
+	if err := t.loadSecurityHeaders(); err != nil {
+		return err
+	}
+
+	if err := t.loadValidationErrors(); err != nil {
+		return err
+	}
+
+	globalWarnings, err := t.loadGlobalAssertions()
+	if err != nil {
+		return fmt.Errorf("%v for %v", err, t.Config.Name)
+	}
+
+	matchers := []*ResponseMatcher{&t.StatusCodeMatcher, &t.ContentTypeMatcher, &t.TransferMatcher, &t.CacheMatcher, &t.CookiesMatcher, &t.RequestCountMatcher, &t.ResponseMatcher, &t.ResponseHeaderMatcher}
+	for _, m := range t.WebsocketMatchers {
+		matchers = append(matchers, m)
+	}
+	for _, sr := range t.StatusResponses {
+		matchers = append(matchers, &sr.ResponseMatcher, &sr.ResponseHeaderMatcher)
+	}
+	t.Warnings = nil
+	for _, m := range matchers {
+		for _, w := range m.Warnings {
+			t.Warnings = append(t.Warnings, fmt.Sprintf("%v: %v", t.Config.Name, w))
+		}
+	}
+	for _, w := range globalWarnings {
+		t.Warnings = append(t.Warnings, fmt.Sprintf("%v: %v %v", t.Config.Name, CFG_GLOBAL_ASSERTIONS, w))
+	}
+
+	return nil
+}
+
+// loadPollConfig parses the interval and timeout of a 'pollUntil' definition, falling back to defaults for any
+// that are missing.
+func (t *TestCase) loadPollConfig() error {
+	poll := t.Config.PollUntil
+	if poll == nil {
+		return nil
+	}
+	if t.Config.Websocket {
+		return fmt.Errorf("'%v' is not supported for websocket tests: %v", CFG_POLL_UNTIL, t.Config.Name)
+	}
+
+	t.PollInterval = DEFAULT_POLL_INTERVAL
+	t.PollTimeout = DEFAULT_POLL_TIMEOUT
+	var err error
+	if poll.Interval != "" {
+		if t.PollInterval, err = time.ParseDuration(poll.Interval); err != nil {
+			return fmt.Errorf("invalid '%v' interval for test '%v': %v", CFG_POLL_UNTIL, t.Config.Name, err)
+		}
+	}
+	if poll.Timeout != "" {
+		if t.PollTimeout, err = time.ParseDuration(poll.Timeout); err != nil {
+			return fmt.Errorf("invalid '%v' timeout for test '%v': %v", CFG_POLL_UNTIL, t.Config.Name, err)
+		}
+	}
+	return nil
+}
+
+// loadWebsocketMatchers creates a matcher for every websocket message that defines an `expect` block so its
+// response can be validated as soon as it is read.
+func (t *TestCase) loadWebsocketMatchers() error {
+	t.WebsocketMatchers = make(map[int]*ResponseMatcher)
+
+	requests, ok := t.Config.Input[WS_REQUESTS].([]interface{})
+	if !ok {
+		return nil
+	}
+
+	for i, r := range requests {
+		msg, mOk := r.(map[interface{}]interface{})
+		if !mOk {
+			continue
+		}
+
+		expect, eOk := msg[WS_EXPECT]
+		if !eOk {
+			continue
+		}
+
+		expectObj, eOk := expect.(map[interface{}]interface{})
+		if !eOk {
+			return fmt.Errorf("Invalid '%v' specified for websocket message %v of %v: expected an object", WS_EXPECT, i, t.Config.Name)
+		}
+
+		matcher := NewResponseMatcher(t.GlobalDataStore)
+		if err := matcher.loadObjectFields(expectObj, expectObj, FieldMatcherPath{}); err != nil {
+			return err
+		}
+		t.WebsocketMatchers[i] = &matcher
+
+		// expectations are not part of the message, so keep them from being resolved and sent as input
+		delete(msg, WS_EXPECT)
+	}
+
 	return nil
 }
 
 func (t *TestCase) GetTestRoute() (string, error) {
-	resolvedRoute, err := t.GlobalDataStore.ExpandVariable(t.Config.Route)
+	route := t.Config.Route
+	if t.Config.Host != "" {
+		resolvedHost, err := t.GlobalDataStore.ExpandVariable(t.Config.Host)
+		if err != nil {
+			return "", err
+		}
+		host := varToString(resolvedHost, t.Config.Host)
+
+		hostVar := VAR_PREFIX + DS_HOST + VAR_SUFFIX
+		if strings.Contains(route, hostVar) {
+			route = strings.ReplaceAll(route, hostVar, host)
+		} else if !strings.Contains(route, "://") && !strings.HasPrefix(route, VAR_PREFIX) {
+			route = strings.TrimSuffix(host, "/") + "/" + strings.TrimPrefix(route, "/")
+		}
+	}
+
+	resolvedRoute, err := t.GlobalDataStore.ExpandVariable(route)
+	if err != nil {
+		return "", err
+	}
+	return varToString(resolvedRoute, route), nil
+}
+
+// GetTestHostHeader resolves the Host header sent with HTTP requests, returning an empty string if it should be
+// derived from the route
+func (t *TestCase) GetTestHostHeader() (string, error) {
+	if t.Config.HostHeader == "" {
+		return "", nil
+	}
+
+	resolved, err := t.GlobalDataStore.ExpandVariable(t.Config.HostHeader)
 	if err != nil {
 		return "", err
 	}
-	return varToString(resolvedRoute, t.Config.Route), nil
+	return varToString(resolved, t.Config.HostHeader), nil
+}
+
+// GetTestProxy resolves the URL of the proxy HTTP requests are sent through, returning nil if none is configured
+func (t *TestCase) GetTestProxy() (*url.URL, error) {
+	if t.Config.Proxy == "" {
+		return nil, nil
+	}
+
+	resolved, err := t.GlobalDataStore.ExpandVariable(t.Config.Proxy)
+	if err != nil {
+		return nil, err
+	}
+
+	proxyUrl, err := url.Parse(varToString(resolved, t.Config.Proxy))
+	if err != nil {
+		return nil, err
+	}
+
+	switch proxyUrl.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("unsupported proxy scheme '%v': expected http, https or socks5", proxyUrl.Scheme)
+	}
+	return proxyUrl, nil
 }
 
 func (t *TestCase) GetTestRpcAddr() (string, error) {
@@ -212,7 +640,20 @@ func (t *TestCase) GetTestRpcAddr() (string, error) {
 
 // Returns a new input object with all included variables resolved
 func (t *TestCase) GetResolvedTestInput() (interface{}, error) {
-	node, err := t.GlobalDataStore.RecursiveResolveVariables(t.Config.Input)
+	var input interface{} = t.Config.Input
+	if isTemplatedInput(input) {
+		body, ok := t.Config.Input[INPUT_KEY_BODY].(string)
+		if !ok {
+			return nil, fmt.Errorf("expected the '%v' of a templated input to be a string but got: %v", INPUT_KEY_BODY, t.Config.Input[INPUT_KEY_BODY])
+		}
+		rendered, err := RenderInputTemplate(t.Config.Name, body, t.GlobalDataStore)
+		if err != nil {
+			return nil, err
+		}
+		input = rendered
+	}
+
+	node, err := t.GlobalDataStore.RecursiveResolveVariables(input)
 	if err != nil {
 		return nil, err
 	}
@@ -222,9 +663,168 @@ func (t *TestCase) GetResolvedTestInput() (interface{}, error) {
 		return nil, err
 	}
 
+	if isPatchedInput(input) {
+		return patchInput(node.(map[interface{}]interface{}))
+	}
 	return node, err
 }
 
+// storeRequestInput stores a copy of the resolved input in the data store so that the response can be compared with
+// what was sent, e.g. '@{REQUEST_INPUT.name}'. The copy keeps later changes to the input from affecting it.
+func (t *TestCase) storeRequestInput(input interface{}) error {
+	copied, err := normalizeJson(YamlToJson(input))
+	if err != nil {
+		return fmt.Errorf("failed to copy test input: %v", err)
+	}
+	t.GlobalDataStore.Put(DS_REQUEST_INPUT, copied)
+	return nil
+}
+
+// storeRequestQuery stores the query parameters of the requested route in the data store so that validations can
+// depend on them, e.g. '@{REQUEST_QUERY.sort}'. Parameters given more than once are stored as arrays.
+func (t *TestCase) storeRequestQuery(route string) {
+	query := make(map[string]interface{})
+	if u, err := url.Parse(route); err == nil {
+		for k, v := range u.Query() {
+			if len(v) == 1 {
+				query[k] = v[0]
+				continue
+			}
+			values := make([]interface{}, len(v))
+			for i := range v {
+				values[i] = v[i]
+			}
+			query[k] = values
+		}
+	}
+	t.GlobalDataStore.Put(DS_REQUEST_QUERY, query)
+}
+
+// isTemplatedInput checks whether an input is rendered from a text/template, which is the case when it only contains
+// 'template: true' and the 'body' to render.
+func isTemplatedInput(input interface{}) bool {
+	node, ok := input.(map[interface{}]interface{})
+	if !ok || node[INPUT_KEY_TEMPLATE] != true {
+		return false
+	}
+	for k := range node {
+		if k != INPUT_KEY_TEMPLATE && k != INPUT_KEY_BODY {
+			return false
+		}
+	}
+	return true
+}
+
+// isPatchedInput checks whether an input is built from another object, which is the case when it only contains a
+// 'from' key and an optional 'patch' key.
+func isPatchedInput(input interface{}) bool {
+	node, ok := input.(map[interface{}]interface{})
+	if !ok {
+		return false
+	}
+	if _, ok := node[INPUT_KEY_FROM]; !ok {
+		return false
+	}
+	for k := range node {
+		if k != INPUT_KEY_FROM && k != INPUT_KEY_PATCH {
+			return false
+		}
+	}
+	return true
+}
+
+// patchInput copies the resolved 'from' object and overrides the values at each JSON path within 'patch'. The
+// object is copied so that the data store value it was resolved from is left untouched.
+func patchInput(node map[interface{}]interface{}) (interface{}, error) {
+	b, err := json.Marshal(YamlToJson(node[INPUT_KEY_FROM]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to copy '%v' input: %v", INPUT_KEY_FROM, err)
+	}
+	var base map[string]interface{}
+	if err := json.Unmarshal(b, &base); err != nil {
+		return nil, fmt.Errorf("failed to copy '%v' input - expected an object: %v", INPUT_KEY_FROM, err)
+	}
+
+	if p, ok := node[INPUT_KEY_PATCH]; ok && p != nil {
+		patch, ok := p.(map[interface{}]interface{})
+		if !ok {
+			return nil, fmt.Errorf("failed to read '%v' input - expected an object", INPUT_KEY_PATCH)
+		}
+		for k, v := range patch {
+			path := strings.TrimPrefix(fmt.Sprintf("%v", k), FIELD_KEY_PREFIX)
+			if err := PutJsonValue(base, path, YamlToJson(v)); err != nil {
+				return nil, fmt.Errorf("failed to patch input at '%v': %v", k, err)
+			}
+		}
+	}
+	return JsonToYaml(base), nil
+}
+
+// mergeHeaders combines suite level default headers with the headers of a test. Header names are case-insensitive
+// so a test header replaces any default header with the same name regardless of its casing.
+func mergeHeaders(defaults map[interface{}]interface{}, headers map[interface{}]interface{}) map[interface{}]interface{} {
+	if len(defaults) == 0 {
+		return headers
+	}
+
+	merged := make(map[interface{}]interface{})
+	for k, v := range defaults {
+		merged[k] = v
+	}
+	for k, v := range headers {
+		deleteHeader(merged, fmt.Sprintf("%v", k))
+		merged[k] = v
+	}
+	return merged
+}
+
+func hasHeader(headers map[interface{}]interface{}, name string) bool {
+	for k := range headers {
+		if strings.EqualFold(fmt.Sprintf("%v", k), name) {
+			return true
+		}
+	}
+	return false
+}
+
+func deleteHeader(headers map[interface{}]interface{}, name string) {
+	for k := range headers {
+		if strings.EqualFold(fmt.Sprintf("%v", k), name) {
+			delete(headers, k)
+		}
+	}
+}
+
+// canonicalHeaderKeys returns a copy of a header validation definition with its header names in the canonical form of
+// the received response headers (e.g. 'content-type' becomes 'Content-Type'), since header names are case-insensitive.
+// Names in JSON path notation (e.g. '$.x-request-id[0]') are canonicalized up to their first path element.
+func canonicalHeaderKeys(headers map[interface{}]interface{}) map[interface{}]interface{} {
+	if headers == nil {
+		return nil
+	}
+
+	canonical := make(map[interface{}]interface{}, len(headers))
+	for k, v := range headers {
+		key, ok := k.(string)
+		if !ok {
+			canonical[k] = v
+			continue
+		}
+
+		prefix := ""
+		if strings.HasPrefix(key, FIELD_KEY_PREFIX) {
+			prefix = FIELD_KEY_PREFIX
+			key = strings.TrimPrefix(key, FIELD_KEY_PREFIX)
+		}
+		name, rest := key, ""
+		if i := strings.IndexAny(key, ".["); i >= 0 {
+			name, rest = key[:i], key[i:]
+		}
+		canonical[prefix+http.CanonicalHeaderKey(name)+rest] = v
+	}
+	return canonical
+}
+
 func (t *TestCase) GetTestHeaders(inputReader *InputReader) (map[interface{}]interface{}, error) {
 	node, err := t.GlobalDataStore.RecursiveResolveVariables(t.Config.Headers)
 	if err != nil {
@@ -240,11 +840,22 @@ func (t *TestCase) GetTestHeaders(inputReader *InputReader) (map[interface{}]int
 	if !ok {
 		return nil, fmt.Errorf("failed to load headers for test - expected an object")
 	}
+	// tests without headers still send generated ones, such as the content type of form input
+	if headersMap == nil {
+		headersMap = map[interface{}]interface{}{}
+	}
 
 	if inputReader != nil && t.Config.FormInput {
+		// the multipart boundary must always be sent, so it replaces any configured content type
+		deleteHeader(headersMap, HEADER_CONTENT_TYPE)
 		headersMap[HEADER_CONTENT_TYPE] = inputReader.FormWriter.FormDataContentType()
 	}
 
+	// MessagePack input is sent with its content type unless the test sets its own
+	if inputReader != nil && t.usesMsgpackInput() && !hasHeader(headersMap, HEADER_CONTENT_TYPE) {
+		headersMap[HEADER_CONTENT_TYPE] = MIME_MSGPACK
+	}
+
 	return headersMap, nil
 }
 
@@ -254,12 +865,29 @@ func (t *TestCase) StepExecWebsocket(step int, result *TestResult) (passed bool,
 	if err != nil {
 		return false, 0, fmt.Errorf("failed to get test input: %v", err)
 	}
+	if err := t.storeRequestInput(input); err != nil {
+		return false, 0, err
+	}
 
 	if remaining, err = executeWebSocket(t, result, input, step); err != nil {
 		return false, remaining, err
 	}
 	result.Passed, result.Fields, err = t.ResponseMatcher.Match(result.Response)
-	return
+	result.mergeMessageFields()
+	return result.Passed, remaining, err
+}
+
+// mergeMessageFields folds the results of any per message websocket expectations into the test result
+func (r *TestResult) mergeMessageFields() {
+	if len(r.MessageFields) == 0 {
+		return
+	}
+
+	fields := append([]*FieldMatcherResult{}, r.MessageFields...)
+	for _, f := range r.MessageFields {
+		r.Passed = r.Passed && f.Status
+	}
+	r.Fields = append(fields, r.Fields...)
 }
 
 func (t *TestCase) GetStubbedFailResult(errorMsg string) *TestResult {
@@ -279,6 +907,14 @@ func (t *TestCase) GetStubbedFailResult(errorMsg string) *TestResult {
 	}
 }
 
+// ctx returns the context requests made by the test are bound to
+func (t *TestCase) ctx() context.Context {
+	if t.Context == nil {
+		return context.Background()
+	}
+	return t.Context
+}
+
 func (t *TestCase) Execute(testTags []string) (passed bool, result *TestResult, err error) {
 	respParser, respValidator := LoadExtensions(nil)
 
@@ -313,36 +949,150 @@ func (t *TestCase) Execute(testTags []string) (passed bool, result *TestResult,
 		return true, result, nil
 	}
 
+	if t.SkipTestOnEnvironment() {
+		msg := fmt.Sprintf("Skipping test - not enabled for environment '%v'", t.Environment)
+		if t.Environment == "" {
+			msg = "Skipping test - no environment was provided"
+		}
+		result.Fields = []*FieldMatcherResult{
+			{
+				Error:         fmt.Sprintf("%v. Enabled for: %v", msg, strings.Join(t.Config.Environments, ", ")),
+				ObjectKeyPath: fmt.Sprintf("test.%v", CFG_ENVIRONMENTS),
+				Status:        true,
+			},
+		}
+		result.Passed = true
+		return true, result, nil
+	}
+
+	if err := t.ctx().Err(); err != nil {
+		return false, result, fmt.Errorf(TimedOutFmt, err)
+	}
+
+	// assertions compare values stored by previous tests without making a request
+	if t.Config.AssertEquals != nil {
+		t.executeAssertion(result)
+		return result.Passed, result, nil
+	}
+
+	if t.RepeatCount() > 1 {
+		result, err = t.executeRepeated(result, respParser, respValidator)
+	} else {
+		result, err = t.executeAttempt(result, respParser, respValidator)
+	}
+	if err == nil {
+		t.runAfterHook(result)
+	}
+	return result.Passed, result, err
+}
+
+// executeAttempt performs the test's request once, or until it passes when polling, and checks its SLAs
+func (t *TestCase) executeAttempt(result *TestResult, respParser ResponseParserHandler, respValidator ResponseValidatorHandler) (*TestResult, error) {
+	var err error
+	if t.Config.PollUntil == nil {
+		if err = t.executeOnce(result, respParser, respValidator); err == nil {
+			t.checkRequestCount(result)
+			t.checkSLAs(result)
+			t.checkGlobalDuration(result)
+		}
+		return result, err
+	}
+
+	// re-request the test until its validations pass. Execution errors are not retried.
+	attempts := 0
+	requests := 0
+	for {
+		attempts++
+		result = &TestResult{
+			TestCase:  *t,
+			StartTime: result.StartTime,
+		}
+		err = t.executeOnce(result, respParser, respValidator)
+		requests += result.RequestCount
+		result.RequestCount = requests
+		if err != nil {
+			return result, err
+		}
+
+		elapsed := time.Since(result.StartTime)
+		if result.Passed || elapsed+t.PollInterval > t.PollTimeout {
+			break
+		}
+
+		select {
+		case <-time.After(t.PollInterval):
+		case <-t.ctx().Done():
+			return result, fmt.Errorf(TimedOutFmt, t.ctx().Err())
+		}
+	}
+
+	pollMsg := PollFailedFmt
+	if result.Passed {
+		pollMsg = PollPassedFmt
+	}
+	result.Fields = append(result.Fields, &FieldMatcherResult{
+		ObjectKeyPath: fmt.Sprintf("test.%v", CFG_POLL_UNTIL),
+		Error:         fmt.Sprintf(pollMsg, attempts, time.Since(result.StartTime).Round(time.Millisecond)),
+		Status:        result.Passed,
+	})
+	t.checkRequestCount(result)
+	t.checkSLAs(result)
+	t.checkGlobalDuration(result)
+	return result, nil
+}
+
+// executeOnce performs the test's request and validates the response
+func (t *TestCase) executeOnce(result *TestResult, respParser ResponseParserHandler, respValidator ResponseValidatorHandler) error {
 	input, err := t.GetResolvedTestInput()
 	if err != nil {
-		return false, result, fmt.Errorf("failed to get test input: %v", err)
+		return fmt.Errorf("failed to get test input: %v", err)
+	}
+	result.ResolvedInput = input
+	if err := t.storeRequestInput(input); err != nil {
+		return err
 	}
 
 	if t.Config.Websocket {
 		if _, err := executeWebSocket(t, result, input, -1); err != nil {
-			return false, result, err
+			return err
 		}
 	} else if !t.IsRPC {
 		if err := executeRest(t, result, respParser, input); err != nil {
-			return false, result, err
+			return err
 		}
 	} else {
 		if err := executeRPC(t, result, input); err != nil {
-			return false, result, err
+			return err
 		}
 	}
 
 	result.Passed, result.Fields, err = respValidator.Handle(t, result)
-	return result.Passed, result, err
+	result.mergeMessageFields()
+	t.captureValues(result)
+	if result.Response != nil {
+		t.GlobalDataStore.Root().Put(DS_PREV_RESPONSE, result.Response)
+	}
+	return err
+}
+
+// RemoveTempFiles removes the temporary files created for the test, such as saved binary responses
+func (t *TestCase) RemoveTempFiles() {
+	for _, f := range t.TempFiles {
+		os.Remove(f)
+	}
+	t.TempFiles = nil
 }
 
 func (t *TestCase) CloseWebsocket() {
-	if wsc, ok := t.GlobalDataStore.Store[DS_WS_CLIENT]; ok {
+	// websocket clients are shared by every test in the suite, including isolated ones
+	ds := t.GlobalDataStore.Root()
+	if wsc, ok := ds.Store[DS_WS_CLIENT]; ok {
 		c := wsc.(*websocket.Conn)
 		c.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(websocket.CloseNormalClosure, ""))
 		c.Close()
 
-		delete(t.GlobalDataStore.Store, DS_WS_CLIENT)
+		delete(ds.Store, DS_WS_CLIENT)
+		delete(ds.Store, DS_WS_HANDSHAKE)
 	}
 }
 
@@ -358,7 +1108,8 @@ func (t *TestCase) GetWebsocketClient() (*websocket.Conn, string, error) {
 	// test to create a new connection.
 	// Otherwise, if no client exists already, we'll create a new one and connect it.
 	var client *websocket.Conn
-	if prevClient, ok := t.GlobalDataStore.Store[DS_WS_CLIENT]; !ok {
+	ds := t.GlobalDataStore.Root()
+	if prevClient, ok := ds.Store[DS_WS_CLIENT]; !ok {
 		inputHeaders := http.Header{}
 
 		headers, err := t.GetTestHeaders(nil)
@@ -370,12 +1121,35 @@ func (t *TestCase) GetWebsocketClient() (*websocket.Conn, string, error) {
 			val := headers[k].(string)
 			inputHeaders.Set(key, val)
 		}
+		setDefaultUserAgent(inputHeaders)
 
-		client, _, err = websocket.DefaultDialer.Dial(route, inputHeaders)
+		var handshake *http.Response
+		client, handshake, err = websocket.DefaultDialer.DialContext(t.ctx(), route, inputHeaders)
 		if err != nil {
+			if handshake != nil {
+				return nil, route, fmt.Errorf("failed to start websocket client: %v (status %v)", err, handshake.StatusCode)
+			}
 			return nil, route, fmt.Errorf("failed to start websocket client: %v", err)
 		}
-		t.GlobalDataStore.Put(DS_WS_CLIENT, client)
+
+		// keep the handshake response for the tests sharing this client so its headers can be validated
+		var handshakeHeaders map[string]interface{}
+		headerData, _ := json.Marshal(&handshake.Header)
+		if err := json.Unmarshal(headerData, &handshakeHeaders); err != nil {
+			return nil, route, fmt.Errorf("failed to convert handshake headers: %v\n%v", err, handshake.Header)
+		}
+		ds.Put(DS_WS_HANDSHAKE, map[string]interface{}{
+			WS_HANDSHAKE_STATUS:  handshake.StatusCode,
+			WS_HANDSHAKE_HEADERS: handshakeHeaders,
+		})
+		// unblock any pending reads once the test run is cancelled
+		if done := t.ctx().Done(); done != nil {
+			go func(c *websocket.Conn) {
+				<-done
+				c.Close()
+			}(client)
+		}
+		ds.Put(DS_WS_CLIENT, client)
 	} else {
 		client = prevClient.(*websocket.Conn)
 	}
@@ -398,6 +1172,14 @@ func (t *TestCase) GetWebsocketInput(input interface{}) (*WSInput, error) {
 func (t *TestCase) GetRestInput(input interface{}) (*InputReader, error) {
 
 	// if we aren't passing in form input, just provide the input object as JSON
+	if t.usesMsgpackInput() {
+		b, err := EncodeMsgpack(YamlToJson(input))
+		if err != nil {
+			return nil, err
+		}
+		return &InputReader{BodyReader: bytes.NewReader(b)}, nil
+	}
+
 	if !t.Config.FormInput || t.Config.Websocket {
 		jsonNode := YamlToJson(input)
 		b, err := json.Marshal(jsonNode)
@@ -449,15 +1231,33 @@ func (t *TestCase) GetRestInput(input interface{}) (*InputReader, error) {
 			}
 		}
 
-		outputWriter.Close()
+		// the closing boundary has to be written before the pipe is closed or the form is truncated
 		inputReader.FormWriter.Close()
+		outputWriter.Close()
 		inputReader.ErrorChan <- nil
 	}()
 
 	return inputReader, nil
 }
 
+// SkipTestOnEnvironment returns whether the test is limited to environments that don't include the current one. Tests
+// limited to any environment are skipped when no environment is provided.
+func (t *TestCase) SkipTestOnEnvironment() bool {
+	if len(t.Config.Environments) == 0 {
+		return false
+	}
+	for _, env := range t.Config.Environments {
+		if env == t.Environment {
+			return false
+		}
+	}
+	return true
+}
+
+// SkipTestOnTags returns whether the test lacks any of the tag combinations. Tags are resolved again first, since the
+// variables of the run and those stored by previous tests are only available once the test is about to execute.
 func (t *TestCase) SkipTestOnTags(testTags []string) bool {
+	t.resolveTags()
 	for _, inTag := range testTags {
 		if !t.HasTag(inTag) {
 			return true
@@ -2,31 +2,53 @@ package arp
 
 import (
 	"bytes"
+	"crypto/rand"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"io/ioutil"
+	"mime"
 	"net/http"
 	"net/rpc"
 	"os"
 	"os/exec"
+	"path/filepath"
 	"strings"
 	"sync"
+	"time"
+	"unicode/utf8"
 
 	"github.com/gorilla/websocket"
 )
 
 const (
-	WS_ENC_BASE64   = "base64gzip"
-	WS_ENC_HEX      = "hex"
-	WS_ENC_FILE     = "file"
-	WS_ENC_EXTERNAL = "external"
-	WS_RESPONSE     = "responses"
+	WS_ENC_BASE64     = "base64gzip"
+	WS_ENC_HEX        = "hex"
+	WS_ENC_FILE       = "file"
+	WS_ENC_EXTERNAL   = "external"
+	WS_RESPONSE       = "responses"
+	WS_REQUESTS       = "requests"
+	WS_EXPECT         = "expect"
+	WS_CLOSE          = "close"
+	WS_CLOSE_CODE     = "code"
+	WS_CLOSE_REASON   = "reason"
+	WS_CLOSE_ABNORMAL = "abnormal"
+
+	WS_HANDSHAKE         = "handshake"
+	WS_HANDSHAKE_STATUS  = "status"
+	WS_HANDSHAKE_HEADERS = "headers"
+
+	// default duration to wait for correlated websocket responses
+	DEFAULT_WS_CORRELATE_TIMEOUT = 5 * time.Second
 
 	WS_MSG_TEXT = "text"
 	WS_MSG_JSON = "json"
 	WS_MSG_BIN  = "binary"
+
+	// keys of the response of an RPC procedure that returned an error
+	RPC_RESPONSE_ERROR = "error"
+	RPC_ERROR_MESSAGE  = "message"
 )
 
 type WSMessage struct {
@@ -38,17 +60,44 @@ type WSMessage struct {
 	MessageType string      `yam:"type" json:"type"`
 	Encoding    string      `yaml:"encoding" json:"encoding"`
 	FilePath    string      `yaml:"filePath" json:"filePath"`
+	// Expected sha256 sum (or file to derive it from) of a binary response
+	ExpectSha256 string `yaml:"expectSha256" json:"expectSha256"`
+	ExpectFile   string `yaml:"expectFile" json:"expectFile"`
+	// Path of a field that is present in both the request payload and its response. When set, responses are paired
+	// with requests by the value of this field rather than the order they arrive in.
+	CorrelateBy string `yaml:"correlateBy" json:"correlateBy"`
 }
 
 type WSInput struct {
 	Requests []WSMessage `yaml:"requests" json:"requests"`
 	Close    bool        `yaml:"close" json:"close"`
+	// How long to wait for correlated responses before reporting the requests without one
+	CorrelateTimeout string `yaml:"correlateTimeout" json:"correlateTimeout"`
+}
+
+// isCorrelated returns whether any of the messages pair their responses by a field value
+func (i *WSInput) isCorrelated() bool {
+	for _, r := range i.Requests {
+		if r.CorrelateBy != "" {
+			return true
+		}
+	}
+	return false
 }
 
 type WsResponseJson struct {
 	Responses []map[string]interface{} `json:"responses"`
 }
 
+// newRequestId generates a random (version 4) UUID to identify a request with
+func newRequestId() string {
+	b := make([]byte, 16)
+	rand.Read(b)
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
 func executeRest(test *TestCase, result *TestResult, responseHandler ResponseParserHandler, input interface{}) error {
 	client := http.Client{}
 	defer client.CloseIdleConnections()
@@ -74,8 +123,28 @@ func executeRest(test *TestCase, result *TestResult, responseHandler ResponsePar
 		return fmt.Errorf("failed to determine test route: %v", err)
 	}
 	result.ResolvedRoute = route
+	test.storeRequestQuery(route)
+
+	proxyUrl, err := test.GetTestProxy()
+	if err != nil {
+		return fmt.Errorf("failed to determine test proxy: %v", err)
+	}
+	if proxyUrl != nil {
+		// keep the default timeouts and TLS settings, only the proxy differs
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.Proxy = http.ProxyURL(proxyUrl)
+		client.Transport = transport
+	}
+	requests := &requestCounter{RoundTripper: http.DefaultTransport}
+	if client.Transport != nil {
+		requests.RoundTripper = client.Transport
+	}
+	client.Transport = requests
+	if test.Trace {
+		client.Transport = &requestTracer{RoundTripper: requests, Name: test.Config.Name, Redactor: test.TraceRedactor}
+	}
 
-	request, err = http.NewRequest(test.Config.Method, result.ResolvedRoute, requestInputReader)
+	request, err = http.NewRequestWithContext(test.ctx(), test.Config.Method, result.ResolvedRoute, requestInputReader)
 	if err != nil {
 		return fmt.Errorf("failed to initialize http request: %v", err)
 	}
@@ -90,9 +159,27 @@ func executeRest(test *TestCase, result *TestResult, responseHandler ResponsePar
 		val := headers[k].(string)
 		request.Header.Set(key, val)
 	}
+	setDefaultUserAgent(request.Header)
+
+	// the Host header is sent from the request rather than its headers, so it can differ from the host in the route
+	hostHeader, err := test.GetTestHostHeader()
+	if err != nil {
+		return fmt.Errorf("failed to resolve test hostHeader parameter: %v", err)
+	}
+	if hostHeader != "" {
+		request.Host = hostHeader
+	}
+
+	if test.RequestIdHeader != "" {
+		if request.Header.Get(test.RequestIdHeader) == "" {
+			request.Header.Set(test.RequestIdHeader, newRequestId())
+		}
+		result.RequestId = request.Header.Get(test.RequestIdHeader)
+	}
 
 	result.RequestHeaders = request.Header
 	response, err = client.Do(request)
+	result.RequestCount = requests.Count
 	if requestInput != nil && requestInput.ErrorChan != nil {
 		if inputErr := <-requestInput.ErrorChan; inputErr != nil {
 			return fmt.Errorf("request input failure: %v", inputErr)
@@ -102,6 +189,12 @@ func executeRest(test *TestCase, result *TestResult, responseHandler ResponsePar
 		return fmt.Errorf("failed to fetch API response: %v", err)
 	}
 	result.StatusCode = response.StatusCode
+	result.ContentLength = response.ContentLength
+	for _, encoding := range response.TransferEncoding {
+		if encoding == "chunked" {
+			result.Chunked = true
+		}
+	}
 
 	// convert response headers to json for validation
 	var responseHeaders map[string]interface{}
@@ -110,10 +203,95 @@ func executeRest(test *TestCase, result *TestResult, responseHandler ResponsePar
 		return fmt.Errorf("failed to convert response headers: %v\n%v", err, response.Header)
 	}
 	result.ResponseHeaders = responseHeaders
+	result.Cache = getCacheJson(test.CacheRules, response.Header)
+	result.Cookies = getCookiesJson(response)
+
+	if mediaType, params, mErr := mime.ParseMediaType(response.Header.Get(HEADER_CONTENT_TYPE)); mErr == nil {
+		result.MediaType = mediaType
+		result.Charset = strings.ToLower(params[CFG_RESPONSE_CHARSET])
+	}
+
+	// validate the body decodes as utf-8 while it is being read by the response parsers
+	var encodingValidator *utf8Validator
+	if test.Config.Response.ValidateCharset && (result.Charset == "utf-8" || result.Charset == "utf8") {
+		encodingValidator = &utf8Validator{Valid: true}
+		response.Body = struct {
+			io.Reader
+			io.Closer
+		}{io.TeeReader(response.Body, encodingValidator), response.Body}
+	}
+
+	// count the bytes in the body while it is being read so it can be compared against the declared length
+	var counter *bodyCounter
+	if test.Config.Response.ContentLengthMatches != nil {
+		counter = &bodyCounter{ReadCloser: response.Body}
+		response.Body = counter
+	}
+
 	result.Response, result.RawResponse, err = responseHandler.Handle(test, response)
+
+	if counter != nil {
+		// the response parsers may stop reading before the end of the body
+		io.Copy(ioutil.Discard, counter)
+		result.BodySize = counter.Size
+	}
+
+	if encodingValidator != nil && !encodingValidator.Done() {
+		result.CharsetError = fmt.Sprintf("Response body is not valid %v", result.Charset)
+	}
 	return err
 }
 
+// bodyCounter counts the bytes read from a response body. A body that ends before its declared length is treated
+// as complete so that the mismatch is reported by the content length validation rather than failing the request.
+type bodyCounter struct {
+	io.ReadCloser
+	Size int64
+}
+
+func (c *bodyCounter) Read(b []byte) (int, error) {
+	n, err := c.ReadCloser.Read(b)
+	c.Size += int64(n)
+	if err == io.ErrUnexpectedEOF {
+		err = io.EOF
+	}
+	return n, err
+}
+
+// utf8Validator checks that all data written to it is valid utf-8 without having to buffer the data.
+type utf8Validator struct {
+	Valid   bool
+	pending []byte
+}
+
+func (v *utf8Validator) Write(b []byte) (int, error) {
+	if !v.Valid {
+		return len(b), nil
+	}
+
+	data := append(v.pending, b...)
+
+	// hold back a trailing partial rune until the rest of it is written
+	cut := len(data)
+	for i := len(data) - 1; i >= 0 && i >= len(data)-utf8.UTFMax; i-- {
+		if utf8.RuneStart(data[i]) {
+			if !utf8.FullRune(data[i:]) {
+				cut = i
+			}
+			break
+		}
+	}
+
+	v.Valid = utf8.Valid(data[:cut])
+	v.pending = append([]byte{}, data[cut:]...)
+	return len(b), nil
+}
+
+// Done returns whether all of the written data was valid utf-8
+func (v *utf8Validator) Done() bool {
+	return v.Valid && len(v.pending) == 0
+}
+
 func executeRPC(test *TestCase, result *TestResult, input interface{}) error {
 	var client *rpc.Client
 	var err error
@@ -150,8 +328,15 @@ func executeRPC(test *TestCase, result *TestResult, input interface{}) error {
 	args = b
 
 	var reply []byte
+	result.RequestCount++
 	err = client.Call(test.Config.RPC.Procedure, args, &reply)
-	if err != nil {
+	if serverErr, ok := err.(rpc.ServerError); ok {
+		// errors returned by the procedure are validated like any other response, unlike transport failures
+		result.Response = map[string]interface{}{
+			RPC_RESPONSE_ERROR: rpcErrorJson(serverErr),
+		}
+		return nil
+	} else if err != nil {
 		return fmt.Errorf("rpc call failed: %v", err)
 	}
 
@@ -164,6 +349,30 @@ func executeRPC(test *TestCase, result *TestResult, input interface{}) error {
 	return nil
 }
 
+// validatesResponseField returns whether the payload of the test defines validations for a top level response field
+func (t *TestCase) validatesResponseField(field string) bool {
+	for k := range t.Config.Response.Payload {
+		key := strings.TrimPrefix(fmt.Sprintf("%v", k), FIELD_KEY_PREFIX)
+		if key == field || strings.HasPrefix(key, field+".") {
+			return true
+		}
+	}
+	return false
+}
+
+// rpcErrorJson converts an error returned by an RPC procedure into an object with its message. Since RPC errors only
+// carry a message, errors whose message is a JSON object, such as {"code": 404, "message": "not found"}, are used as
+// the error object instead.
+func rpcErrorJson(err rpc.ServerError) map[string]interface{} {
+	var obj map[string]interface{}
+	if json.Unmarshal([]byte(err), &obj) == nil && obj != nil {
+		return obj
+	}
+	return map[string]interface{}{
+		RPC_ERROR_MESSAGE: string(err),
+	}
+}
+
 func executeWebSocket(test *TestCase, result *TestResult, input interface{}, step int) (int, error) {
 	client, route, err := test.GetWebsocketClient()
 	if err != nil {
@@ -186,28 +395,54 @@ func executeWebSocket(test *TestCase, result *TestResult, input interface{}, ste
 		result.Response = make(map[string]interface{})
 		result.Response[WS_RESPONSE] = make([]interface{}, 0)
 	}
+	if handshake, ok := test.GlobalDataStore.Root().Store[DS_WS_HANDSHAKE]; ok {
+		result.Response[WS_HANDSHAKE] = handshake
+	}
+
+	if inputs.isCorrelated() {
+		// correlated messages can't be stepped through individually since their responses may arrive in any order
+		return 0, executeCorrelatedWebsocket(test, client, inputs, result)
+	}
 
 	if step >= 0 && step < len(inputs.Requests) {
-		return len(inputs.Requests) - 1 - step, executeWebsoecktRequest(client, &inputs.Requests[step], result)
+		passed, err := executeWebsoecktRequest(test, client, &inputs.Requests[step], step, result)
+		if _, closed := result.Response[WS_CLOSE]; closed {
+			failUnreadExpectations(test, inputs, step, result)
+			return 0, err
+		}
+		if !passed {
+			// fail fast, there is no point in stepping through the remaining messages
+			return 0, err
+		}
+		return len(inputs.Requests) - 1 - step, err
 	}
 
-	for _, ti := range inputs.Requests {
-		err := executeWebsoecktRequest(client, &ti, result)
+	for i, ti := range inputs.Requests {
+		passed, err := executeWebsoecktRequest(test, client, &ti, i, result)
 		if err != nil {
 			return 0, err
 		}
+		if _, closed := result.Response[WS_CLOSE]; closed {
+			failUnreadExpectations(test, inputs, i, result)
+			break
+		}
+		if !passed {
+			break
+		}
 	}
 
 	return 0, nil
 }
 
-func executeWebsoecktRequest(client *websocket.Conn, testInput *WSMessage, result *TestResult) error {
+// executeWebsoecktRequest sends and/or receives a single websocket message. If the message defines its own
+// expectations, the response is validated immediately and false is returned when the validation fails.
+func executeWebsoecktRequest(test *TestCase, client *websocket.Conn, testInput *WSMessage, index int, result *TestResult) (bool, error) {
 	if !testInput.ReadOnly {
 		err := writeWebsocketPayload(client, testInput)
 		if err != nil {
 			//result.Passed = false
 			//result.RunError = err
-			return err
+			return false, err
 		}
 	}
 
@@ -215,14 +450,18 @@ func executeWebsoecktRequest(client *websocket.Conn, testInput *WSMessage, resul
 		var subRespJson map[string]interface{}
 		if testInput.Response == "binary" {
 			_, responseReader, err := client.NextReader()
-			if err != nil {
-				return fmt.Errorf("failed to initialze websocket response reader: %v", err)
+			if recordWebsocketClose(test, result, err) {
+				return true, nil
+			} else if err != nil {
+				return false, fmt.Errorf("failed to initialze websocket response reader: %v", err)
 			}
 			subRespJson, _ = getBinaryJson(testInput.FilePath, true, responseReader)
 		} else {
 			_, responseData, err := client.ReadMessage()
-			if err != nil {
-				return fmt.Errorf("failed to read websocket response: %v", err)
+			if recordWebsocketClose(test, result, err) {
+				return true, nil
+			} else if err != nil {
+				return false, fmt.Errorf("failed to read websocket response: %v", err)
 			}
 
 			if testInput.Response == "json" || testInput.Response == "" {
@@ -235,11 +474,254 @@ func executeWebsoecktRequest(client *websocket.Conn, testInput *WSMessage, resul
 			}
 		}
 
-		result.Response[WS_RESPONSE] = append(result.Response[WS_RESPONSE].([]interface{}), subRespJson)
+		responses := append(result.Response[WS_RESPONSE].([]interface{}), subRespJson)
+		result.Response[WS_RESPONSE] = responses
+		return validateWebsocketResponse(test, testInput, subRespJson, index, len(responses)-1, result)
+	}
+	return true, nil
+}
+
+// recordWebsocketClose stores the code and reason of a websocket closed by the server under the 'close' key of the
+// response so that it can be validated. Closures without a close frame, such as a dropped connection, are flagged
+// as abnormal. Returns false if the error isn't a close.
+func recordWebsocketClose(test *TestCase, result *TestResult, err error) bool {
+	closeErr, ok := err.(*websocket.CloseError)
+	if !ok {
+		return false
+	}
+
+	result.Response[WS_CLOSE] = map[string]interface{}{
+		WS_CLOSE_CODE:     closeErr.Code,
+		WS_CLOSE_REASON:   closeErr.Text,
+		WS_CLOSE_ABNORMAL: closeErr.Code == websocket.CloseAbnormalClosure,
+	}
+
+	// the connection can't be used anymore, so the next websocket test has to reconnect
+	test.CloseWebsocket()
+	return true
+}
+
+// failUnreadExpectations fails the expectations of the messages from 'from' onwards once the server closed the websocket
+// before their responses were read, since a test shouldn't pass by the server closing early. Tests that validate the
+// close in their payload expect the server to close, so their unread expectations are ignored.
+func failUnreadExpectations(test *TestCase, inputs *WSInput, from int, result *TestResult) {
+	if test.validatesResponseField(WS_CLOSE) {
+		return
+	}
+
+	position := len(result.Response[WS_RESPONSE].([]interface{}))
+	for i := from; i < len(inputs.Requests); i++ {
+		msg := inputs.Requests[i]
+		if msg.WriteOnly {
+			continue
+		}
+
+		_, expects := test.WebsocketMatchers[i]
+		if msg.Response == WS_MSG_BIN && (msg.ExpectSha256 != "" || msg.ExpectFile != "") {
+			expects = true
+		}
+		if expects {
+			result.MessageFields = append(result.MessageFields, &FieldMatcherResult{
+				ObjectKeyPath:   fmt.Sprintf(".%v[%v]", WS_RESPONSE, position),
+				Error:           fmt.Sprintf("The websocket was closed by the server before the response to message %v was read", i),
+				Status:          false,
+				ShowExtendedMsg: true,
+			})
+		}
+		position++
+	}
+}
+
+// validateWebsocketResponse applies the expectations of a single websocket message to its response. The index is the
+// position of the message in the requests while position is that of its response in the responses array, which differ
+// when earlier messages don't read a response (e.g. 'writeOnly').
+func validateWebsocketResponse(test *TestCase, testInput *WSMessage, response map[string]interface{}, index int, position int, result *TestResult) (bool, error) {
+	passed := true
+	if testInput.Response == WS_MSG_BIN && (testInput.ExpectSha256 != "" || testInput.ExpectFile != "") {
+		field, err := validateWebsocketBinary(test.GlobalDataStore, testInput, response, index, position)
+		if err != nil {
+			return false, err
+		}
+		result.MessageFields = append(result.MessageFields, field)
+		passed = field.Status
+	}
+
+	if matcher, ok := test.WebsocketMatchers[index]; ok {
+		mPassed, fields, err := matcher.Match(response)
+		for _, f := range fields {
+			f.ObjectKeyPath = fmt.Sprintf(".%v[%v]%v", WS_RESPONSE, position, f.ObjectKeyPath)
+		}
+		result.MessageFields = append(result.MessageFields, fields...)
+		return passed && mPassed, err
+	}
+	return passed, nil
+}
+
+// correlationKey returns the string representation of the field used to pair a websocket request and response
+func correlationKey(payload interface{}, field string) (string, bool) {
+	if s, ok := payload.(string); ok {
+		var decoded interface{}
+		if err := json.Unmarshal([]byte(s), &decoded); err != nil {
+			return "", false
+		}
+		payload = decoded
+	}
+
+	obj, ok := payload.(map[string]interface{})
+	if !ok {
+		return "", false
+	}
+	value, err := GetJsonValue(obj, field)
+	if err != nil || value == nil {
+		return "", false
+	}
+	return varToString(value), true
+}
+
+// executeCorrelatedWebsocket sends all the websocket messages and then pairs the responses with their requests by
+// the value of each message's 'correlateBy' field. Responses are stored at the index of the request they belong
+// to so that per message expectations apply regardless of the order the server replied in. Requests that didn't
+// receive a response before the timeout and responses that don't belong to any request are reported as failures.
+func executeCorrelatedWebsocket(test *TestCase, client *websocket.Conn, inputs *WSInput, result *TestResult) error {
+	timeout := DEFAULT_WS_CORRELATE_TIMEOUT
+	if inputs.CorrelateTimeout != "" {
+		var err error
+		if timeout, err = time.ParseDuration(inputs.CorrelateTimeout); err != nil {
+			return fmt.Errorf("invalid websocket correlateTimeout: %v", err)
+		}
+	}
+
+	responses := make([]interface{}, len(inputs.Requests))
+	pending := map[string]int{}
+	for i := range inputs.Requests {
+		msg := &inputs.Requests[i]
+		if !msg.ReadOnly {
+			if err := writeWebsocketPayload(client, msg); err != nil {
+				return err
+			}
+		}
+		if msg.WriteOnly {
+			continue
+		}
+		if msg.CorrelateBy == "" || msg.ReadOnly {
+			return fmt.Errorf("websocket message %v must define 'correlateBy' and a payload when other messages are correlated", i)
+		}
+
+		key, ok := correlationKey(msg.Payload, msg.CorrelateBy)
+		if !ok {
+			return fmt.Errorf("websocket message %v is missing its correlateBy field '%v'", i, msg.CorrelateBy)
+		}
+		if _, exists := pending[key]; exists {
+			return fmt.Errorf("websocket message %v has the same correlateBy value as another message: %v", i, key)
+		}
+		pending[key] = i
+	}
+
+	client.SetReadDeadline(time.Now().Add(timeout))
+	defer client.SetReadDeadline(time.Time{})
+
+	for len(pending) > 0 {
+		_, responseData, err := client.ReadMessage()
+		if err != nil {
+			if netErr, ok := err.(interface{ Timeout() bool }); ok && netErr.Timeout() {
+				// a connection that timed out can't be read from again, so the next test has to reconnect
+				test.CloseWebsocket()
+				break
+			}
+			if recordWebsocketClose(test, result, err) {
+				break
+			}
+			return fmt.Errorf("failed to read websocket response: %v", err)
+		}
+
+		var response map[string]interface{}
+		if err := json.Unmarshal(responseData, &response); err != nil {
+			response, _ = getBinaryJson("", false, bytes.NewReader(responseData))
+		}
+
+		index := -1
+		for key, i := range pending {
+			if responseKey, ok := correlationKey(response, inputs.Requests[i].CorrelateBy); ok && responseKey == key {
+				index = i
+				delete(pending, key)
+				break
+			}
+		}
+
+		if index < 0 {
+			responses = append(responses, response)
+			result.MessageFields = append(result.MessageFields, &FieldMatcherResult{
+				ObjectKeyPath:   fmt.Sprintf(".%v[%v]", WS_RESPONSE, len(responses)-1),
+				Error:           "Received a response that doesn't correlate with any request",
+				Status:          false,
+				ShowExtendedMsg: true,
+			})
+			continue
+		}
+
+		responses[index] = response
+		if _, err := validateWebsocketResponse(test, &inputs.Requests[index], response, index, index, result); err != nil {
+			return err
+		}
 	}
+
+	unmatched := map[int]string{}
+	for key, i := range pending {
+		unmatched[i] = key
+	}
+	for i := range inputs.Requests {
+		key, ok := unmatched[i]
+		if !ok {
+			continue
+		}
+		result.MessageFields = append(result.MessageFields, &FieldMatcherResult{
+			ObjectKeyPath:   fmt.Sprintf(".%v[%v]", WS_RESPONSE, i),
+			Error:           fmt.Sprintf("No response with %v '%v' received within %v", inputs.Requests[i].CorrelateBy, key, timeout),
+			Status:          false,
+			ShowExtendedMsg: true,
+		})
+	}
+
+	result.Response[WS_RESPONSE] = append(result.Response[WS_RESPONSE].([]interface{}), responses...)
 	return nil
 }
 
+// validateWebsocketBinary compares the sha256 sum of a binary websocket response against the expected sum
+// or the sum of the expected file. Relative file paths are resolved from the directory of the test file.
+func validateWebsocketBinary(datastore *DataStore, testInput *WSMessage, response map[string]interface{}, index int, position int) (*FieldMatcherResult, error) {
+	expected := testInput.ExpectSha256
+	if testInput.ExpectFile != "" {
+		resolved, err := datastore.ExpandVariable(testInput.ExpectFile)
+		if err != nil {
+			return nil, fmt.Errorf(BadVarMatcherFmt, testInput.ExpectFile)
+		}
+		path := varToString(resolved, testInput.ExpectFile)
+		if !filepath.IsAbs(path) {
+			if testDir, ok := datastore.Get(DS_TEST_DIR).(string); ok {
+				path = filepath.Join(testDir, path)
+			}
+		}
+		fileSum, err := fileSha256(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to hash expected file for websocket message %v: %v", index, err)
+		}
+		expected = fileSum
+	}
+
+	actual := fmt.Sprintf("%v", response[BIN_KEY_SHA256])
+	result := &FieldMatcherResult{
+		ObjectKeyPath: fmt.Sprintf(".%v[%v].%v", WS_RESPONSE, position, BIN_KEY_SHA256),
+		Status:        strings.EqualFold(expected, actual),
+		Error:         actual,
+	}
+	if !result.Status {
+		result.Error = fmt.Sprintf(ValueErrFmt, expected, actual)
+		result.ShowExtendedMsg = true
+	}
+
+	return result, nil
+}
+
 func writeWebsocketPayload(client *websocket.Conn, input *WSMessage) error {
 	msType := websocket.TextMessage
 	switch input.MessageType {
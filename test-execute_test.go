@@ -0,0 +1,381 @@
+package arp
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/rpc"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// testRpcService is the procedure called by the RPC tests
+type testRpcService struct{}
+
+func (s *testRpcService) Get(args []byte, reply *[]byte) error {
+	var input map[string]interface{}
+	if err := json.Unmarshal(args, &input); err != nil {
+		return err
+	}
+	if input["id"] == float64(0) {
+		return errors.New(`{"code": 404, "message": "user not found"}`)
+	}
+	*reply, _ = json.Marshal(map[string]interface{}{"id": input["id"], "name": "charles"})
+	return nil
+}
+
+func TestExecuteRPC(t *testing.T) {
+	server := rpc.NewServer()
+	if err := server.RegisterName("Users", &testRpcService{}); err != nil {
+		t.Fatal(err)
+	}
+	httpServer := httptest.NewServer(server)
+	defer httpServer.Close()
+	addr := strings.TrimPrefix(httpServer.URL, "http://")
+
+	tests := []struct {
+		name      string
+		procedure string
+		input     map[interface{}]interface{}
+		payload   string
+		response  string
+	}{
+		{"success", "Users.Get", map[interface{}]interface{}{"id": 1}, "", `{"id":1,"name":"charles"}`},
+		{"error validated", "Users.Get", map[interface{}]interface{}{"id": 0}, "error: {type: object}",
+			`{"error":{"code":404,"message":"user not found"}}`},
+		{"error validated by path", "Users.Get", map[interface{}]interface{}{"id": 0}, "$.error.code: 404",
+			`{"error":{"code":404,"message":"user not found"}}`},
+		{"error without validation", "Users.Get", map[interface{}]interface{}{"id": 0}, "",
+			`{"error":{"code":404,"message":"user not found"}}`},
+		{"unknown procedure", "Users.Missing", map[interface{}]interface{}{"id": 1}, "",
+			`{"error":{"message":"rpc: can't find method Users.Missing"}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ds := NewDataStore()
+			test := &TestCase{GlobalDataStore: &ds}
+			test.Config.RPC = TestCaseRpcCfg{Address: addr, Procedure: tt.procedure}
+			if tt.payload != "" {
+				test.Config.Response.Payload = parseTestYaml(t, tt.payload)
+			}
+
+			result := &TestResult{}
+			if err := executeRPC(test, result, tt.input); err != nil {
+				t.Fatal(err)
+			}
+			response, _ := json.Marshal(result.Response)
+			if string(response) != tt.response {
+				t.Errorf("expected response %v but got %v", tt.response, string(response))
+			}
+		})
+	}
+
+	// errors returned by the procedure fail the test through its validations rather than ending the suite
+	result := runTestFile(t, `
+tests:
+  - name: Unknown user
+    rpc:
+      protocol: http
+      address: "@{host}"
+      procedure: Users.Get
+    input:
+      id: 0
+    response:
+      payload:
+        name: charles
+`, addr, SuiteOptions{})
+	if len(result.Results) != 1 {
+		t.Fatalf("expected 1 result but got %v", len(result.Results))
+	}
+	if r := result.Results[0]; r.Passed || !strings.Contains(failedFields(r), "name") {
+		t.Errorf("expected the payload validations to fail the test but got:\n%v", failedFields(r))
+	}
+
+	// only failing to reach the server is an error
+	ds := NewDataStore()
+	test := &TestCase{GlobalDataStore: &ds}
+	test.Config.RPC = TestCaseRpcCfg{Address: "127.0.0.1:1", Procedure: "Users.Get"}
+	if err := executeRPC(test, &TestResult{}, map[interface{}]interface{}{"id": 1}); err == nil {
+		t.Error("expected an unreachable server to fail with an error")
+	}
+}
+
+func TestExecuteRestProxy(t *testing.T) {
+	// the proxy answers requests itself rather than forwarding them, so the route's host never has to resolve
+	proxy := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"url": r.URL.String(),
+		})
+	}))
+	defer proxy.Close()
+
+	result := runTestFile(t, `
+tests:
+  - name: Proxied
+    route: "http://arp.invalid/users?id=1"
+    method: GET
+    proxy: "@{host}"
+    response:
+      code: 200
+      payload:
+        url: "http://arp\\.invalid/users\\?id=1"
+`, proxy.URL, SuiteOptions{})
+
+	if len(result.Results) != 1 {
+		t.Fatalf("expected 1 result but got %v", len(result.Results))
+	}
+	if r := result.Results[0]; !r.Passed {
+		t.Errorf("expected the request to be sent through the proxy:\n%v", failedFields(r))
+	}
+}
+
+func TestValidateWebsocketBinaryFile(t *testing.T) {
+	dir := t.TempDir()
+	contents := []byte{0, 1, 2, 3}
+	if err := os.WriteFile(filepath.Join(dir, "expected.bin"), contents, 0600); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "other.bin"), []byte{4}, 0600); err != nil {
+		t.Fatal(err)
+	}
+	sum := sha256.Sum256(contents)
+	response := map[string]interface{}{BIN_KEY_SHA256: hex.EncodeToString(sum[:])}
+
+	ds := NewDataStore()
+	ds.Put(DS_TEST_DIR, dir)
+	ds.Put("fileName", "expected.bin")
+
+	tests := []struct {
+		name   string
+		file   string
+		passed bool
+		fails  bool
+	}{
+		{"relative to the test file", "expected.bin", true, false},
+		{"absolute", filepath.Join(dir, "expected.bin"), true, false},
+		{"variable", "@{fileName}", true, false},
+		{"different file", "other.bin", false, false},
+		{"missing file", "missing.bin", false, true},
+		{"missing variable", "@{missing}", false, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			field, err := validateWebsocketBinary(&ds, &WSMessage{ExpectFile: tt.file}, response, 0, 0)
+			if tt.fails {
+				if err == nil {
+					t.Errorf("expected an error but got %v", field)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if field.Status != tt.passed {
+				t.Errorf("expected the validation to pass: %v but got: %v", tt.passed, field.Error)
+			}
+		})
+	}
+}
+
+func TestExecuteRestHostHeader(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"host": r.Host})
+	}))
+	defer server.Close()
+	t.Setenv("ARP_TEST_TENANT", "acme")
+
+	result := runTestFile(t, `
+tests:
+  - name: Route host
+    route: "@{host}/users"
+    method: GET
+    response:
+      code: 200
+      payload:
+        host: "^`+strings.ReplaceAll(strings.TrimPrefix(server.URL, "http://"), ".", "\\\\.")+`$"
+  - name: Host header
+    route: "@{host}/users"
+    method: GET
+    hostHeader: tenant.example.com
+    response:
+      code: 200
+      payload:
+        host: "^tenant\\.example\\.com$"
+  - name: Host header variable
+    route: "@{host}/users"
+    method: GET
+    hostHeader: "@{ARP_TEST_TENANT}.example.com"
+    response:
+      code: 200
+      payload:
+        host: "^acme\\.example\\.com$"
+`, server.URL, SuiteOptions{EnvPrefix: "ARP_TEST_"})
+
+	if len(result.Results) != 3 {
+		t.Fatalf("expected 3 results but got %v", len(result.Results))
+	}
+	for _, r := range result.Results {
+		if !r.Passed {
+			t.Errorf("%v: unexpected Host header:\n%v", r.TestCase.Config.Name, failedFields(r))
+		}
+	}
+}
+
+func TestExecuteWebsocketExpectPosition(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// a single subscription is answered by an acknowledgement and an event
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		conn.WriteJSON(map[string]interface{}{"type": "subscribed"})
+		conn.WriteJSON(map[string]interface{}{"type": "event", "id": 2})
+		for {
+			if _, _, err := conn.ReadMessage(); err != nil {
+				return
+			}
+		}
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name   string
+		id     int
+		passed bool
+		paths  []string
+	}{
+		{"passing expectations", 2, true, []string{".responses[0].type", ".responses[1].type", ".responses[1].id"}},
+		{"failing expectation", 3, false, []string{".responses[0].type", ".responses[1].type", ".responses[1].id"}},
+	}
+
+	for _, tt := range tests {
+		result := runTestFile(t, `
+tests:
+  - name: Subscription frames
+    route: "`+strings.Replace(server.URL, "http://", "ws://", 1)+`"
+    websocket: true
+    input:
+      close: true
+      requests:
+        - payload: subscribe
+          writeOnly: true
+        - readOnly: true
+          expect:
+            type: subscribed
+        - readOnly: true
+          expect:
+            type: event
+            id: `+strconv.Itoa(tt.id)+`
+`, server.URL, SuiteOptions{})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("%v: expected 1 result but got %v", tt.name, len(result.Results))
+		}
+		r := result.Results[0]
+		if r.Passed != tt.passed {
+			t.Errorf("%v: expected the test to pass: %v but got:\n%v", tt.name, tt.passed, ToJsonStr(r.MessageFields))
+		}
+
+		var paths []string
+		for _, f := range r.MessageFields {
+			paths = append(paths, f.ObjectKeyPath)
+		}
+		sort.Strings(paths)
+		expected := append([]string{}, tt.paths...)
+		sort.Strings(expected)
+		if strings.Join(paths, ",") != strings.Join(expected, ",") {
+			t.Errorf("%v: expected results at %v but got %v", tt.name, expected, paths)
+		}
+		for _, f := range r.MessageFields {
+			if f.ObjectKeyPath == ".responses[1].id" && f.Status != tt.passed {
+				t.Errorf("%v: expected the id validation to pass: %v", tt.name, tt.passed)
+			}
+		}
+	}
+}
+
+func TestExecuteWebsocketClosedEarly(t *testing.T) {
+	upgrader := websocket.Upgrader{}
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, err := upgrader.Upgrade(w, r, nil)
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		// closes the connection instead of answering the first message
+		if _, _, err := conn.ReadMessage(); err != nil {
+			return
+		}
+		conn.WriteMessage(websocket.CloseMessage, websocket.FormatCloseMessage(4001, "unauthorized"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		requests string
+		payload  string
+		failed   []string
+	}{
+		{"unread expectations", "- payload: hello\n  expect:\n    type: welcome\n- readOnly: true\n  expect:\n    type: event", "", []string{".responses[0]", ".responses[1]"}},
+		{"unread binary expectation", "- payload: hello\n- readOnly: true\n  response: binary\n  expectSha256: abc", "", []string{".responses[1]"}},
+		{"write only messages", "- payload: hello\n  writeOnly: true\n- payload: again\n  writeOnly: true\n- readOnly: true\n  expect:\n    type: event", "", []string{".responses[0]"}},
+		{"without expectations", "- payload: hello\n- readOnly: true", "", nil},
+		{"close validated", "- payload: hello\n  expect:\n    type: welcome", "$.close.code: 4001", nil},
+	}
+
+	for _, tt := range tests {
+		payload := ""
+		if tt.payload != "" {
+			payload = "response:\n      payload:\n        " + tt.payload
+		}
+		result := runTestFile(t, `
+tests:
+  - name: Closed early
+    route: "`+strings.Replace(server.URL, "http://", "ws://", 1)+`"
+    websocket: true
+    input:
+      close: true
+      requests:
+        `+strings.ReplaceAll(tt.requests, "\n", "\n        ")+`
+    `+payload+`
+`, server.URL, SuiteOptions{})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("%v: expected 1 result but got %v", tt.name, len(result.Results))
+		}
+		r := result.Results[0]
+		if r.Passed != (len(tt.failed) == 0) {
+			t.Errorf("%v: expected the test to pass: %v but got:\n%v", tt.name, len(tt.failed) == 0, failedFields(r))
+		}
+
+		var failed []string
+		for _, f := range r.Fields {
+			if !f.Status {
+				failed = append(failed, f.ObjectKeyPath)
+			}
+		}
+		if strings.Join(failed, ",") != strings.Join(tt.failed, ",") {
+			t.Errorf("%v: expected failures at %v but got:\n%v", tt.name, tt.failed, failedFields(r))
+		}
+	}
+}
@@ -0,0 +1,113 @@
+package arp
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+const (
+	TIMING_GROUP_METHOD = "method"
+	TIMING_GROUP_ROUTE  = "route"
+)
+
+// TimingStats summarizes the request durations of a set of tests
+type TimingStats struct {
+	Count  int
+	Min    time.Duration
+	Median time.Duration
+	P90    time.Duration
+	P99    time.Duration
+	Max    time.Duration
+}
+
+// NewTimingStats computes the summary of a list of durations using the nearest-rank percentile
+func NewTimingStats(durations []time.Duration) TimingStats {
+	if len(durations) == 0 {
+		return TimingStats{}
+	}
+
+	sorted := append([]time.Duration{}, durations...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+
+	percentile := func(p int) time.Duration {
+		rank := (p*len(sorted) + 99) / 100
+		if rank < 1 {
+			rank = 1
+		}
+		return sorted[rank-1]
+	}
+
+	return TimingStats{
+		Count:  len(sorted),
+		Min:    sorted[0],
+		Median: percentile(50),
+		P90:    percentile(90),
+		P99:    percentile(99),
+		Max:    sorted[len(sorted)-1],
+	}
+}
+
+func (s TimingStats) String() string {
+	return fmt.Sprintf("count: %v, min: %v, median: %v, p90: %v, p99: %v, max: %v", s.Count,
+		s.Min.Round(time.Microsecond), s.Median.Round(time.Microsecond), s.P90.Round(time.Microsecond),
+		s.P99.Round(time.Microsecond), s.Max.Round(time.Microsecond))
+}
+
+// timingGroupKey returns the name of the group a test's duration is summarized under. Routes are grouped by their
+// definition rather than their resolved value so that requests differing only by variables share a group.
+func timingGroupKey(groupBy string, test *TestResult) string {
+	switch groupBy {
+	case TIMING_GROUP_METHOD:
+		return test.TestCase.Config.Method
+	case TIMING_GROUP_ROUTE:
+		return fmt.Sprintf("%v %v", test.TestCase.Config.Method, test.TestCase.Config.Route)
+	}
+	return ""
+}
+
+// madeRequest returns whether a test result is from a request that was actually sent. Skipped tests, assertions and
+// tests that failed before sending anything finish almost instantly and would skew the distribution.
+func madeRequest(test *TestResult) bool {
+	if test.EndTime.Sub(test.StartTime) <= 0 {
+		return false
+	}
+	if test.TestCase.Config.Websocket {
+		// websocket messages aren't counted as requests, but the route is only resolved once the client connected
+		return test.ResolvedRoute != ""
+	}
+	return test.RequestCount > 0
+}
+
+// PrintTimingSummary prints the distribution of request durations across every executed test in the run, optionally
+// grouped by method or route. Only tests that sent a request are included.
+func PrintTimingSummary(opts ReportOptions, results []MultiSuiteResult) {
+	var all []time.Duration
+	groups := make(map[string][]time.Duration)
+	for _, r := range results {
+		for _, test := range r.TestResults.Results {
+			if !madeRequest(test) {
+				continue
+			}
+			delta := test.EndTime.Sub(test.StartTime)
+			all = append(all, delta)
+			if opts.TimingGroup != "" {
+				key := timingGroupKey(opts.TimingGroup, test)
+				groups[key] = append(groups[key], delta)
+			}
+		}
+	}
+
+	PrintIndentedLn(0, "\n%v\n", opts.Colors.BrightWhite("Request Durations"))
+	PrintIndentedLn(1, "%v\n", NewTimingStats(all))
+
+	var keys []string
+	for k := range groups {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		PrintIndentedLn(1, "%v\n", opts.Colors.BrightCyan(k))
+		PrintIndentedLn(2, "%v\n", NewTimingStats(groups[k]))
+	}
+}
@@ -0,0 +1,54 @@
+package arp
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestMadeRequest(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// keeps the duration from rounding down to nothing on coarse clocks
+		time.Sleep(time.Millisecond)
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		w.Write([]byte(`{"id": 1}`))
+	}))
+	defer server.Close()
+
+	result := runTestFile(t, `
+tests:
+  - name: Request
+    route: "@{host}/users"
+    method: GET
+    response:
+      code: 200
+  - name: Skipped
+    route: "@{host}/users"
+    method: GET
+    skip: true
+  - name: Other environment
+    route: "@{host}/users"
+    method: GET
+    environments: [production]
+  - name: Assertion
+    assertEquals:
+      left: 1
+      right: 1
+`, server.URL, SuiteOptions{})
+
+	expected := map[string]bool{"Request": true, "Skipped": false, "Other environment": false, "Assertion": false}
+	if len(result.Results) != len(expected) {
+		t.Fatalf("expected %v results but got %v", len(expected), len(result.Results))
+	}
+	for _, r := range result.Results {
+		if made := madeRequest(r); made != expected[r.TestCase.Config.Name] {
+			t.Errorf("%v: expected a request to be made: %v but got %v", r.TestCase.Config.Name, expected[r.TestCase.Config.Name], made)
+		}
+	}
+
+	stubbed := (&TestCase{}).GetStubbedFailResult("previous test failed")
+	if madeRequest(stubbed) {
+		t.Errorf("expected a stubbed result not to have made a request")
+	}
+}
@@ -0,0 +1,202 @@
+package arp
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptrace"
+	"net/http/httputil"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	TraceRequestFmt      = ">>> [trace] %v - request %v"
+	TraceResponseFmt     = "<<< [trace] %v - response %v (%v)"
+	TraceResponseBodyFmt = "<<< [trace] %v - response %v body"
+	TraceConnFmt         = "* connected to %v"
+	TraceBinaryFmt       = "[%v bytes of binary data]"
+)
+
+var (
+	// traces of concurrently executed tests are written one exchange at a time
+	traceLock sync.Mutex
+
+	// headers that are always redacted in traces since they carry session credentials
+	TraceRedactPatterns = []string{"cookie", "set-cookie"}
+)
+
+// NewTraceRedactor creates the redactor of traced headers from the redaction patterns of test reports, which also
+// masks cookies. Nothing is redacted when secrets should be shown.
+func NewTraceRedactor(patternList string, showSecrets bool) Redactor {
+	if showSecrets {
+		return Redactor{}
+	}
+	return NewRedactor(strings.Join(append([]string{patternList}, TraceRedactPatterns...), ","))
+}
+
+// requestTracer prints the raw requests sent through a transport and the responses received for them, including
+// those following redirects. The values of sensitive headers are masked by its redactor.
+type requestTracer struct {
+	http.RoundTripper
+	Name     string
+	Redactor Redactor
+	Out      io.Writer
+	count    int
+}
+
+func (t *requestTracer) RoundTrip(request *http.Request) (*http.Response, error) {
+	t.count++
+	var out bytes.Buffer
+	fmt.Fprintf(&out, TraceRequestFmt+"\n", t.Name, t.count)
+
+	// the body is buffered so that it can be printed as well as sent
+	var body []byte
+	if request.Body != nil {
+		var err error
+		if body, err = ioutil.ReadAll(request.Body); err != nil {
+			return nil, err
+		}
+		request.Body.Close()
+		request.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+
+	dumped := request.Clone(request.Context())
+	dumped.Header = t.redactHeaders(request.Header)
+	if body != nil {
+		dumped.Body = ioutil.NopCloser(bytes.NewReader(body))
+	}
+	if head, err := httputil.DumpRequestOut(dumped, false); err == nil {
+		out.Write(head)
+	}
+	writeTraceBody(&out, body)
+
+	trace := &httptrace.ClientTrace{
+		GotConn: func(info httptrace.GotConnInfo) {
+			conn := fmt.Sprintf(TraceConnFmt, info.Conn.RemoteAddr())
+			if info.Reused {
+				conn += " (reused connection)"
+			}
+			fmt.Fprintln(&out, conn)
+		},
+	}
+	request = request.WithContext(httptrace.WithClientTrace(request.Context(), trace))
+
+	start := time.Now()
+	response, err := t.RoundTripper.RoundTrip(request)
+	if err != nil {
+		fmt.Fprintf(&out, "!!! [trace] %v - %v\n\n", t.Name, err)
+		t.write(out.Bytes())
+		return response, err
+	}
+	fmt.Fprintf(&out, TraceResponseFmt+"\n", t.Name, t.count, time.Since(start))
+
+	dumpedResponse := *response
+	dumpedResponse.Header = t.redactHeaders(response.Header)
+	if head, err := httputil.DumpResponse(&dumpedResponse, false); err == nil {
+		out.Write(head)
+	}
+	t.write(out.Bytes())
+
+	// the body is printed once the response parsers have read or closed it, so streams that are left open aren't waited on
+	response.Body = &tracedBody{ReadCloser: response.Body, tracer: t, exchange: t.count}
+	return response, nil
+}
+
+// CloseIdleConnections closes the idle connections of the wrapped transport so that clients using the tracer can
+// still release them
+func (t *requestTracer) CloseIdleConnections() {
+	if closer, ok := t.RoundTripper.(interface{ CloseIdleConnections() }); ok {
+		closer.CloseIdleConnections()
+	}
+}
+
+func (t *requestTracer) write(b []byte) {
+	traceLock.Lock()
+	defer traceLock.Unlock()
+	out := t.Out
+	if out == nil {
+		out = os.Stderr
+	}
+	out.Write(b)
+}
+
+// redactHeaders returns a copy of the headers where the values of sensitive headers are masked
+func (t *requestTracer) redactHeaders(headers http.Header) http.Header {
+	redacted := headers.Clone()
+	for k, values := range redacted {
+		if !t.Redactor.IsSensitive(k) {
+			continue
+		}
+		for i := range values {
+			values[i] = REDACTED_VALUE
+		}
+	}
+	return redacted
+}
+
+// writeTraceBody writes a body as is unless it is binary, in which case only its size is written
+func writeTraceBody(out *bytes.Buffer, body []byte) {
+	if len(body) == 0 {
+		return
+	}
+	if !utf8.Valid(body) {
+		fmt.Fprintf(out, TraceBinaryFmt+"\n", len(body))
+		return
+	}
+	out.Write(body)
+	if !strings.HasSuffix(string(body), "\n") {
+		out.WriteString("\n")
+	}
+}
+
+// tracedBody keeps a copy of a response body as it is read and prints it once it has been read to the end or closed,
+// whichever happens first
+type tracedBody struct {
+	io.ReadCloser
+	tracer   *requestTracer
+	exchange int
+	lock     sync.Mutex
+	body     bytes.Buffer
+	done     bool
+}
+
+func (b *tracedBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.lock.Lock()
+	b.body.Write(p[:n])
+	b.lock.Unlock()
+	if err != nil {
+		b.finish(err)
+	}
+	return n, err
+}
+
+func (b *tracedBody) Close() error {
+	err := b.ReadCloser.Close()
+	b.finish(nil)
+	return err
+}
+
+func (b *tracedBody) finish(readErr error) {
+	b.lock.Lock()
+	defer b.lock.Unlock()
+	if b.done {
+		return
+	}
+	b.done = true
+
+	var out bytes.Buffer
+	fmt.Fprintf(&out, TraceResponseBodyFmt+"\n", b.tracer.Name, b.exchange)
+	writeTraceBody(&out, b.body.Bytes())
+	if readErr != nil && readErr != io.EOF {
+		fmt.Fprintf(&out, "!!! [trace] %v - failed to read the response body: %v\n", b.tracer.Name, readErr)
+	}
+	out.WriteString("\n")
+	b.tracer.write(out.Bytes())
+}
@@ -0,0 +1,104 @@
+package arp
+
+import (
+	"bufio"
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestTraceStreamedResponse(t *testing.T) {
+	release := make(chan struct{})
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte("{\"line\": 1}\n"))
+		w.(http.Flusher).Flush()
+		// the stream stays open until the test is done with it
+		select {
+		case <-release:
+		case <-r.Context().Done():
+		}
+	}))
+	defer server.Close()
+	defer close(release)
+
+	var out bytes.Buffer
+	tracer := &requestTracer{RoundTripper: http.DefaultTransport, Name: "stream", Out: &out}
+	client := &http.Client{Transport: tracer}
+
+	done := make(chan string)
+	go func() {
+		response, err := client.Get(server.URL)
+		if err != nil {
+			done <- err.Error()
+			return
+		}
+		line, _ := bufio.NewReader(response.Body).ReadString('\n')
+		response.Body.Close()
+		done <- line
+	}()
+
+	select {
+	case line := <-done:
+		if line != "{\"line\": 1}\n" {
+			t.Fatalf("unexpected line '%v'", line)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("tracing waited for the stream to end")
+	}
+
+	trace := out.String()
+	if !strings.Contains(trace, "<<< [trace] stream - response 1 body\n{\"line\": 1}\n") {
+		t.Errorf("expected the streamed body in the trace:\n%v", trace)
+	}
+}
+
+func TestTraceRedaction(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		http.SetCookie(w, &http.Cookie{Name: "session", Value: "server-secret"})
+		w.Write([]byte("ok"))
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name        string
+		showSecrets bool
+		redacted    bool
+	}{
+		{"redacted", false, true},
+		{"shown", true, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var out bytes.Buffer
+			tracer := &requestTracer{
+				RoundTripper: http.DefaultTransport,
+				Name:         tt.name,
+				Redactor:     NewTraceRedactor(strings.Join(DefaultRedactPatterns, ","), tt.showSecrets),
+				Out:          &out,
+			}
+			request, _ := http.NewRequest(http.MethodGet, server.URL, nil)
+			request.Header.Set("Authorization", "Bearer client-secret")
+			request.Header.Set("Cookie", "session=client-cookie")
+			response, err := (&http.Client{Transport: tracer}).Do(request)
+			if err != nil {
+				t.Fatal(err)
+			}
+			response.Body.Close()
+
+			trace := out.String()
+			for _, secret := range []string{"client-secret", "client-cookie", "server-secret"} {
+				if strings.Contains(trace, secret) == tt.redacted {
+					t.Errorf("expected '%v' to be redacted: %v\n%v", secret, tt.redacted, trace)
+				}
+			}
+			// redaction only applies to the trace, not the request sent
+			if request.Header.Get("Cookie") != "session=client-cookie" {
+				t.Error("the request headers were modified")
+			}
+		})
+	}
+}
@@ -0,0 +1,195 @@
+package arp
+
+import (
+	"fmt"
+	"strings"
+)
+
+const (
+	CFG_RESPONSE_VALIDATION_ERROR = "expectValidationError"
+
+	// keys of each expected validation error
+	VALIDATION_ERROR_KEY_FIELD       = "field"
+	VALIDATION_ERROR_KEY_MESSAGE     = "messageMatches"
+	VALIDATION_ERROR_KEY_PATH        = "path"
+	VALIDATION_ERROR_KEY_FIELD_KEY   = "fieldKey"
+	VALIDATION_ERROR_KEY_MESSAGE_KEY = "messageKey"
+
+	// the common '{errors: [{field, message}]}' shape of validation errors
+	DEFAULT_VALIDATION_ERROR_PATH        = "errors"
+	DEFAULT_VALIDATION_ERROR_FIELD_KEY   = "field"
+	DEFAULT_VALIDATION_ERROR_MESSAGE_KEY = "message"
+
+	ValidationErrorMarker = "[validation] "
+
+	ValidationErrorMissingFmt = "Expected a validation error for the field '%v' in '%v' but found errors for: %v"
+	ValidationErrorNoneFmt    = "Expected a validation error for the field '%v' but '%v' contains no errors"
+)
+
+// ValidationErrorExpectation is a field level error the response must contain, e.g. for negative tests of input
+// validation. It is validated by an array matcher finding the error of the field and matching its message.
+type ValidationErrorExpectation struct {
+	// JSON path of the array of errors within the response
+	Path string
+	// keys of the field and message within each error
+	FieldKey   string
+	MessageKey string
+	Field      string
+	Matcher    *ResponseMatcher
+}
+
+// parseValidationErrors parses the 'expectValidationError' option, which is either a single expected error or a list
+// of them
+func parseValidationErrors(cfg interface{}) ([]map[interface{}]interface{}, error) {
+	switch v := cfg.(type) {
+	case nil:
+		return nil, nil
+	case map[interface{}]interface{}:
+		return []map[interface{}]interface{}{v}, nil
+	case []interface{}:
+		var defs []map[interface{}]interface{}
+		for _, d := range v {
+			def, ok := d.(map[interface{}]interface{})
+			if !ok {
+				return nil, fmt.Errorf("'%v' must be an object or a list of objects", CFG_RESPONSE_VALIDATION_ERROR)
+			}
+			defs = append(defs, def)
+		}
+		return defs, nil
+	}
+	return nil, fmt.Errorf("'%v' must be an object or a list of objects", CFG_RESPONSE_VALIDATION_ERROR)
+}
+
+// validationErrorDefinition builds the payload definition of an array matcher finding the error of the expected
+// field at the error path and matching its message, e.g. '$.errors: {type: array, find: {...}}'
+func (e *ValidationErrorExpectation) validationErrorDefinition(message interface{}) map[interface{}]interface{} {
+	find := map[interface{}]interface{}{
+		TEST_KEY_FIND_FIELD:  e.FieldKey,
+		TEST_KEY_FIND_EQUALS: e.Field,
+	}
+	if message != nil {
+		find[TEST_KEY_PROPERTIES] = map[interface{}]interface{}{
+			e.MessageKey: map[interface{}]interface{}{
+				TEST_KEY_TYPE:    TYPE_STR,
+				TEST_KEY_MATCHES: fmt.Sprintf("%v", message),
+			},
+		}
+	}
+
+	// the JSON path notation creates the validations of any objects leading to the errors
+	return map[interface{}]interface{}{
+		FIELD_KEY_PREFIX + e.Path: map[interface{}]interface{}{
+			TEST_KEY_TYPE: TYPE_ARRAY,
+			TEST_KEY_FIND: find,
+		},
+	}
+}
+
+// loadValidationErrors loads a matcher for each validation error the test's response must contain
+func (t *TestCase) loadValidationErrors() error {
+	defs, err := parseValidationErrors(t.Config.Response.ValidationError)
+	if err != nil {
+		return fmt.Errorf("%v for %v", err, t.Config.Name)
+	}
+
+	t.ValidationErrors = nil
+	for _, d := range defs {
+		field, ok := d[VALIDATION_ERROR_KEY_FIELD]
+		if !ok {
+			return fmt.Errorf("'%v' requires a '%v' for %v", CFG_RESPONSE_VALIDATION_ERROR, VALIDATION_ERROR_KEY_FIELD,
+				t.Config.Name)
+		}
+
+		expectation := &ValidationErrorExpectation{
+			Path:       DEFAULT_VALIDATION_ERROR_PATH,
+			FieldKey:   DEFAULT_VALIDATION_ERROR_FIELD_KEY,
+			MessageKey: DEFAULT_VALIDATION_ERROR_MESSAGE_KEY,
+			Field:      fmt.Sprintf("%v", field),
+		}
+		if v, ok := d[VALIDATION_ERROR_KEY_PATH]; ok {
+			expectation.Path = strings.TrimPrefix(fmt.Sprintf("%v", v), FIELD_KEY_PREFIX)
+		}
+		if v, ok := d[VALIDATION_ERROR_KEY_FIELD_KEY]; ok {
+			expectation.FieldKey = fmt.Sprintf("%v", v)
+		}
+		if v, ok := d[VALIDATION_ERROR_KEY_MESSAGE_KEY]; ok {
+			expectation.MessageKey = fmt.Sprintf("%v", v)
+		}
+
+		def := expectation.validationErrorDefinition(d[VALIDATION_ERROR_KEY_MESSAGE])
+		matcher := NewResponseMatcher(t.GlobalDataStore)
+		if err := matcher.loadObjectFields(def, def, FieldMatcherPath{}); err != nil {
+			return fmt.Errorf("invalid '%v' for %v: %v", CFG_RESPONSE_VALIDATION_ERROR, t.Config.Name, err)
+		}
+		for _, c := range matcher.Config {
+			c.Marker = ValidationErrorMarker
+		}
+		expectation.Matcher = &matcher
+		t.ValidationErrors = append(t.ValidationErrors, expectation)
+	}
+	return nil
+}
+
+// missingResult reports the expected error along with the fields that do have errors when the response doesn't
+// contain an error for the expected field. Nil is returned if it does.
+func (e *ValidationErrorExpectation) missingResult(response map[string]interface{}, datastore *DataStore) *FieldMatcherResult {
+	resolved, err := datastore.ExpandVariable(e.Field)
+	if err != nil {
+		return validationError("."+e.Path, fmt.Errorf(BadVarMatcherFmt, e.Field))
+	}
+	field := varToString(resolved, e.Field)
+
+	errs, _ := GetJsonValue(response, e.Path)
+	elements, _ := errs.([]interface{})
+
+	var fields []string
+	for _, el := range elements {
+		obj, ok := el.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		value, err := GetJsonValue(obj, e.FieldKey)
+		if err != nil || value == nil {
+			continue
+		}
+		if varToString(value) == field {
+			return nil
+		}
+		fields = append(fields, varToString(value))
+	}
+
+	msg := fmt.Sprintf(ValidationErrorNoneFmt, field, e.Path)
+	if len(fields) > 0 {
+		msg = fmt.Sprintf(ValidationErrorMissingFmt, field, e.Path, strings.Join(fields, ", "))
+	}
+	return &FieldMatcherResult{
+		ObjectKeyPath: "." + e.Path,
+		Error:         ValidationErrorMarker + msg,
+		Status:        false,
+	}
+}
+
+// validateValidationErrors validates that the response contains each expected validation error and that their
+// messages match
+func (t *TestCase) validateValidationErrors(response map[string]interface{}) (bool, []*FieldMatcherResult) {
+	status := true
+	var results []*FieldMatcherResult
+	for _, e := range t.ValidationErrors {
+		if r := e.missingResult(response, t.GlobalDataStore); r != nil {
+			results = append(results, r)
+			status = false
+			continue
+		}
+
+		// the matcher is reused by every attempt of the test, so nodes cached from a previous response are dropped
+		e.Matcher.NodeCache = NodeCache{Cache: make(map[string]NodeCacheObj)}
+		passed, matchResults, err := e.Matcher.Match(response)
+		results = append(results, matchResults...)
+		if err != nil {
+			passed = false
+			results = append(results, validationError("."+e.Path, err))
+		}
+		status = status && passed
+	}
+	return status, results
+}
@@ -0,0 +1,18 @@
+package arp
+
+import "net/http"
+
+const (
+	VERSION = "0.1.0"
+
+	HEADER_USER_AGENT = "User-Agent"
+	// sent with requests that don't set their own User-Agent so that arp's traffic can be identified in server logs
+	DEFAULT_USER_AGENT = "arp/" + VERSION
+)
+
+// setDefaultUserAgent identifies the request as sent by arp unless the test set its own User-Agent header
+func setDefaultUserAgent(headers http.Header) {
+	if headers.Get(HEADER_USER_AGENT) == "" {
+		headers.Set(HEADER_USER_AGENT, DEFAULT_USER_AGENT)
+	}
+}
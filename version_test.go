@@ -0,0 +1,66 @@
+package arp
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestSetDefaultUserAgent(t *testing.T) {
+	tests := []struct {
+		name     string
+		headers  http.Header
+		expected string
+	}{
+		{"default", http.Header{}, DEFAULT_USER_AGENT},
+		{"overridden", http.Header{HEADER_USER_AGENT: {"curl/8.0"}}, "curl/8.0"},
+		{"other headers", http.Header{"Accept": {"*/*"}}, DEFAULT_USER_AGENT},
+	}
+
+	for _, tt := range tests {
+		setDefaultUserAgent(tt.headers)
+		if values := tt.headers.Values(HEADER_USER_AGENT); len(values) != 1 || values[0] != tt.expected {
+			t.Errorf("%v: expected %v but got %v", tt.name, tt.expected, values)
+		}
+	}
+}
+
+func TestExecuteRestUserAgent(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set(HEADER_CONTENT_TYPE, "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{"agent": r.UserAgent()})
+	}))
+	defer server.Close()
+
+	tests := []struct {
+		name     string
+		headers  string
+		expected string
+	}{
+		{"default", "", "^arp/"},
+		{"overridden", "headers:\n      User-Agent: curl/8.0", "^curl/8\\\\.0$"},
+		{"overridden in lower case", "headers:\n      user-agent: curl/8.0", "^curl/8\\\\.0$"},
+	}
+
+	for _, tt := range tests {
+		result := runTestFile(t, `
+tests:
+  - name: User agent
+    route: "@{host}/users"
+    method: GET
+    `+tt.headers+`
+    response:
+      code: 200
+      payload:
+        agent: "`+tt.expected+`"
+`, server.URL, SuiteOptions{})
+
+		if len(result.Results) != 1 {
+			t.Fatalf("%v: expected 1 result but got %v", tt.name, len(result.Results))
+		}
+		if r := result.Results[0]; !r.Passed {
+			t.Errorf("%v: unexpected User-Agent:\n%v", tt.name, failedFields(r))
+		}
+	}
+}
@@ -0,0 +1,116 @@
+package arp
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+const (
+	CFG_WAIT_FOR = "waitFor"
+
+	DEFAULT_WAIT_INTERVAL = time.Second
+	DEFAULT_WAIT_TIMEOUT  = time.Minute
+
+	WaitReadyFmt  = "%v was ready after %v (%v attempt(s))"
+	WaitFailedFmt = "%v was not ready after %v (%v attempt(s)): %v"
+)
+
+// WaitForCfg defines an endpoint that is polled until it responds with a success status before any test of a file
+// is executed, such as the health check of the service under test.
+type WaitForCfg struct {
+	Url      string `yaml:"url"`
+	Interval string `yaml:"interval"`
+	Timeout  string `yaml:"timeout"`
+}
+
+// GetDurations parses the interval and timeout of the wait, falling back to defaults for any that are missing
+func (w *WaitForCfg) GetDurations() (time.Duration, time.Duration, error) {
+	interval := DEFAULT_WAIT_INTERVAL
+	timeout := DEFAULT_WAIT_TIMEOUT
+	var err error
+	if w.Interval != "" {
+		if interval, err = time.ParseDuration(w.Interval); err != nil {
+			return 0, 0, fmt.Errorf("invalid '%v' interval: %v", CFG_WAIT_FOR, err)
+		}
+	}
+	if w.Timeout != "" {
+		if timeout, err = time.ParseDuration(w.Timeout); err != nil {
+			return 0, 0, fmt.Errorf("invalid '%v' timeout: %v", CFG_WAIT_FOR, err)
+		}
+	}
+	return interval, timeout, nil
+}
+
+// WaitFor requests a URL every interval until it responds with a 2xx status. An error describing the last failed
+// attempt is returned once the timeout expires or the context is cancelled. The time spent waiting and the number of
+// attempts are returned either way.
+func WaitFor(ctx context.Context, url string, interval time.Duration, timeout time.Duration) (time.Duration, int, error) {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+
+	start := time.Now()
+	client := http.Client{}
+	attempts := 0
+	for {
+		attempts++
+		lastErr := checkReady(ctx, &client, url)
+		if lastErr == nil {
+			return time.Since(start).Round(time.Millisecond), attempts, nil
+		}
+
+		select {
+		case <-time.After(interval):
+		case <-ctx.Done():
+			elapsed := time.Since(start).Round(time.Millisecond)
+			return elapsed, attempts, fmt.Errorf(WaitFailedFmt, url, elapsed, attempts, lastErr)
+		}
+	}
+}
+
+// checkReady requests a URL once, returning an error if it fails or doesn't respond with a 2xx status
+func checkReady(ctx context.Context, client *http.Client, url string) error {
+	request, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	response, err := client.Do(request)
+	if err != nil {
+		return err
+	}
+	response.Body.Close()
+	if response.StatusCode < 200 || response.StatusCode > 299 {
+		return fmt.Errorf("status %v", response.StatusCode)
+	}
+	return nil
+}
+
+// waitForService polls the 'waitFor' endpoint of the test file, if any, before its tests are executed
+func (t *TestSuite) waitForService() error {
+	if t.WaitFor == nil {
+		return nil
+	}
+
+	interval, timeout, err := t.WaitFor.GetDurations()
+	if err != nil {
+		return err
+	}
+	resolved, err := t.GlobalDataStore.ExpandVariable(t.WaitFor.Url)
+	if err != nil {
+		return fmt.Errorf("failed to resolve '%v' url: %v", CFG_WAIT_FOR, err)
+	}
+	url := varToString(resolved, t.WaitFor.Url)
+
+	elapsed, attempts, err := WaitFor(t.Context, url, interval, timeout)
+	if err != nil {
+		return err
+	}
+	if t.Verbose {
+		fmt.Printf(">> "+WaitReadyFmt+"\n", url, elapsed, attempts)
+	}
+	return nil
+}